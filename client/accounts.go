@@ -2,9 +2,8 @@ package client
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 )
@@ -52,7 +51,7 @@ func (c *CoinbaseClient) GetAccountsWithLogging(enableLogging bool) ([]Account,
 	quoteCurrency := parts[1]
 
 	// Only log in debug mode for performance (and if logging is enabled)
-	if os.Getenv("LOG_LEVEL") == "DEBUG" && enableLogging {
+	if c.debug && enableLogging {
 		c.logger.Printf("Fetching accounts for %s and %s...", baseCurrency, quoteCurrency)
 	}
 
@@ -61,12 +60,15 @@ func (c *CoinbaseClient) GetAccountsWithLogging(enableLogging bool) ([]Account,
 		if enableLogging {
 			c.logger.Printf("Error fetching accounts: %v", err)
 		}
+		if errors.Is(err, ErrUpstreamNonJSON) {
+			return nil, fmt.Errorf("coinbase appears to be unavailable (maintenance or incident): %w", err)
+		}
 		return nil, fmt.Errorf("failed to fetch accounts: %w", err)
 	}
 
 	var resp AccountsResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal accounts response: %w", err)
+	if err := decodeJSON(respBody, &resp, "accounts"); err != nil {
+		return nil, err
 	}
 
 	// Filter and convert only accounts for the configured trading pair
@@ -85,7 +87,7 @@ func (c *CoinbaseClient) GetAccountsWithLogging(enableLogging bool) ([]Account,
 	}
 
 	// Only log in debug mode for performance (and if logging is enabled)
-	if os.Getenv("LOG_LEVEL") == "DEBUG" && enableLogging {
+	if c.debug && enableLogging {
 		c.logger.Printf("Successfully fetched %d trading accounts (%s/%s)", len(accounts), baseCurrency, quoteCurrency)
 	}
 	return accounts, nil
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// cachedSignalCandles returns the latest count candles of granularity, reusing the previously fetched set and
+// only requesting candles from the last cached one onwards. A full fetch is made when the cache is empty, holds
+// another granularity/count, or is too stale to top up with fewer candles than a full fetch. The fetch runs
+// outside the cache lock so one slow candle request doesn't hold up other callers.
+func (c *CoinbaseClient) cachedSignalCandles(count int, granularity string) ([]Candle, error) {
+	key := fmt.Sprintf("%s/%d", granularity, count)
+	c.candleCacheMutex.Lock()
+	var cached []Candle
+	if c.candleCacheKey == key {
+		cached = c.candleCache
+	}
+	c.candleCacheMutex.Unlock()
+
+	if len(cached) > 0 {
+		candles, err := c.topUpCandles(cached, count, granularity)
+		if err == nil {
+			c.storeSignalCandles(key, candles)
+			return append([]Candle(nil), candles...), nil
+		}
+		if c.debug {
+			c.logger.Printf("Candle cache refresh failed, fetching all %d candles: %v", count, err)
+		}
+	}
+
+	candles, err := c.GetCandles("", "", granularity, count)
+	if err != nil {
+		return nil, err
+	}
+	c.storeSignalCandles(key, candles)
+	return append([]Candle(nil), candles...), nil
+}
+
+// storeSignalCandles caches candles fetched for key, unless a concurrent fetch for the same key already cached
+// candles reaching further
+func (c *CoinbaseClient) storeSignalCandles(key string, candles []Candle) {
+	c.candleCacheMutex.Lock()
+	defer c.candleCacheMutex.Unlock()
+
+	if c.candleCacheKey == key && len(c.candleCache) > 0 && len(candles) > 0 {
+		current, currentErr := parseCandleTime(c.candleCache[len(c.candleCache)-1].Start)
+		latest, latestErr := parseCandleTime(candles[len(candles)-1].Start)
+		if currentErr == nil && latestErr == nil && current.After(latest) {
+			return
+		}
+	}
+	c.candleCache = candles
+	c.candleCacheKey = key
+}
+
+// topUpCandles fetches candles from the last cached start until now, replaces the overlapping (still forming)
+// cached candles with them and keeps the newest count
+func (c *CoinbaseClient) topUpCandles(cached []Candle, count int, granularity string) ([]Candle, error) {
+	duration, err := granularityDuration(granularity)
+	if err != nil {
+		return nil, err
+	}
+	lastStart, err := parseCandleTime(cached[len(cached)-1].Start)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if missing := int(now.Sub(lastStart)/duration) + 1; missing >= count {
+		return nil, fmt.Errorf("cache is %d candles behind", missing)
+	}
+
+	fresh, err := c.GetCandles(strconv.FormatInt(lastStart.Unix(), 10), strconv.FormatInt(now.Unix(), 10), granularity, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(fresh) == 0 {
+		return nil, fmt.Errorf("no candles returned since %s", lastStart.Format(time.RFC3339))
+	}
+
+	firstFresh, err := parseCandleTime(fresh[0].Start)
+	if err != nil {
+		return nil, err
+	}
+	merged := make([]Candle, 0, len(cached)+len(fresh))
+	for _, candle := range cached {
+		if start, err := parseCandleTime(candle.Start); err == nil && start.Before(firstFresh) {
+			merged = append(merged, candle)
+		}
+	}
+	merged = append(merged, fresh...)
+
+	if len(merged) > count {
+		merged = merged[len(merged)-count:]
+	}
+	return merged, nil
+}
+
+// PrefetchSignalCandles fills the signal candle cache so the first signal after startup only tops it up,
+// returning the number of candles fetched
+func (c *CoinbaseClient) PrefetchSignalCandles() (int, error) {
+	candles, err := c.cachedSignalCandles(c.signalCandles, c.signalGranularity)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prefetch %s candles: %w", c.tradingPair, err)
+	}
+	return len(candles), nil
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSlowCandleFetchDoesNotBlockTheCache(t *testing.T) {
+	arrived, release := make(chan struct{}), make(chan struct{})
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The 60-candle fetch hangs at Coinbase until the test lets it through
+		if r.URL.Query().Get("limit") == "60" {
+			close(arrived)
+			<-release
+		}
+		windowCandles(w, r)
+	}))
+	// Let the hanging request through even if the test fails, so the server can shut down
+	releaseOnce := sync.OnceFunc(func() { close(release) })
+	t.Cleanup(releaseOnce)
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := c.cachedSignalCandles(60, "FIVE_MINUTE")
+		slow <- err
+	}()
+	<-arrived
+
+	// Another caller is served while the slow fetch is in flight
+	fast := make(chan error, 1)
+	go func() {
+		_, err := c.cachedSignalCandles(50, "FIVE_MINUTE")
+		fast <- err
+	}()
+	select {
+	case err := <-fast:
+		if err != nil {
+			t.Errorf("fetch during the slow one: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("fetch waited for the slow one to finish")
+	}
+	releaseOnce()
+	if err := <-slow; err != nil {
+		t.Errorf("slow fetch: %v", err)
+	}
+}
+
+func TestStoreSignalCandlesKeepsTheNewest(t *testing.T) {
+	c := &CoinbaseClient{}
+	newer := candlesFromCloses([]float64{100, 101, 102})
+	older := newer[:2]
+
+	// A fetch that finishes after a concurrent, more recent one doesn't roll the cache back
+	c.storeSignalCandles("FIVE_MINUTE/3", newer)
+	c.storeSignalCandles("FIVE_MINUTE/3", older)
+	if len(c.candleCache) != 3 {
+		t.Errorf("cache holds %d candles, want the 3 newest", len(c.candleCache))
+	}
+
+	// Another granularity or count replaces it
+	c.storeSignalCandles("FIVE_MINUTE/2", older)
+	if c.candleCacheKey != "FIVE_MINUTE/2" || len(c.candleCache) != 2 {
+		t.Errorf("cache = %s with %d candles, want FIVE_MINUTE/2 with 2", c.candleCacheKey, len(c.candleCache))
+	}
+}
@@ -0,0 +1,394 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// granularityDuration converts a Coinbase candle granularity to its interval length
+func granularityDuration(granularity string) (time.Duration, error) {
+	switch granularity {
+	case "ONE_MINUTE":
+		return time.Minute, nil
+	case "FIVE_MINUTE":
+		return 5 * time.Minute, nil
+	case "FIFTEEN_MINUTE":
+		return 15 * time.Minute, nil
+	case "THIRTY_MINUTE":
+		return 30 * time.Minute, nil
+	case "ONE_HOUR":
+		return time.Hour, nil
+	case "TWO_HOUR":
+		return 2 * time.Hour, nil
+	case "SIX_HOUR":
+		return 6 * time.Hour, nil
+	case "ONE_DAY":
+		return 24 * time.Hour, nil
+	default:
+		return 0, fmt.Errorf("unsupported granularity: %s", granularity)
+	}
+}
+
+// candlesForSpan returns how many candles of the given granularity cover a time span (0 if unknown)
+func candlesForSpan(span time.Duration, granularity string) int {
+	interval, err := granularityDuration(granularity)
+	if err != nil || interval <= 0 {
+		return 0
+	}
+	return int(span / interval)
+}
+
+// maxCandlesPerRequest is the Coinbase limit on candles returned by one request
+const maxCandlesPerRequest = 350
+
+// candleWindow fills in an empty end (now) and start (count candles before end, or maxCandlesPerRequest when
+// count is not set) of a candle request, so Coinbase is never left to pick the window. Both are Unix seconds.
+func candleWindow(start, end, granularity string, count int, now time.Time) (string, string, error) {
+	if end == "" {
+		end = strconv.FormatInt(now.Unix(), 10)
+	}
+	if start != "" {
+		return start, end, nil
+	}
+
+	interval, err := granularityDuration(granularity)
+	if err != nil {
+		return "", "", err
+	}
+	endUnix, err := strconv.ParseInt(end, 10, 64)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid candle end %q: must be Unix seconds", end)
+	}
+	if count <= 0 {
+		count = maxCandlesPerRequest
+	}
+	startTime := time.Unix(endUnix, 0).Add(-time.Duration(count) * interval)
+	return strconv.FormatInt(startTime.Unix(), 10), end, nil
+}
+
+// granularitiesFineToCoarse lists the supported candle granularities from shortest to longest interval
+var granularitiesFineToCoarse = []string{
+	"ONE_MINUTE", "FIVE_MINUTE", "FIFTEEN_MINUTE", "THIRTY_MINUTE", "ONE_HOUR", "TWO_HOUR", "SIX_HOUR", "ONE_DAY",
+}
+
+// FitGranularity returns the finest granularity, no finer than requested, that covers span in at most
+// maxCandlesPerRequest candles. ONE_DAY is returned if nothing fits; unknown granularities are returned as is.
+func FitGranularity(span time.Duration, requested string) string {
+	found := false
+	for _, granularity := range granularitiesFineToCoarse {
+		if granularity == requested {
+			found = true
+		}
+		if found && candlesForSpan(span, granularity) <= maxCandlesPerRequest {
+			return granularity
+		}
+	}
+	if !found {
+		return requested
+	}
+	return "ONE_DAY"
+}
+
+// validateSignalCandles checks a signal granularity and candle count can feed every indicator,
+// including the trend EMA (EMA200 by default)
+func validateSignalCandles(granularity string, candleCount int, trendPeriod int) error {
+	if _, err := granularityDuration(granularity); err != nil {
+		return err
+	}
+	if candleCount > maxCandlesPerRequest {
+		return fmt.Errorf("candle count %d exceeds the Coinbase limit of %d", candleCount, maxCandlesPerRequest)
+	}
+	if candleCount < trendPeriod {
+		return fmt.Errorf("candle count %d is too small for EMA%d rules (need at least %d)", candleCount, trendPeriod, trendPeriod)
+	}
+	return nil
+}
+
+// maxRangeCandles caps how many candles a single chunked range fetch may return
+const maxRangeCandles = 3000
+
+// ErrCandleBudgetExceeded is returned when a range would need more candles than maxRangeCandles
+var ErrCandleBudgetExceeded = errors.New("candle budget exceeded")
+
+// GetCandlesRange retrieves candles for an arbitrary time range, splitting the request into
+// chunks of at most maxCandlesPerRequest candles. Results are oldest-first and de-duplicated.
+func (c *CoinbaseClient) GetCandlesRange(startTime, endTime time.Time, granularity string) ([]Candle, error) {
+	interval, err := granularityDuration(granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	totalCandles := candlesForSpan(endTime.Sub(startTime), granularity)
+	if totalCandles > maxRangeCandles {
+		return nil, fmt.Errorf("%w: %d %s candles requested (max %d)", ErrCandleBudgetExceeded, totalCandles, granularity, maxRangeCandles)
+	}
+
+	// Single request when the range fits
+	if totalCandles <= maxCandlesPerRequest {
+		return c.GetCandles(
+			fmt.Sprintf("%d", startTime.Unix()),
+			fmt.Sprintf("%d", endTime.Unix()),
+			granularity,
+			totalCandles,
+		)
+	}
+
+	// Log chunked fetching in debug mode
+	if c.debug {
+		c.logger.Printf("Fetching %d %s candles in chunks of %d...", totalCandles, granularity, maxCandlesPerRequest)
+	}
+
+	// Split the range into chunk windows up front so results can be stitched back in order
+	chunkSpan := time.Duration(maxCandlesPerRequest) * interval
+	type chunkWindow struct{ start, end time.Time }
+	var windows []chunkWindow
+	for chunkStart := startTime; chunkStart.Before(endTime); chunkStart = chunkStart.Add(chunkSpan) {
+		chunkEnd := chunkStart.Add(chunkSpan)
+		if chunkEnd.After(endTime) {
+			chunkEnd = endTime
+		}
+		windows = append(windows, chunkWindow{chunkStart, chunkEnd})
+	}
+
+	// Fetch chunks with a bounded worker pool; requests still share the client rate limiter.
+	// The first failure cancels the context so in-flight and queued chunks stop early.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chunks := make([][]Candle, len(windows))
+	chunkErrs := make([]error, len(windows))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(c.candleFetchConcurrency, len(windows)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				window := windows[i]
+				chunk, err := c.getCandlesContext(ctx,
+					fmt.Sprintf("%d", window.start.Unix()),
+					fmt.Sprintf("%d", window.end.Unix()),
+					granularity,
+					maxCandlesPerRequest,
+				)
+				if err != nil {
+					if ctx.Err() != nil && errors.Is(err, context.Canceled) {
+						continue // Stopped because another chunk failed, that failure is reported
+					}
+					chunkErrs[i] = fmt.Errorf("failed to fetch candles from %s to %s: %w",
+						window.start.Format(time.RFC3339), window.end.Format(time.RFC3339), err)
+					cancel()
+					continue
+				}
+				chunks[i] = chunk
+			}
+		}()
+	}
+	for i := range windows {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Report every chunk that failed, not just the first
+	if err := errors.Join(chunkErrs...); err != nil {
+		return nil, err
+	}
+
+	// Chunk boundaries overlap by one candle, keep the first copy
+	seen := make(map[string]bool, totalCandles)
+	var candles []Candle
+	for _, chunk := range chunks {
+		for _, candle := range chunk {
+			if !seen[candle.Start] {
+				seen[candle.Start] = true
+				candles = append(candles, candle)
+			}
+		}
+	}
+
+	sortCandlesAscending(candles)
+	return candles, nil
+}
+
+// parseCandleTime parses a candle start timestamp. Coinbase returns Unix seconds,
+// but RFC3339 is accepted too so a format change doesn't silently drop candles.
+func parseCandleTime(start string) (time.Time, error) {
+	if unixTime, err := strconv.ParseInt(start, 10, 64); err == nil {
+		return time.Unix(unixTime, 0), nil
+	}
+	if t, err := time.Parse(time.RFC3339, start); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unable to parse candle timestamp: %q", start)
+}
+
+// sortCandlesAscending orders candles oldest-first by their start timestamp.
+// Coinbase returns candles newest-first, while indicators and charts expect chronological order.
+func sortCandlesAscending(candles []Candle) {
+	starts := make(map[string]int64, len(candles))
+	for _, candle := range candles {
+		var start int64
+		if parsed, err := parseCandleTime(candle.Start); err == nil {
+			start = parsed.Unix()
+		}
+		starts[candle.Start] = start
+	}
+
+	sort.SliceStable(candles, func(i, j int) bool {
+		return starts[candles[i].Start] < starts[candles[j].Start]
+	})
+}
+
+// Candle validation modes (CANDLE_VALIDATION)
+const (
+	CandleValidationDrop  = "drop"  // Drop malformed candles and log how many
+	CandleValidationError = "error" // Fail the fetch on the first malformed candle
+)
+
+// ErrInvalidCandle is returned by candle fetches when CANDLE_VALIDATION=error and a candle is malformed
+var ErrInvalidCandle = errors.New("invalid candle")
+
+// checkCandle reports why a candle can't be used: an unparseable or non-positive price, or high below low
+func checkCandle(candle Candle) error {
+	fields := []struct{ name, value string }{
+		{"open", candle.Open}, {"high", candle.High}, {"low", candle.Low}, {"close", candle.Close},
+	}
+	parsed := make([]float64, len(fields))
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(field.value, 64)
+		if err != nil {
+			return fmt.Errorf("unparseable %s %q", field.name, field.value)
+		}
+		if value <= 0 {
+			return fmt.Errorf("non-positive %s %s", field.name, field.value)
+		}
+		parsed[i] = value
+	}
+	if high, low := parsed[1], parsed[2]; high < low {
+		return fmt.Errorf("high %s below low %s", candle.High, candle.Low)
+	}
+	return nil
+}
+
+// validateCandles drops malformed candles, or with CANDLE_VALIDATION=error fails on the first one.
+// Indicators would otherwise read zeros for unparseable prices and skew the EMAs.
+func (c *CoinbaseClient) validateCandles(candles []Candle, granularity string) ([]Candle, error) {
+	valid := make([]Candle, 0, len(candles))
+	var firstErr error
+	for _, candle := range candles {
+		err := checkCandle(candle)
+		if err == nil {
+			valid = append(valid, candle)
+			continue
+		}
+		if c.candleValidation == CandleValidationError {
+			return nil, fmt.Errorf("%w: %s candle at %s: %v", ErrInvalidCandle, granularity, candle.Start, err)
+		}
+		if firstErr == nil {
+			firstErr = fmt.Errorf("candle at %s: %v", candle.Start, err)
+		}
+	}
+
+	if dropped := len(candles) - len(valid); dropped > 0 {
+		c.logger.Printf("[WARN] Dropped %d malformed %s candles out of %d for %s (first: %v)",
+			dropped, granularity, len(candles), c.tradingPair, firstErr)
+	}
+	return valid, nil
+}
+
+// dropFormingCandle removes the last candle if its interval hasn't ended yet at now. Its close, high, low and
+// volume still change until then, which makes indicators computed from it flicker between polls.
+// Candles must be oldest-first, as returned by GetCandles.
+func dropFormingCandle(candles []Candle, granularity string, now time.Time) []Candle {
+	if len(candles) == 0 {
+		return candles
+	}
+	interval, err := granularityDuration(granularity)
+	if err != nil {
+		return candles
+	}
+	start, err := parseCandleTime(candles[len(candles)-1].Start)
+	if err != nil || !start.Add(interval).After(now) {
+		return candles
+	}
+	return candles[:len(candles)-1]
+}
+
+// fillCandleGaps inserts flat candles for missing intervals so indicators see contiguous data.
+// Filled candles carry the previous close forward as open/high/low/close with zero volume.
+// Candles must be oldest-first, as returned by GetCandles.
+func fillCandleGaps(candles []Candle, granularity string) []Candle {
+	duration, err := granularityDuration(granularity)
+	if err != nil || len(candles) < 2 {
+		return candles
+	}
+	interval := int64(duration / time.Second)
+
+	filled := make([]Candle, 0, len(candles))
+	var previousStart int64
+	for i, candle := range candles {
+		startTime, err := parseCandleTime(candle.Start)
+		if err != nil {
+			return candles // Unknown timestamp format, leave data untouched
+		}
+		start := startTime.Unix()
+
+		if i > 0 {
+			previous := candles[i-1]
+			for missing := previousStart + interval; missing < start; missing += interval {
+				filled = append(filled, Candle{
+					Start:  strconv.FormatInt(missing, 10),
+					Low:    previous.Close,
+					High:   previous.Close,
+					Open:   previous.Close,
+					Close:  previous.Close,
+					Volume: "0",
+				})
+			}
+		}
+
+		filled = append(filled, candle)
+		previousStart = start
+	}
+
+	return filled
+}
+
+// ToOHLC converts Coinbase's string-valued candles to numeric OHLCV candles sorted oldest-first
+func ToOHLC(candles []Candle) ([]OHLCCandle, error) {
+	ohlc := make([]OHLCCandle, 0, len(candles))
+	for _, candle := range candles {
+		start, err := parseCandleTime(candle.Start)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]float64, 5)
+		for i, field := range []string{candle.Open, candle.High, candle.Low, candle.Close, candle.Volume} {
+			if values[i], err = strconv.ParseFloat(field, 64); err != nil {
+				return nil, fmt.Errorf("invalid candle value %q at %s: %w", field, candle.Start, err)
+			}
+		}
+		ohlc = append(ohlc, OHLCCandle{
+			Time:   start.Unix(),
+			Open:   values[0],
+			High:   values[1],
+			Low:    values[2],
+			Close:  values[3],
+			Volume: values[4],
+		})
+	}
+
+	sort.SliceStable(ohlc, func(i, j int) bool {
+		return ohlc[i].Time < ohlc[j].Time
+	})
+	return ohlc, nil
+}
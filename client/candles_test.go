@@ -0,0 +1,318 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestValidateCandlesHandlesCorruptCandles(t *testing.T) {
+	candles := candlesFromCloses([]float64{100, 101, 102, 103, 104, 105})
+	candles[1].Close = "NaN-ish"                   // Unparseable
+	candles[3].Low = "0"                           // Non-positive
+	candles[4].High, candles[4].Low = "100", "110" // High below low
+
+	c := &CoinbaseClient{logger: discardLogger(), tradingPair: "BTC-USDC", candleValidation: CandleValidationDrop}
+	valid, err := c.validateCandles(candles, "FIVE_MINUTE")
+	if err != nil {
+		t.Fatalf("drop mode: %v", err)
+	}
+	if len(valid) != 3 || valid[0].Close != "100" || valid[1].Close != "102" || valid[2].Close != "105" {
+		t.Errorf("drop mode kept %v, want the candles closing at 100, 102 and 105", valid)
+	}
+
+	c.candleValidation = CandleValidationError
+	if _, err := c.validateCandles(candles, "FIVE_MINUTE"); !errors.Is(err, ErrInvalidCandle) {
+		t.Errorf("error mode = %v, want ErrInvalidCandle", err)
+	}
+	if _, err := c.validateCandles(candlesFromCloses([]float64{100, 101}), "FIVE_MINUTE"); err != nil {
+		t.Errorf("error mode with clean candles = %v", err)
+	}
+}
+
+func TestDropFormingCandle(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 3, 0, 0, time.UTC)
+	forming := candlesStartingAt(now.Add(-13*time.Minute), []float64{100, 101, 102}) // 11:50, 11:55, 12:00
+	closed := candlesStartingAt(now.Add(-18*time.Minute), []float64{100, 101, 102})  // 11:45, 11:50, 11:55
+
+	tests := []struct {
+		name        string
+		candles     []Candle
+		granularity string
+		want        int
+	}{
+		{"forming last candle dropped", forming, "FIVE_MINUTE", 2},
+		{"closed last candle kept", closed, "FIVE_MINUTE", 3},
+		{"still forming at a longer granularity", closed, "FIFTEEN_MINUTE", 2},
+		{"unknown granularity left alone", forming, "ONE_WEEK", 3},
+		{"no candles", nil, "FIVE_MINUTE", 0},
+	}
+	for _, tt := range tests {
+		if got := dropFormingCandle(tt.candles, tt.granularity, now); len(got) != tt.want {
+			t.Errorf("%s: %d candles left, want %d", tt.name, len(got), tt.want)
+		}
+	}
+}
+
+func TestSignalIndicatorsExcludeFormingCandle(t *testing.T) {
+	now := time.Now()
+	closes := wavyCloses(300)
+	candles := closedCandlesUntil(now, closes)
+	// The candle still forming spikes, as an unfinished candle can
+	formingStart := now.Truncate(5 * time.Minute)
+	candles = append(candles, candlesStartingAt(formingStart, []float64{closes[len(closes)-1] * 2})...)
+
+	fake := newFakeCoinbase()
+	fake.candles = candles
+
+	for _, closedOnly := range []bool{true, false} {
+		c := newTestClient(t, fake)
+		c.closedCandlesOnly = closedOnly
+		indicators, err := c.signalIndicators(len(candles), "FIVE_MINUTE")
+		if err != nil {
+			t.Fatalf("signalIndicators: %v", err)
+		}
+		if time.Now().Truncate(5 * time.Minute).After(formingStart) {
+			t.Skip("the forming candle closed while the test ran")
+		}
+		want := closes[len(closes)-1]
+		if !closedOnly {
+			want *= 2
+		}
+		if indicators.CurrentPrice != want {
+			t.Errorf("closed candles only %v: current price = %v, want %v", closedOnly, indicators.CurrentPrice, want)
+		}
+	}
+}
+
+func TestCandleWindow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) // Unix 1704110400
+	tests := []struct {
+		name, start, end, granularity string
+		count                         int
+		wantStart, wantEnd            string
+	}{
+		{"defaults end to now", "", "", "ONE_HOUR", 24, "1704024000", "1704110400"},
+		{"start from count before end", "", "1704000000", "FIVE_MINUTE", 12, "1703996400", "1704000000"},
+		{"no count fills one request", "", "1704110400", "ONE_MINUTE", 0, "1704089400", "1704110400"},
+		{"explicit start is kept", "1700000000", "", "NOT_A_GRANULARITY", 10, "1700000000", "1704110400"},
+	}
+	for _, tt := range tests {
+		start, end, err := candleWindow(tt.start, tt.end, tt.granularity, tt.count, now)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		} else if start != tt.wantStart || end != tt.wantEnd {
+			t.Errorf("%s: window = %s..%s, want %s..%s", tt.name, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+
+	if _, _, err := candleWindow("", "", "NOT_A_GRANULARITY", 10, now); err == nil {
+		t.Error("unknown granularity did not fail")
+	}
+	if _, _, err := candleWindow("", "2024-01-01", "ONE_HOUR", 10, now); err == nil {
+		t.Error("non-numeric end did not fail")
+	}
+}
+
+func TestFillCandleGaps(t *testing.T) {
+	full := candlesFromCloses([]float64{100, 101, 102, 103, 104, 105})
+	gapped := []Candle{full[0], full[3], full[5]} // Missing 00:05, 00:10 and 00:20
+
+	filled := fillCandleGaps(gapped, "FIVE_MINUTE")
+	if len(filled) != len(full) {
+		t.Fatalf("filled %d candles, want %d", len(filled), len(full))
+	}
+	wantCloses := []string{"100", "100", "100", "103", "103", "105"}
+	for i, candle := range filled {
+		if candle.Start != full[i].Start {
+			t.Errorf("candle %d starts at %s, want %s", i, candle.Start, full[i].Start)
+		}
+		if candle.Close != wantCloses[i] {
+			t.Errorf("candle %d closes at %s, want %s", i, candle.Close, wantCloses[i])
+		}
+	}
+	// Filled candles are flat at the previous close with no volume; real candles are untouched
+	for _, i := range []int{1, 2, 4} {
+		if c := filled[i]; c.Open != c.Close || c.High != c.Close || c.Low != c.Close || c.Volume != "0" {
+			t.Errorf("filled candle %d = %+v, want flat with zero volume", i, c)
+		}
+	}
+	if filled[3] != full[3] || filled[5] != full[5] {
+		t.Error("real candles were changed")
+	}
+
+	// Contiguous data and unknown granularities are left as is
+	if got := fillCandleGaps(full, "FIVE_MINUTE"); len(got) != len(full) {
+		t.Errorf("contiguous candles grew to %d", len(got))
+	}
+	if got := fillCandleGaps(gapped, "NOT_A_GRANULARITY"); len(got) != len(gapped) {
+		t.Errorf("unknown granularity filled to %d candles", len(got))
+	}
+}
+
+func TestFillCandleGapsBeforeIndicators(t *testing.T) {
+	t.Setenv("FILL_CANDLE_GAPS", "true")
+	closes := wavyCloses(300)
+	candles := candlesFromCloses(closes)
+	fake := newFakeCoinbase()
+	fake.candles = append(append([]Candle(nil), candles[:150]...), candles[160:]...) // 50 minutes without trades
+	c := newTestClient(t, fake)
+	c.closedCandlesOnly = false
+	if !c.fillCandleGaps {
+		t.Fatal("FILL_CANDLE_GAPS=true did not enable gap filling")
+	}
+
+	indicators, err := c.signalIndicators(len(fake.candles), "FIVE_MINUTE")
+	if err != nil {
+		t.Fatalf("signalIndicators: %v", err)
+	}
+	want := calculateTechnicalIndicators(fillCandleGaps(fake.candles, "FIVE_MINUTE"), c.indicatorPeriods, c.earlySignalExit)
+	unfilled := calculateTechnicalIndicators(fake.candles, c.indicatorPeriods, c.earlySignalExit)
+	if want.EMA12 == unfilled.EMA12 {
+		t.Fatal("the gap doesn't change the indicators")
+	}
+	if indicators.EMA12 != want.EMA12 || indicators.RSI != want.RSI {
+		t.Errorf("indicators = EMA12 %v RSI %v, want the gap-filled EMA12 %v RSI %v", indicators.EMA12, indicators.RSI, want.EMA12, want.RSI)
+	}
+}
+
+func TestGetCandlesSortsAShuffledResponse(t *testing.T) {
+	ordered := candlesFromCloses([]float64{100, 101, 102, 103, 104, 105})
+	fake := newFakeCoinbase()
+	// Coinbase returns newest-first; a shuffled page must come out the same way
+	fake.candles = []Candle{ordered[3], ordered[5], ordered[0], ordered[4], ordered[1], ordered[2]}
+	c := newTestClient(t, fake)
+
+	candles, err := c.GetCandles("", "", "FIVE_MINUTE", len(ordered))
+	if err != nil {
+		t.Fatalf("GetCandles: %v", err)
+	}
+	if len(candles) != len(ordered) {
+		t.Fatalf("got %d candles, want %d", len(candles), len(ordered))
+	}
+	for i := range candles {
+		if candles[i].Start != ordered[i].Start {
+			t.Errorf("candle %d starts at %s, want %s (oldest first)", i, candles[i].Start, ordered[i].Start)
+		}
+	}
+}
+
+// windowCandles serves one FIVE_MINUTE candle for every step of the requested window, bounds included, newest first
+func windowCandles(w http.ResponseWriter, r *http.Request) {
+	start, _ := strconv.ParseInt(r.URL.Query().Get("start"), 10, 64)
+	end, _ := strconv.ParseInt(r.URL.Query().Get("end"), 10, 64)
+	var candles []Candle
+	for ts := end; ts >= start; ts -= 300 {
+		candles = append(candles, Candle{Start: strconv.FormatInt(ts, 10), Open: "100", High: "101", Low: "99", Close: "100", Volume: "1"})
+	}
+	writeJSON(w, CandlesResponse{Candles: candles})
+}
+
+func TestGetCandlesRangeStitchesConcurrentChunks(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(1000 * 5 * time.Minute)
+	var requests atomic.Int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		// Earlier chunks answer last, so completion order is the reverse of the range
+		chunkStart, _ := strconv.ParseInt(r.URL.Query().Get("start"), 10, 64)
+		time.Sleep(time.Duration(end.Unix()-chunkStart) * time.Microsecond / 20)
+		windowCandles(w, r)
+	}))
+	c.candleFetchConcurrency = 3
+
+	candles, err := c.GetCandlesRange(start, end, "FIVE_MINUTE")
+	if err != nil {
+		t.Fatalf("GetCandlesRange: %v", err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("%d chunk requests, want 3 for 1000 candles", n)
+	}
+
+	// Every step once, oldest first, although chunk bounds overlap and chunks finished out of order
+	if len(candles) != 1001 {
+		t.Fatalf("got %d candles, want 1001", len(candles))
+	}
+	for i, candle := range candles {
+		if want := strconv.FormatInt(start.Add(time.Duration(i)*5*time.Minute).Unix(), 10); candle.Start != want {
+			t.Fatalf("candle %d starts at %s, want %s", i, candle.Start, want)
+		}
+	}
+}
+
+func TestGetCandlesRangeStopsWorkersOnFailure(t *testing.T) {
+	const concurrency = 3
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	firstChunk := strconv.FormatInt(start.Unix(), 10)
+	var requests, hanging atomic.Int32
+	var others sync.WaitGroup
+	others.Add(concurrency - 1)
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Query().Get("start") == firstChunk {
+			// Fail once the other workers are in flight
+			others.Wait()
+			http.Error(w, `{"error":"INVALID_ARGUMENT"}`, http.StatusBadRequest)
+			return
+		}
+		// The other chunks hang until the client gives up on them
+		if hanging.Add(1) < concurrency {
+			others.Done()
+		}
+		<-r.Context().Done()
+	}))
+	c.candleFetchConcurrency = concurrency
+
+	// 3000 candles are 9 chunks: the failure must cancel the requests in flight and leave the queued ones unsent
+	started := time.Now()
+	_, err := c.GetCandlesRange(start, start.Add(3000*5*time.Minute), "FIVE_MINUTE")
+	if err == nil {
+		t.Fatal("GetCandlesRange succeeded with a failing chunk")
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("GetCandlesRange took %v, the hanging chunks were not cancelled", elapsed)
+	}
+	if n := requests.Load(); n > concurrency {
+		t.Errorf("%d chunk requests reached Coinbase, want at most %d once a chunk failed", n, concurrency)
+	}
+}
+
+func TestParseCandleTime(t *testing.T) {
+	want := time.Date(2024, 1, 1, 12, 5, 0, 0, time.UTC)
+	for _, start := range []string{"1704110700", "2024-01-01T12:05:00Z", "2024-01-01T13:05:00+01:00"} {
+		got, err := parseCandleTime(start)
+		if err != nil {
+			t.Errorf("parseCandleTime(%q): %v", start, err)
+		} else if !got.Equal(want) {
+			t.Errorf("parseCandleTime(%q) = %v, want %v", start, got, want)
+		}
+	}
+	for _, start := range []string{"", "yesterday", "2024-01-01 12:05"} {
+		if _, err := parseCandleTime(start); err == nil {
+			t.Errorf("parseCandleTime(%q) did not fail", start)
+		}
+	}
+}
+
+func TestAccountValuesKeepRFC3339Candles(t *testing.T) {
+	c := newTestClient(t, newFakeCoinbase())
+	candles := candlesFromCloses([]float64{50000, 50000, 50000})
+	candles[1].Start = "2024-01-01T00:05:00Z" // Same instant as its Unix timestamp
+
+	values, err := c.CalculateAccountValuesOverTime(candles, nil, time.Time{}, time.Now())
+	if err != nil {
+		t.Fatalf("CalculateAccountValuesOverTime: %v", err)
+	}
+	if len(values) != len(candles) {
+		t.Fatalf("%d account values for %d candles, an RFC3339 candle was dropped", len(values), len(candles))
+	}
+	for i, value := range values {
+		if want := int64(1704067200 + 300*i); value.Timestamp != want {
+			t.Errorf("value %d at %d, want %d", i, value.Timestamp, want)
+		}
+	}
+}
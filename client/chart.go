@@ -7,6 +7,7 @@ import (
 	"image/png"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"gonum.org/v1/plot"
@@ -17,23 +18,19 @@ import (
 )
 
 // GenerateChartPNG creates a sleek PNG chart with two separate graphs
-func (c *CoinbaseClient) GenerateChartPNG(graphData *GraphData) ([]byte, error) {
+func (c *CoinbaseClient) GenerateChartPNG(graphData *GraphData, opts ChartOptions) ([]byte, error) {
 	// Validate input data
 	if len(graphData.Candles) == 0 {
 		return nil, fmt.Errorf("no candle data available")
 	}
 
-	// Helper function to parse timestamps consistently
-	parseTimestamp := func(timeStr string) (time.Time, error) {
-		// Try RFC3339 first
-		if t, err := time.Parse(time.RFC3339, timeStr); err == nil {
-			return t, nil
-		}
-		// Try Unix timestamp
-		if unixTime, err := strconv.ParseInt(timeStr, 10, 64); err == nil {
-			return time.Unix(unixTime, 0), nil
-		}
-		return time.Time{}, fmt.Errorf("unable to parse timestamp: %s", timeStr)
+	// Render axis labels and the title date range in the requested timezone
+	location := opts.Location
+	if location == nil {
+		location = c.chartLocation
+	}
+	unixTimeIn := func(t float64) time.Time {
+		return time.Unix(int64(t), 0).In(location)
 	}
 
 	// Calculate overall time range from all data sources
@@ -41,7 +38,7 @@ func (c *CoinbaseClient) GenerateChartPNG(graphData *GraphData) ([]byte, error)
 
 	// Add candle timestamps
 	for _, candle := range graphData.Candles {
-		timestamp, err := parseTimestamp(candle.Start)
+		timestamp, err := parseCandleTime(candle.Start)
 		if err == nil {
 			allTimestamps = append(allTimestamps, float64(timestamp.Unix()))
 		}
@@ -72,15 +69,28 @@ func (c *CoinbaseClient) GenerateChartPNG(graphData *GraphData) ([]byte, error)
 		}
 	}
 
-	// Create a large image to hold both charts
-	img := vgimg.New(12*vg.Inch, 10*vg.Inch)
+	// Create a large image to hold both charts, growing it when the volume panel is added
+	var volumeHeight vg.Length
+	if opts.Volume {
+		volumeHeight = 2 * vg.Inch
+	}
+	img := vgimg.New(12*vg.Inch, 10*vg.Inch+volumeHeight)
 	dc := draw.New(img)
 
-	// Create top chart (BTC Price and Trades) - takes 70% of height
+	// Label prices and values with the configured pair's currencies
+	base, quote, err := c.pairCurrencies()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create top chart (base currency price and trades) - takes 70% of height
 	topChart := plot.New()
-	topChart.Title.Text = fmt.Sprintf("BTC-USDC Price Chart (%s)", graphData.Period)
+	topChart.Title.Text = c.chartTitle(graphData, location)
 	topChart.X.Label.Text = "Time"
-	topChart.Y.Label.Text = "BTC Price (USD)"
+	if len(graphData.Warnings) > 0 {
+		topChart.X.Label.Text += "\nNote: " + strings.Join(graphData.Warnings, ", ")
+	}
+	topChart.Y.Label.Text = fmt.Sprintf("%s Price (%s)", base, quote)
 
 	// Set X-axis range for top chart
 	if maxTime > minTime {
@@ -91,7 +101,7 @@ func (c *CoinbaseClient) GenerateChartPNG(graphData *GraphData) ([]byte, error)
 	// Create candlestick data
 	candles := make(plotter.XYs, 0, len(graphData.Candles))
 	for _, candle := range graphData.Candles {
-		timestamp, err := parseTimestamp(candle.Start)
+		timestamp, err := parseCandleTime(candle.Start)
 		if err != nil {
 			continue
 		}
@@ -113,14 +123,9 @@ func (c *CoinbaseClient) GenerateChartPNG(graphData *GraphData) ([]byte, error)
 		return nil, fmt.Errorf("no valid candle data after parsing")
 	}
 
-	// Sort candles by time
-	sort.Slice(candles, func(i, j int) bool {
-		return candles[i].X < candles[j].X
-	})
-
 	// Add candlesticks to top chart
 	for _, candle := range graphData.Candles {
-		timestamp, err := parseTimestamp(candle.Start)
+		timestamp, err := parseCandleTime(candle.Start)
 		if err != nil {
 			continue
 		}
@@ -173,15 +178,16 @@ func (c *CoinbaseClient) GenerateChartPNG(graphData *GraphData) ([]byte, error)
 	if len(graphData.Indicators.EMA12) > 0 && len(graphData.Indicators.EMA12) == len(graphData.Candles) {
 		ema12Data := make(plotter.XYs, 0, len(candles))
 		for i, candle := range graphData.Candles {
-			timestamp, err := parseTimestamp(candle.Start)
+			timestamp, err := parseCandleTime(candle.Start)
 			if err != nil {
 				continue
 			}
+			// Skip warm-up points that have no value yet
 			ema12Value := graphData.Indicators.EMA12[i]
-			if ema12Value > 0 {
+			if ema12Value != nil {
 				ema12Data = append(ema12Data, plotter.XY{
 					X: float64(timestamp.Unix()),
-					Y: ema12Value,
+					Y: *ema12Value,
 				})
 			}
 		}
@@ -198,15 +204,16 @@ func (c *CoinbaseClient) GenerateChartPNG(graphData *GraphData) ([]byte, error)
 	if len(graphData.Indicators.EMA26) > 0 && len(graphData.Indicators.EMA26) == len(graphData.Candles) {
 		ema26Data := make(plotter.XYs, 0, len(candles))
 		for i, candle := range graphData.Candles {
-			timestamp, err := parseTimestamp(candle.Start)
+			timestamp, err := parseCandleTime(candle.Start)
 			if err != nil {
 				continue
 			}
+			// Skip warm-up points that have no value yet
 			ema26Value := graphData.Indicators.EMA26[i]
-			if ema26Value > 0 {
+			if ema26Value != nil {
 				ema26Data = append(ema26Data, plotter.XY{
 					X: float64(timestamp.Unix()),
-					Y: ema26Value,
+					Y: *ema26Value,
 				})
 			}
 		}
@@ -261,7 +268,7 @@ func (c *CoinbaseClient) GenerateChartPNG(graphData *GraphData) ([]byte, error)
 	}
 
 	// Format X-axis as time
-	topChart.X.Tick.Marker = plot.TimeTicks{Format: "01-02 15:04"}
+	topChart.X.Tick.Marker = plot.TimeTicks{Format: "01-02 15:04", Time: unixTimeIn}
 
 	// Add legend to top chart
 	topChart.Legend.Top = true
@@ -292,11 +299,21 @@ func (c *CoinbaseClient) GenerateChartPNG(graphData *GraphData) ([]byte, error)
 		topChart.Legend.Add("Sell", sellScatter)
 	}
 
+	// Create volume chart (bars colored by candle direction), sharing the price chart time axis
+	var volumeChart *plot.Plot
+	if opts.Volume {
+		volumeChart = volumePanel(graphData.Candles, minTime, maxTime)
+		volumeChart.X.Tick.Marker = plot.TimeTicks{Format: "01-02 15:04", Time: unixTimeIn}
+	}
+
 	// Create bottom chart (Asset Value Line Chart) - takes 30% of height
 	bottomChart := plot.New()
 	bottomChart.Title.Text = "Total Asset Value Evolution"
+	if len(graphData.AccountValues) == 0 {
+		bottomChart.Title.Text += " (no asset history yet)"
+	}
 	bottomChart.X.Label.Text = "Time"
-	bottomChart.Y.Label.Text = "Asset Value (USD)"
+	bottomChart.Y.Label.Text = fmt.Sprintf("Asset Value (%s)", c.assetValueCurrency(graphData))
 
 	// Set X-axis range for bottom chart (same as top chart)
 	if maxTime > minTime {
@@ -306,23 +323,18 @@ func (c *CoinbaseClient) GenerateChartPNG(graphData *GraphData) ([]byte, error)
 
 	// Create line chart data for asset values
 	if len(graphData.AccountValues) > 0 {
-		// Group asset values by day for cleaner chart
-		dailyValues := make(map[string]float64)
-		for _, av := range graphData.AccountValues {
-			timestamp := time.Unix(av.Timestamp, 0)
-			dayKey := timestamp.Format("2006-01-02")
-			if dailyValues[dayKey] == 0 || av.TotalUSD > dailyValues[dayKey] {
-				dailyValues[dayKey] = av.TotalUSD
-			}
+		// Downsample long series so the chart stays fast and readable (the graph data itself is untouched)
+		maxPoints := opts.MaxPoints
+		if maxPoints <= 0 {
+			maxPoints = defaultChartPoints
 		}
+		values := downsampleLTTB(graphData.AccountValues, maxPoints)
 
-		// Convert to sorted data
-		var lineData plotter.XYs
-		for dayKey, value := range dailyValues {
-			timestamp, _ := time.Parse("2006-01-02", dayKey)
+		lineData := make(plotter.XYs, 0, len(values))
+		for _, av := range values {
 			lineData = append(lineData, plotter.XY{
-				X: float64(timestamp.Unix()),
-				Y: value,
+				X: float64(av.Timestamp),
+				Y: av.TotalValue,
 			})
 		}
 
@@ -349,18 +361,30 @@ func (c *CoinbaseClient) GenerateChartPNG(graphData *GraphData) ([]byte, error)
 	}
 
 	// Format X-axis as time for bottom chart
-	bottomChart.X.Tick.Marker = plot.TimeTicks{Format: "01-02"}
+	bottomChart.X.Tick.Marker = plot.TimeTicks{Format: "01-02", Time: unixTimeIn}
 
-	// Draw top chart (70% of height)
+	// Draw top chart (70% of height, above the volume panel when present)
 	topCanvas := draw.Canvas{
 		Canvas: dc,
 		Rectangle: vg.Rectangle{
-			Min: vg.Point{X: 0, Y: 3 * vg.Inch}, // Bottom 30% for bottom chart
-			Max: vg.Point{X: 12 * vg.Inch, Y: 10 * vg.Inch},
+			Min: vg.Point{X: 0, Y: 3*vg.Inch + volumeHeight}, // Bottom 30% for bottom chart
+			Max: vg.Point{X: 12 * vg.Inch, Y: 10*vg.Inch + volumeHeight},
 		},
 	}
 	topChart.Draw(topCanvas)
 
+	// Draw volume chart between the price and asset value charts
+	if volumeChart != nil {
+		volumeCanvas := draw.Canvas{
+			Canvas: dc,
+			Rectangle: vg.Rectangle{
+				Min: vg.Point{X: 0, Y: 3 * vg.Inch},
+				Max: vg.Point{X: 12 * vg.Inch, Y: 3*vg.Inch + volumeHeight},
+			},
+		}
+		volumeChart.Draw(volumeCanvas)
+	}
+
 	// Draw bottom chart (30% of height)
 	bottomCanvas := draw.Canvas{
 		Canvas: dc,
@@ -371,23 +395,97 @@ func (c *CoinbaseClient) GenerateChartPNG(graphData *GraphData) ([]byte, error)
 	}
 	bottomChart.Draw(bottomCanvas)
 
-	// Add overall title with asset value information to top chart
-	if len(graphData.AccountValues) > 0 {
-		firstValue := graphData.AccountValues[0].TotalUSD
-		lastValue := graphData.AccountValues[len(graphData.AccountValues)-1].TotalUSD
-		valueChange := lastValue - firstValue
-		valueChangePct := (valueChange / firstValue) * 100
-
-		topChart.Title.Text = fmt.Sprintf("BTC-USDC Trading Chart (%s) - Asset Value: $%.2f → $%.2f (%.1f%%)",
-			graphData.Period, firstValue, lastValue, valueChangePct)
-	}
-
 	// Convert to PNG bytes
+	return encodeChartPNG(img)
+}
+
+// encodeChartPNG encodes a rendered chart canvas as PNG bytes
+func encodeChartPNG(img *vgimg.Canvas) ([]byte, error) {
 	var buf bytes.Buffer
-	err = png.Encode(&buf, img.Image())
-	if err != nil {
+	if err := png.Encode(&buf, img.Image()); err != nil {
 		return nil, fmt.Errorf("failed to encode PNG: %w", err)
 	}
-
 	return buf.Bytes(), nil
 }
+
+// volumePanel plots one bar per candle from zero to its volume, green when the candle closed up and red otherwise
+func volumePanel(candles []Candle, minTime, maxTime float64) *plot.Plot {
+	volumeChart := plot.New()
+	volumeChart.Y.Label.Text = "Volume"
+	volumeChart.Y.Min = 0
+
+	if maxTime > minTime {
+		volumeChart.X.Min = minTime
+		volumeChart.X.Max = maxTime
+	}
+
+	for _, candle := range candles {
+		timestamp, err := parseCandleTime(candle.Start)
+		if err != nil {
+			continue
+		}
+
+		volume, _ := strconv.ParseFloat(candle.Volume, 64)
+		if volume <= 0 {
+			continue
+		}
+		openPrice, _ := strconv.ParseFloat(candle.Open, 64)
+		closePrice, _ := strconv.ParseFloat(candle.Close, 64)
+
+		barData := plotter.XYs{
+			{X: float64(timestamp.Unix()), Y: 0},
+			{X: float64(timestamp.Unix()), Y: volume},
+		}
+		bar, err := plotter.NewLine(barData)
+		if err != nil {
+			continue
+		}
+		if closePrice > openPrice {
+			bar.Color = color.RGBA{R: 0, G: 200, B: 0, A: 255}
+		} else {
+			bar.Color = color.RGBA{R: 255, G: 0, B: 0, A: 255}
+		}
+		bar.Width = vg.Points(2)
+		volumeChart.Add(bar)
+	}
+
+	return volumeChart
+}
+
+// chartTitle builds the top chart title with the trading pair, the rendered candle date range and the asset
+// value change. It must be set before the chart is drawn; candles are expected oldest-first.
+func (c *CoinbaseClient) chartTitle(graphData *GraphData, location *time.Location) string {
+	title := fmt.Sprintf("%s Trading Chart (%s)", c.tradingPair, graphData.Period)
+
+	first, errFirst := parseCandleTime(graphData.Candles[0].Start)
+	last, errLast := parseCandleTime(graphData.Candles[len(graphData.Candles)-1].Start)
+	if errFirst == nil && errLast == nil {
+		title += fmt.Sprintf(" %s – %s %s", first.In(location).Format("2006-01-02 15:04"),
+			last.In(location).Format("2006-01-02 15:04"), location)
+	}
+
+	if len(graphData.AccountValues) == 0 {
+		return title + " - Asset Value: no asset history yet"
+	}
+
+	firstValue := graphData.AccountValues[0].TotalValue
+	lastValue := graphData.AccountValues[len(graphData.AccountValues)-1].TotalValue
+	valueChange := lastValue - firstValue
+	var valueChangePct float64
+	if firstValue != 0 {
+		valueChangePct = (valueChange / firstValue) * 100
+	}
+
+	currency := c.assetValueCurrency(graphData)
+	return title + fmt.Sprintf(" - Asset Value: %s → %s (%.1f%%)",
+		formatAmount(firstValue, currency), formatAmount(lastValue, currency), valueChangePct)
+}
+
+// assetValueCurrency returns the currency of the charted asset values: the one recorded with the latest
+// sample, or VALUATION_CURRENCY when samples don't carry it
+func (c *CoinbaseClient) assetValueCurrency(graphData *GraphData) string {
+	if n := len(graphData.AccountValues); n > 0 && graphData.AccountValues[n-1].Currency != "" {
+		return graphData.AccountValues[n-1].Currency
+	}
+	return c.valuationCurrency
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChartTitleForNonUSDPair(t *testing.T) {
+	graphData := &GraphData{
+		Period:  "week",
+		Candles: candlesFromCloses([]float64{2000, 2100}),
+		AccountValues: []AccountValue{
+			{Timestamp: 1, TotalValue: 1000, Currency: "EUR"},
+			{Timestamp: 2, TotalValue: 1100, Currency: "EUR"},
+		},
+	}
+
+	tests := []struct {
+		pair, valuation, currency string
+		want                      string
+	}{
+		{"ETH-EUR", "EUR", "EUR", "ETH-EUR Trading Chart (week) 2024-01-01 00:00 – 2024-01-01 00:05 UTC - Asset Value: €1000.00 → €1100.00 (10.0%)"},
+		// Samples without a currency fall back to VALUATION_CURRENCY; a currency without symbol is shown by code
+		{"ETH-SGD", "SGD", "", "ETH-SGD Trading Chart (week) 2024-01-01 00:00 – 2024-01-01 00:05 UTC - Asset Value: 1000.00 SGD → 1100.00 SGD (10.0%)"},
+	}
+	for _, tt := range tests {
+		for i := range graphData.AccountValues {
+			graphData.AccountValues[i].Currency = tt.currency
+		}
+		c := &CoinbaseClient{tradingPair: tt.pair, valuationCurrency: tt.valuation}
+		title := c.chartTitle(graphData, time.UTC)
+		if title != tt.want {
+			t.Errorf("%s title = %q\nwant %q", tt.pair, title, tt.want)
+		}
+		if strings.Contains(title, "$") || strings.Contains(title, "BTC") {
+			t.Errorf("%s title mentions the default pair or dollars: %q", tt.pair, title)
+		}
+	}
+}
+
+func TestChartTitleFromAZeroAssetValue(t *testing.T) {
+	graphData := &GraphData{
+		Period:  "day",
+		Candles: candlesFromCloses([]float64{50000, 50100}),
+		AccountValues: []AccountValue{
+			{Timestamp: 1, TotalValue: 0, Currency: "USD"},
+			{Timestamp: 2, TotalValue: 1000, Currency: "USD"},
+		},
+	}
+	c := &CoinbaseClient{tradingPair: "BTC-USDC", valuationCurrency: "USD"}
+
+	title := c.chartTitle(graphData, time.UTC)
+	if !strings.HasSuffix(title, "$0.00 → $1000.00 (0.0%)") {
+		t.Errorf("title = %q, want a 0.0%% change from an empty account", title)
+	}
+	if strings.Contains(title, "Inf") || strings.Contains(title, "NaN") {
+		t.Errorf("title = %q", title)
+	}
+}
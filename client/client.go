@@ -6,6 +6,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -15,7 +16,11 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/shopspring/decimal"
+	"golang.org/x/time/rate"
 )
 
 // Context key for health check tracking
@@ -23,26 +28,130 @@ type contextKey string
 
 const healthCheckKey contextKey = "health_check"
 
+// defaultAssetHistoryMax is the default number of asset value samples kept in memory
+const defaultAssetHistoryMax = 1000
+
+// defaultAssetSampleInterval is the default minimum time between two asset value samples
+const defaultAssetSampleInterval = time.Minute
+
+// ErrAssetSampleSkipped is returned by TrackAssetValue when the last sample is more recent than ASSET_SAMPLE_MIN_INTERVAL
+var ErrAssetSampleSkipped = errors.New("asset value sample skipped: last sample is too recent")
+
+// trendScoreThreshold is the weighted bullish/bearish score needed to call a trend
+const trendScoreThreshold = 7.0
+
+// Default bounds for scaling the trend score threshold by volatility (ADAPTIVE_THRESHOLDS)
+const (
+	defaultAdaptiveMinScale = 0.7
+	defaultAdaptiveMaxScale = 1.5
+)
+
+// defaultAnomalyZScore is the price z-score (vs the long EMA) that raises a PRICE_ANOMALY trigger
+const defaultAnomalyZScore = 3.0
+
+// defaultCandleFetchConcurrency is the number of chunk requests GetCandlesRange runs in parallel
+const defaultCandleFetchConcurrency = 2
+
+// defaultRetryShrinkPct is how much an order rejected for insufficient funds shrinks before its one retry
+const defaultRetryShrinkPct = 0.5
+
+// defaultCoinbaseRPS is a conservative default below Coinbase's per-second private endpoint limit
+const defaultCoinbaseRPS = 10.0
+
 // CoinbaseClient represents a custom Coinbase Advanced Trade API client
 type CoinbaseClient struct {
-	logger            *log.Logger
-	apiKey            string
-	privateKey        *ecdsa.PrivateKey
-	tradingPair       string
-	webhookURL        string
-	webhookMaxRetries int
-	webhookTimeout    int
-	httpClient        *http.Client
+	logger              *log.Logger
+	errorLog            *LogThrottle // Collapses errors repeated on every poll (LOG_REPEAT_WINDOW)
+	debug               bool         // Cached LOG_LEVEL == DEBUG check, read once at construction
+	apiKey              string
+	privateKey          *ecdsa.PrivateKey
+	tradingPair         string
+	webhookURL          string
+	executionWebhookURL string // Notified when an order fills (EXECUTION_WEBHOOK_URL), separate from signal webhooks
+	// Orders still open after placement, by ID, with the filled size already sent to the execution webhook
+	watchedOrders      map[string]decimal.Decimal
+	watchedOrdersMutex sync.Mutex
+	webhookMaxRetries  int
+	webhookTimeout     int
+	httpClient         *http.Client
+	rateLimiter        *rate.Limiter // Keeps outgoing Coinbase requests under COINBASE_RPS
+	baseRPS            rate.Limit    // Configured COINBASE_RPS, restored after a rate-limit slowdown
+	// Coinbase rate-limit headers
+	rateLimitStatus            RateLimitStatus
+	rateLimitMux               sync.Mutex
+	rateLimitSlowdownRemaining int              // Halve the request rate below this many remaining requests, zero disables (RATE_LIMIT_SLOWDOWN_REMAINING)
+	fillCandleGaps             bool             // Insert flat candles for missing intervals before indicator calculation
+	closedCandlesOnly          bool             // Drop the still-forming last candle before signal indicators (SIGNAL_CLOSED_CANDLES_ONLY)
+	candleValidation           string           // What to do with malformed candles: drop them or fail the fetch (CANDLE_VALIDATION)
+	candleFetchConcurrency     int              // Parallel chunk requests in GetCandlesRange (CANDLE_FETCH_CONCURRENCY)
+	signalGranularity          string           // Candle granularity used by GetSignal
+	signalCandles              int              // Candle count used by GetSignal
+	indicatorPeriods           IndicatorPeriods // EMA/MACD/RSI/ADX lookback periods
+	scoringWeights             ScoringWeights   // Points of the trend and dip detector conditions (SCORING_WEIGHTS_FILE)
+	earlySignalExit            bool             // Stop indicator calculation on the first bearish hint (EARLY_SIGNAL_EXIT)
+	maxOrderNotional           decimal.Decimal  // Reject orders whose size*price exceeds this (zero disables the cap)
+	dryRun                     bool             // Plan rebalances without placing orders (DRY_RUN)
+	maxSpreadBps               float64          // Reject orders while the spread is wider than this (zero disables the guard)
+	retryShrinkOnInsufficient  bool             // Retry an order rejected for insufficient funds once with a smaller size
+	retryShrinkPct             float64          // How much smaller the retried order is, in percent (RETRY_SHRINK_PCT)
+	// Order status polling after placement
+	orderStatusPollTimeout  time.Duration // Total time to wait for a terminal order state (default ~500ms, checked twice)
+	orderStatusPollInterval time.Duration // Delay between order status checks
+	// Extra status reads when every poll failed, before the order is reported as UNKNOWN
+	orderStatusRetries       int
+	orderStatusRetryInterval time.Duration
 	// Performance tracking
-	requestCount int64
-	startTime    time.Time
-	// Trend state tracking
+	requestCount      int64
+	startTime         time.Time
+	endpointCounts    map[string]int64 // Requests per Coinbase endpoint ("GET /orders/historical/{id}")
+	endpointCountsMux sync.Mutex
+	// Trend state tracking, guarded by trendMutex (with lastAnomalyTime) as the poller and /signal run concurrently
+	trendMutex          sync.Mutex
 	lastTrendState      string // "bullish", "bearish", or "neutral"
 	lastSignalTime      time.Time
+	trendStateFile      string        // JSON file persisting lastTrendState/lastSignalTime per pair (TREND_STATE_FILE)
+	webhookSequenceFile string        // File persisting the webhook sequence counter (WEBHOOK_SEQUENCE_FILE)
+	webhookFormat       string        // Signal webhook format: raw, slack or discord (WEBHOOK_FORMAT)
+	valuationCurrency   string        // Currency asset values are reported in (VALUATION_CURRENCY)
 	trendChangeCooldown time.Duration // Minimum time between trend change signals
+	minVolumeForSignal  float64       // Suppress signals while 24h base volume is below this (zero disables)
+	// Multi-timeframe confirmation
+	multiTimeframeConfirm bool   // Require the higher timeframe to lean the same way before emitting (MULTI_TIMEFRAME_CONFIRM)
+	confirmGranularity    string // Higher timeframe checked for confirmation (MULTI_TIMEFRAME_GRANULARITY)
+	// Volatility-scaled trend threshold (ADAPTIVE_THRESHOLDS)
+	adaptiveThresholds bool    // Scale trendScoreThreshold by EWMA volatility relative to its baseline
+	adaptiveMinScale   float64 // Lowest threshold multiplier, reached in calm markets (ADAPTIVE_THRESHOLD_MIN_SCALE)
+	adaptiveMaxScale   float64 // Highest threshold multiplier, reached in volatile markets (ADAPTIVE_THRESHOLD_MAX_SCALE)
+	// Price anomaly detection
+	anomalyZScoreThreshold float64   // |z-score| of price vs the long EMA that raises PRICE_ANOMALY (PRICE_ANOMALY_ZSCORE)
+	lastAnomalyTime        time.Time // Guarded by trendMutex
 	// Asset value tracking
-	assetValueHistory []AccountValue
-	assetValueMutex   sync.RWMutex
+	assetValueHistory  []AccountValue
+	assetValueMutex    sync.RWMutex
+	assetHistoryMax    int           // Maximum number of samples kept (ASSET_HISTORY_MAX)
+	assetHistoryMaxAge time.Duration // Samples older than this are pruned, zero keeps all (ASSET_HISTORY_MAX_AGE)
+	assetSampleEvery   time.Duration // Minimum time between samples unless forced (ASSET_SAMPLE_MIN_INTERVAL)
+	lastAssetSample    time.Time     // When the last sample was recorded
+	// Chart rendering
+	chartLocation *time.Location // Timezone for chart labels (CHART_TIMEZONE, then TZ, default UTC)
+	// Spread tracking
+	spreadHistory      []SpreadSample
+	spreadHistoryMutex sync.RWMutex
+	// Signal candle cache, topped up incrementally by GetSignalWithCandles
+	candleCache      []Candle
+	candleCacheKey   string // "<granularity>/<count>" of the cached candles
+	candleCacheMutex sync.Mutex
+	// Short-lived market state (per order book depth) and product stats cache, dropped on order placement
+	marketCacheTTL        time.Duration // MARKET_CACHE_TTL
+	marketStateCache      map[int]cachedMarketState
+	productStatsCache     *ProductStats
+	productStatsFetchedAt time.Time
+	marketCacheGeneration uint64 // Bumped by InvalidateMarketCache so fetches started before it aren't cached
+	marketCacheMutex      sync.Mutex
+	// Shutdown drain: trades in flight are waited for, new ones are refused once draining
+	drainMutex     sync.Mutex
+	draining       bool
+	inFlightTrades sync.WaitGroup
 }
 
 // NewCoinbaseClient creates a new Coinbase client using ECDSA private key
@@ -50,8 +159,17 @@ func NewCoinbaseClient(tradingPair string, webhookURL string, webhookMaxRetries
 	apiKey := os.Getenv("COINBASE_API_KEY")
 	apiSecret := os.Getenv("COINBASE_API_SECRET")
 
-	if apiKey == "" || apiSecret == "" {
-		return nil, fmt.Errorf("missing required environment variables: COINBASE_API_KEY, COINBASE_API_SECRET")
+	// A mounted secret file (e.g. Kubernetes) takes precedence over the inline PEM
+	if secretFile := os.Getenv("COINBASE_API_SECRET_FILE"); secretFile != "" {
+		secret, err := os.ReadFile(secretFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read COINBASE_API_SECRET_FILE %s: %w", secretFile, err)
+		}
+		apiSecret = string(secret)
+	}
+
+	if apiKey == "" || strings.TrimSpace(apiSecret) == "" {
+		return nil, fmt.Errorf("missing required environment variables: COINBASE_API_KEY, COINBASE_API_SECRET (or COINBASE_API_SECRET_FILE)")
 	}
 
 	// Initialize logger
@@ -88,104 +206,319 @@ func NewCoinbaseClient(tradingPair string, webhookURL string, webhookMaxRetries
 		return nil, fmt.Errorf("failed to parse ECDSA private key: %w", err)
 	}
 
+	// Load indicator periods (defaults: EMA 12/26/200, MACD 12/26/9, RSI/ADX 14)
+	indicatorPeriods, err := loadIndicatorPeriods()
+	if err != nil {
+		return nil, fmt.Errorf("invalid indicator configuration: %w", err)
+	}
+
+	// Load detector weights (defaults: the built-in weights, overridden by SCORING_WEIGHTS_FILE)
+	scoringWeights, err := loadScoringWeights()
+	if err != nil {
+		return nil, err
+	}
+
+	// Load GetSignal candle configuration (defaults: five-minute candles, just enough for the indicator periods)
+	signalGranularity := strings.ToUpper(os.Getenv("DEFAULT_SIGNAL_GRANULARITY"))
+	if signalGranularity == "" {
+		signalGranularity = "FIVE_MINUTE"
+	}
+	minSignalCandles := indicatorPeriods.minSignalCandles()
+	signalCandles := getEnvInt("DEFAULT_SIGNAL_CANDLES", minSignalCandles)
+	if err := validateSignalCandles(signalGranularity, signalCandles, indicatorPeriods.EMATrend); err != nil {
+		return nil, fmt.Errorf("invalid signal configuration: %w", err)
+	}
+	if logLevel == "DEBUG" {
+		logger.Printf("Signal candles: %d %s (minimum for indicator periods: %d)", signalCandles, signalGranularity, minSignalCandles)
+	}
+
+	// Load the higher timeframe used to confirm signals (default: ONE_HOUR)
+	confirmGranularity := strings.ToUpper(os.Getenv("MULTI_TIMEFRAME_GRANULARITY"))
+	if confirmGranularity == "" {
+		confirmGranularity = defaultConfirmGranularity
+	}
+	multiTimeframeConfirm := getEnvBool("MULTI_TIMEFRAME_CONFIRM", false)
+	if multiTimeframeConfirm {
+		if err := validateConfirmGranularity(confirmGranularity, signalGranularity); err != nil {
+			return nil, fmt.Errorf("invalid MULTI_TIMEFRAME_GRANULARITY: %w", err)
+		}
+	}
+
+	// Load the chart timezone (CHART_TIMEZONE takes precedence over TZ)
+	chartLocation, err := chartLocationFromEnv(logger)
+	if err != nil {
+		return nil, err
+	}
+
 	logger.Printf("Successfully loaded ECDSA private key")
 	logger.Printf("Trading pair: %s", tradingPair)
 
+	// Load adaptive threshold bounds (calm markets lower the threshold, volatile ones raise it)
+	adaptiveMinScale := getEnvFloat("ADAPTIVE_THRESHOLD_MIN_SCALE", defaultAdaptiveMinScale)
+	adaptiveMaxScale := getEnvFloat("ADAPTIVE_THRESHOLD_MAX_SCALE", defaultAdaptiveMaxScale)
+	if adaptiveMinScale > 1 || adaptiveMaxScale < 1 {
+		return nil, fmt.Errorf("invalid adaptive thresholds: ADAPTIVE_THRESHOLD_MIN_SCALE (%.2f) must be <= 1 and ADAPTIVE_THRESHOLD_MAX_SCALE (%.2f) >= 1",
+			adaptiveMinScale, adaptiveMaxScale)
+	}
+
+	// Currency asset values are reported in (cross rates are looked up when it differs from the quote currency)
+	valuationCurrency := strings.ToUpper(strings.TrimSpace(os.Getenv("VALUATION_CURRENCY")))
+	if valuationCurrency == "" {
+		valuationCurrency = defaultValuationCurrency
+	}
+
+	// Load connection pool tuning (defaults suit a handful of pairs against a single Coinbase host)
+	maxIdleConns, err := getEnvNonNegativeInt("HTTP_MAX_IDLE_CONNS", 100)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP pool configuration: %w", err)
+	}
+	maxIdleConnsPerHost, err := getEnvNonNegativeInt("HTTP_MAX_IDLE_CONNS_PER_HOST", 10)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP pool configuration: %w", err)
+	}
+	maxConnsPerHost, err := getEnvNonNegativeInt("HTTP_MAX_CONNS_PER_HOST", 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP pool configuration: %w", err)
+	}
+	idleConnTimeout, err := getEnvNonNegativeDuration("HTTP_IDLE_CONN_TIMEOUT", 90*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP pool configuration: %w", err)
+	}
+
+	orderStatusRetries, err := getEnvNonNegativeInt("ORDER_STATUS_RETRIES", 3)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order status configuration: %w", err)
+	}
+
+	// Load how malformed candles are handled
+	candleValidation := strings.ToLower(os.Getenv("CANDLE_VALIDATION"))
+	if candleValidation == "" {
+		candleValidation = CandleValidationDrop
+	}
+	if candleValidation != CandleValidationDrop && candleValidation != CandleValidationError {
+		return nil, fmt.Errorf("invalid CANDLE_VALIDATION %q (use %s or %s)", candleValidation, CandleValidationDrop, CandleValidationError)
+	}
+
+	// Load the signal webhook format
+	webhookFormat := strings.ToLower(os.Getenv("WEBHOOK_FORMAT"))
+	if webhookFormat == "" {
+		webhookFormat = WebhookFormatRaw
+	}
+	if webhookFormat != WebhookFormatRaw && webhookFormat != WebhookFormatSlack && webhookFormat != WebhookFormatDiscord {
+		return nil, fmt.Errorf("invalid WEBHOOK_FORMAT %q (use %s, %s or %s)", webhookFormat, WebhookFormatRaw, WebhookFormatSlack, WebhookFormatDiscord)
+	}
+
+	// Load request rate and the remaining-request threshold that slows it down
+	baseRPS := rate.Limit(getEnvFloat("COINBASE_RPS", defaultCoinbaseRPS))
+	rateLimitSlowdownRemaining, err := getEnvNonNegativeInt("RATE_LIMIT_SLOWDOWN_REMAINING", defaultRateLimitSlowdownRemaining)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limit configuration: %w", err)
+	}
+
 	// Create optimized HTTP client with connection pooling
 	httpClient := &http.Client{
 		Timeout: 10 * time.Second,
 		Transport: &http.Transport{
-			MaxIdleConns:        100,              // Maximum number of idle connections
-			MaxIdleConnsPerHost: 10,               // Maximum idle connections per host
-			IdleConnTimeout:     90 * time.Second, // How long to keep idle connections
-			DisableCompression:  false,            // Keep compression for smaller payloads
-			ForceAttemptHTTP2:   true,             // Use HTTP/2 for better performance
-			DisableKeepAlives:   false,            // Enable keep-alive for connection reuse
-			MaxConnsPerHost:     0,                // No limit on connections per host
+			// Idle connections kept across all hosts (0 = unlimited); more avoids TLS handshakes under load, fewer saves sockets
+			MaxIdleConns: maxIdleConns,
+			// Idle connections kept per host (0 = Go default of 2); all requests go to api.coinbase.com, so raise this for many pairs or high COINBASE_RPS
+			MaxIdleConnsPerHost: maxIdleConnsPerHost,
+			// How long an idle connection is kept; longer reuses more, shorter frees sockets sooner on quiet deployments
+			IdleConnTimeout:    idleConnTimeout,
+			DisableCompression: false,           // Keep compression for smaller payloads
+			ForceAttemptHTTP2:  true,            // Use HTTP/2 for better performance
+			DisableKeepAlives:  false,           // Enable keep-alive for connection reuse
+			MaxConnsPerHost:    maxConnsPerHost, // Cap on total connections per host (0 = no limit), excess requests wait for a free one
 		},
 	}
 
-	return &CoinbaseClient{
-		logger:              logger,
-		apiKey:              apiKey,
-		privateKey:          privateKey,
-		tradingPair:         tradingPair,
-		webhookURL:          webhookURL,
-		webhookMaxRetries:   webhookMaxRetries,
-		webhookTimeout:      webhookTimeout,
-		httpClient:          httpClient,
-		startTime:           time.Now(),
-		trendChangeCooldown: 8 * time.Minute, // Increased from 2 to 8 minutes to reduce signal frequency
-	}, nil
+	client := &CoinbaseClient{
+		logger:                     logger,
+		debug:                      logLevel == "DEBUG",
+		apiKey:                     apiKey,
+		privateKey:                 privateKey,
+		tradingPair:                tradingPair,
+		webhookURL:                 webhookURL,
+		executionWebhookURL:        os.Getenv("EXECUTION_WEBHOOK_URL"),
+		webhookMaxRetries:          webhookMaxRetries,
+		webhookTimeout:             webhookTimeout,
+		errorLog:                   NewLogThrottle(logger, LogRepeatWindow()),
+		httpClient:                 httpClient,
+		rateLimiter:                rate.NewLimiter(baseRPS, 1),
+		baseRPS:                    baseRPS,
+		rateLimitSlowdownRemaining: rateLimitSlowdownRemaining,
+		fillCandleGaps:             getEnvBool("FILL_CANDLE_GAPS", false),
+		closedCandlesOnly:          getEnvBool("SIGNAL_CLOSED_CANDLES_ONLY", true),
+		candleValidation:           candleValidation,
+		earlySignalExit:            getEnvBool("EARLY_SIGNAL_EXIT", false),
+		candleFetchConcurrency:     getEnvInt("CANDLE_FETCH_CONCURRENCY", defaultCandleFetchConcurrency),
+		marketCacheTTL:             getEnvDuration("MARKET_CACHE_TTL", defaultMarketCacheTTL),
+		signalGranularity:          signalGranularity,
+		signalCandles:              signalCandles,
+		indicatorPeriods:           indicatorPeriods,
+		scoringWeights:             scoringWeights,
+		maxOrderNotional:           decimal.NewFromFloat(getEnvFloat("MAX_ORDER_NOTIONAL_USD", 0)),
+		dryRun:                     getEnvBool("DRY_RUN", false),
+		maxSpreadBps:               getEnvFloat("MAX_SPREAD_BPS", 0),
+		retryShrinkOnInsufficient:  getEnvBool("RETRY_SHRINK_ON_INSUFFICIENT", false),
+		retryShrinkPct:             getEnvFloat("RETRY_SHRINK_PCT", defaultRetryShrinkPct),
+		orderStatusPollTimeout:     time.Duration(getEnvInt("ORDER_STATUS_POLL_TIMEOUT_MS", 500)) * time.Millisecond,
+		orderStatusPollInterval:    time.Duration(getEnvInt("ORDER_STATUS_POLL_INTERVAL_MS", 250)) * time.Millisecond,
+		orderStatusRetries:         orderStatusRetries,
+		orderStatusRetryInterval:   time.Duration(getEnvInt("ORDER_STATUS_RETRY_INTERVAL_MS", 500)) * time.Millisecond,
+		startTime:                  time.Now(),
+		endpointCounts:             make(map[string]int64),
+		trendChangeCooldown:        8 * time.Minute, // Increased from 2 to 8 minutes to reduce signal frequency
+		anomalyZScoreThreshold:     getEnvFloat("PRICE_ANOMALY_ZSCORE", defaultAnomalyZScore),
+		adaptiveThresholds:         getEnvBool("ADAPTIVE_THRESHOLDS", false),
+		adaptiveMinScale:           adaptiveMinScale,
+		adaptiveMaxScale:           adaptiveMaxScale,
+		minVolumeForSignal:         getEnvFloat("MIN_VOLUME_FOR_SIGNAL", 0),
+		multiTimeframeConfirm:      multiTimeframeConfirm,
+		confirmGranularity:         confirmGranularity,
+		assetHistoryMax:            getEnvInt("ASSET_HISTORY_MAX", defaultAssetHistoryMax),
+		assetHistoryMaxAge:         getEnvDuration("ASSET_HISTORY_MAX_AGE", 0),
+		assetSampleEvery:           getEnvDuration("ASSET_SAMPLE_MIN_INTERVAL", defaultAssetSampleInterval),
+		chartLocation:              chartLocation,
+		trendStateFile:             os.Getenv("TREND_STATE_FILE"),
+		webhookSequenceFile:        os.Getenv("WEBHOOK_SEQUENCE_FILE"),
+		webhookFormat:              webhookFormat,
+		valuationCurrency:          valuationCurrency,
+	}
+
+	// Resume the trend detector where it left off before a restart
+	if err := client.loadTrendState(); err != nil {
+		return nil, fmt.Errorf("failed to load trend state: %w", err)
+	}
+
+	return client, nil
 }
 
-// TrackAssetValue adds the current asset value to the historical tracking
+// TrackAssetValue adds the current asset value to the historical tracking. It returns ErrAssetSampleSkipped
+// without fetching anything when the last sample is less than ASSET_SAMPLE_MIN_INTERVAL old, so frequent
+// manual signal checks don't flood the history with near-duplicate points.
 func (c *CoinbaseClient) TrackAssetValue() error {
-	// Get current account balances
-	accounts, err := c.GetAccounts()
-	if err != nil {
-		return fmt.Errorf("failed to get current accounts: %w", err)
-	}
+	return c.trackAssetValue(false)
+}
 
-	// Find BTC and USDC accounts
-	var btcAccount, usdcAccount *Account
-	for i := range accounts {
-		if accounts[i].Currency == "BTC" {
-			btcAccount = &accounts[i]
-		} else if accounts[i].Currency == "USDC" {
-			usdcAccount = &accounts[i]
-		}
-	}
+// ForceTrackAssetValue adds the current asset value to the historical tracking regardless of the minimum interval
+func (c *CoinbaseClient) ForceTrackAssetValue() error {
+	return c.trackAssetValue(true)
+}
+
+// assetSampleDue reports whether a new sample may be recorded at now. Callers must hold assetValueMutex.
+func (c *CoinbaseClient) assetSampleDue(now time.Time) bool {
+	return c.lastAssetSample.IsZero() || now.Sub(c.lastAssetSample) >= c.assetSampleEvery
+}
 
-	if btcAccount == nil || usdcAccount == nil {
-		return fmt.Errorf("missing BTC or USDC accounts")
+// trackAssetValue records one asset value sample; unless forced, it is skipped while the last one is too recent
+func (c *CoinbaseClient) trackAssetValue(force bool) error {
+	// Skip early to avoid the balance and price requests
+	if !force {
+		c.assetValueMutex.RLock()
+		due := c.assetSampleDue(time.Now())
+		c.assetValueMutex.RUnlock()
+		if !due {
+			return ErrAssetSampleSkipped
+		}
 	}
 
-	// Get current BTC price for USD calculation
-	orderBook, err := c.GetOrderBook(1)
+	// Get current base and quote balances
+	baseBalance, quoteBalance, err := c.pairBalances()
 	if err != nil {
-		return fmt.Errorf("failed to get current price: %w", err)
+		return err
 	}
 
-	var currentPrice float64
-	if len(orderBook.Bids) > 0 {
-		currentPrice, _ = strconv.ParseFloat(orderBook.Bids[0].Price, 64)
-	} else {
-		return fmt.Errorf("no current price available")
+	// Get current base price for the quote currency valuation
+	currentPrice, err := c.currentBidPrice()
+	if err != nil {
+		return err
 	}
 
-	// Calculate total USD value
-	btcBalance, _ := strconv.ParseFloat(btcAccount.AvailableBalance, 64)
-	usdcBalance, _ := strconv.ParseFloat(usdcAccount.AvailableBalance, 64)
-	totalUSD := usdcBalance + (btcBalance * currentPrice)
+	// Value in the quote currency, then convert to VALUATION_CURRENCY
+	_, quote, _ := c.pairCurrencies()
+	totalQuote := quoteBalance + (baseBalance * currentPrice)
+	rate, currency := c.valuationRate(quote)
 
 	// Create account value entry
 	accountValue := AccountValue{
-		Timestamp: time.Now().Unix(),
-		BTC:       btcBalance,
-		USDC:      usdcBalance,
-		TotalUSD:  totalUSD,
+		Timestamp:  time.Now().Unix(),
+		BTC:        baseBalance,
+		USDC:       quoteBalance,
+		TotalQuote: totalQuote,
+		TotalUSD:   totalQuote,
+		TotalValue: totalQuote * rate,
+		Currency:   currency,
 	}
 
 	// Add to history with thread safety
 	c.assetValueMutex.Lock()
 	defer c.assetValueMutex.Unlock()
 
-	// Keep only last 1000 entries to prevent memory bloat
-	if len(c.assetValueHistory) >= 1000 {
-		c.assetValueHistory = c.assetValueHistory[1:]
+	// Check again: a concurrent call may have recorded a sample while the balances were fetched
+	now := time.Now()
+	if !force && !c.assetSampleDue(now) {
+		return ErrAssetSampleSkipped
 	}
 
 	c.assetValueHistory = append(c.assetValueHistory, accountValue)
+	c.lastAssetSample = now
+	c.pruneAssetHistory(now)
 
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
-		c.logger.Printf("Asset value tracked: $%.2f (BTC: %.8f, USDC: %.2f)",
-			totalUSD, btcBalance, usdcBalance)
+	if c.debug {
+		c.logger.Printf("Asset value tracked: %.2f %s (base: %.8f, quote: %.2f %s)",
+			accountValue.TotalValue, currency, baseBalance, quoteBalance, quote)
 	}
 
 	return nil
 }
 
+// currentBidPrice returns the best bid, falling back to the product's last trade price when the
+// order book is unavailable or momentarily empty so asset history stays continuous
+func (c *CoinbaseClient) currentBidPrice() (float64, error) {
+	orderBook, bookErr := c.GetOrderBook(1)
+	if bookErr == nil && len(orderBook.Bids) > 0 {
+		if price, err := strconv.ParseFloat(orderBook.Bids[0].Price, 64); err == nil && price > 0 {
+			return price, nil
+		}
+	}
+
+	product, err := c.getProduct()
+	if err != nil {
+		return 0, fmt.Errorf("no current price available: order book empty and product lookup failed: %w", err)
+	}
+	price, err := strconv.ParseFloat(product.Price, 64)
+	if err != nil || price <= 0 {
+		return 0, fmt.Errorf("no current price available: order book empty and no last price for %s", c.tradingPair)
+	}
+
+	if bookErr != nil {
+		c.logger.Printf("[WARN] Order book unavailable (%v), valuing assets at last price %.2f", bookErr, price)
+	} else if c.debug {
+		c.logger.Printf("Order book has no bids, valuing assets at last price %.2f", price)
+	}
+	return price, nil
+}
+
+// pruneAssetHistory drops samples older than assetHistoryMaxAge and keeps at most assetHistoryMax entries.
+// The caller must hold assetValueMutex.
+func (c *CoinbaseClient) pruneAssetHistory(now time.Time) {
+	start := 0
+	if c.assetHistoryMaxAge > 0 {
+		cutoff := now.Add(-c.assetHistoryMaxAge).Unix()
+		for start < len(c.assetValueHistory) && c.assetValueHistory[start].Timestamp < cutoff {
+			start++
+		}
+	}
+	if c.assetHistoryMax > 0 && len(c.assetValueHistory)-start > c.assetHistoryMax {
+		start = len(c.assetValueHistory) - c.assetHistoryMax
+	}
+	if start > 0 {
+		// Copy so the evicted prefix of the backing array can be released
+		c.assetValueHistory = append([]AccountValue(nil), c.assetValueHistory[start:]...)
+	}
+}
+
 // GetAssetValueHistory returns the historical asset values
 func (c *CoinbaseClient) GetAssetValueHistory() []AccountValue {
 	c.assetValueMutex.RLock()
@@ -212,11 +545,50 @@ func (c *CoinbaseClient) GetAssetValueHistoryForPeriod(startTime, endTime time.T
 	return result
 }
 
+// SetDebug enables or disables debug logging (LOG_LEVEL is only read once at construction)
+func (c *CoinbaseClient) SetDebug(enabled bool) {
+	c.debug = enabled
+}
+
+// chartLocationFromEnv returns the timezone chart labels use: CHART_TIMEZONE, which must be a valid IANA name,
+// then TZ, then UTC. TZ also accepts POSIX forms such as "UTC0" or ":/etc/localtime" that time.LoadLocation
+// rejects, so an unusable TZ only logs a warning and falls back to UTC.
+func chartLocationFromEnv(logger *log.Logger) (*time.Location, error) {
+	if name := os.Getenv("CHART_TIMEZONE"); name != "" {
+		location, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CHART_TIMEZONE %q: %w", name, err)
+		}
+		return location, nil
+	}
+
+	name := os.Getenv("TZ")
+	if name == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		logger.Printf("[WARN] TZ %q is not a timezone name (%v), charts use UTC; set CHART_TIMEZONE to choose one", name, err)
+		return time.UTC, nil
+	}
+	return location, nil
+}
+
+// Debug reports whether debug logging is enabled, so callers can skip debug work without reading LOG_LEVEL
+func (c *CoinbaseClient) Debug() bool {
+	return c.debug
+}
+
 // GetTradingPair returns the configured trading pair
 func (c *CoinbaseClient) GetTradingPair() string {
 	return c.tradingPair
 }
 
+// KeyLoaded reports whether the API private key was parsed, the only client state liveness depends on
+func (c *CoinbaseClient) KeyLoaded() bool {
+	return c.privateKey != nil
+}
+
 // Close closes the HTTP client and cleans up resources
 func (c *CoinbaseClient) Close() error {
 	if c.httpClient != nil {
@@ -228,11 +600,82 @@ func (c *CoinbaseClient) Close() error {
 // GetPerformanceStats returns performance statistics
 func (c *CoinbaseClient) GetPerformanceStats() map[string]interface{} {
 	uptime := time.Since(c.startTime)
+	requestCount := atomic.LoadInt64(&c.requestCount)
+	return map[string]interface{}{
+		"uptime_seconds":       uptime.Seconds(),
+		"total_requests":       requestCount,
+		"requests_per_second":  float64(requestCount) / uptime.Seconds(),
+		"requests_by_endpoint": c.endpointRequestCounts(),
+		"current_rps":          float64(c.currentRPS()),
+		"rate_limit":           c.GetRateLimitStatus(),
+		"trading_pair":         c.tradingPair,
+	}
+}
+
+// GetEffectiveConfig returns the client settings actually in effect after env defaults (no secrets)
+func (c *CoinbaseClient) GetEffectiveConfig() map[string]interface{} {
 	return map[string]interface{}{
-		"uptime_seconds":      uptime.Seconds(),
-		"total_requests":      c.requestCount,
-		"requests_per_second": float64(c.requestCount) / uptime.Seconds(),
-		"trading_pair":        c.tradingPair,
+		"trading_pair":                   c.tradingPair,
+		"coinbase_rps":                   float64(c.baseRPS),
+		"rate_limit_slowdown_remaining":  c.rateLimitSlowdownRemaining,
+		"fill_candle_gaps":               c.fillCandleGaps,
+		"signal_closed_candles_only":     c.closedCandlesOnly,
+		"candle_validation":              c.candleValidation,
+		"candle_fetch_concurrency":       c.candleFetchConcurrency,
+		"market_cache_ttl":               c.marketCacheTTL.String(),
+		"signal_granularity":             c.signalGranularity,
+		"signal_candles":                 c.signalCandles,
+		"indicator_periods":              c.indicatorPeriods,
+		"scoring_weights_file":           os.Getenv("SCORING_WEIGHTS_FILE"),
+		"scoring_weights":                c.scoringWeights,
+		"early_signal_exit":              c.earlySignalExit,
+		"trend_score_threshold":          trendScoreThreshold,
+		"adaptive_thresholds":            c.adaptiveThresholds,
+		"adaptive_threshold_min_scale":   c.adaptiveMinScale,
+		"adaptive_threshold_max_scale":   c.adaptiveMaxScale,
+		"trend_change_cooldown_seconds":  c.trendChangeCooldown.Seconds(),
+		"price_anomaly_zscore":           c.anomalyZScoreThreshold,
+		"min_volume_for_signal":          c.minVolumeForSignal,
+		"multi_timeframe_confirm":        c.multiTimeframeConfirm,
+		"multi_timeframe_granularity":    c.confirmGranularity,
+		"max_order_notional_usd":         c.maxOrderNotional.InexactFloat64(),
+		"dry_run":                        c.dryRun,
+		"max_spread_bps":                 c.maxSpreadBps,
+		"retry_shrink_on_insufficient":   c.retryShrinkOnInsufficient,
+		"retry_shrink_pct":               c.retryShrinkPct,
+		"order_status_poll_timeout_ms":   c.orderStatusPollTimeout.Milliseconds(),
+		"order_status_poll_interval_ms":  c.orderStatusPollInterval.Milliseconds(),
+		"order_status_retries":           c.orderStatusRetries,
+		"order_status_retry_interval_ms": c.orderStatusRetryInterval.Milliseconds(),
+		"execution_webhook_configured":   c.executionWebhookURL != "",
+		"execution_poll_interval":        ExecutionPollInterval().String(),
+		"webhook_max_retries":            c.webhookMaxRetries,
+		"webhook_timeout_seconds":        c.webhookTimeout,
+		"asset_history_max":              c.assetHistoryMax,
+		"asset_history_max_age":          c.assetHistoryMaxAge.String(),
+		"asset_sample_min_interval":      c.assetSampleEvery.String(),
+		"log_repeat_window":              c.errorLog.window.String(),
+		"chart_timezone":                 c.chartLocation.String(),
+		"trend_state_file":               c.trendStateFile,
+		"webhook_sequence_file":          c.webhookSequenceFile,
+		"webhook_format":                 c.webhookFormat,
+		"valuation_currency":             c.valuationCurrency,
+		"http_pool":                      c.httpPoolConfig(),
+		"debug":                          c.debug,
+	}
+}
+
+// httpPoolConfig reports the connection pool settings of the HTTP transport
+func (c *CoinbaseClient) httpPoolConfig() map[string]interface{} {
+	transport, ok := c.httpClient.Transport.(*http.Transport)
+	if !ok {
+		return nil
+	}
+	return map[string]interface{}{
+		"max_idle_conns":          transport.MaxIdleConns,
+		"max_idle_conns_per_host": transport.MaxIdleConnsPerHost,
+		"max_conns_per_host":      transport.MaxConnsPerHost,
+		"idle_conn_timeout":       transport.IdleConnTimeout.String(),
 	}
 }
 
@@ -246,30 +689,33 @@ func (c *CoinbaseClient) SendWebhook(signal *SignalResponse) error {
 	baseDelay := 1 * time.Second
 	startTime := time.Now()
 
+	// One sequence number per event: retries carry the same one so receivers can drop duplicates
+	sequence := c.NextWebhookSequence()
+
 	// Debug: Log webhook start
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("🚀 Starting webhook delivery (max retries: %d, timeout: %ds)", maxRetries, c.webhookTimeout)
 	}
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
-		if os.Getenv("LOG_LEVEL") == "DEBUG" && attempt > 0 {
+		if c.debug && attempt > 0 {
 			c.logger.Printf("🔄 Webhook attempt %d/%d", attempt+1, maxRetries+1)
 		}
 
 		attemptStartTime := time.Now()
-		err := c.sendWebhookAttempt(signal)
+		err := c.sendWebhookAttempt(signal, sequence)
 		duration := time.Since(attemptStartTime)
 
 		if err == nil {
 			// Success - log based on retry count
 			if attempt == 0 {
-				if os.Getenv("LOG_LEVEL") == "DEBUG" {
+				if c.debug {
 					c.logger.Printf("✅ Webhook sent successfully to %s (duration: %v)", c.webhookURL, duration)
 				} else {
 					c.logger.Printf("Webhook sent successfully to %s", c.webhookURL)
 				}
 			} else {
-				if os.Getenv("LOG_LEVEL") == "DEBUG" {
+				if c.debug {
 					c.logger.Printf("✅ Webhook sent successfully to %s after %d retries (total duration: %v)", c.webhookURL, attempt, duration)
 				} else {
 					c.logger.Printf("Webhook sent successfully to %s after %d retries", c.webhookURL, attempt)
@@ -279,7 +725,7 @@ func (c *CoinbaseClient) SendWebhook(signal *SignalResponse) error {
 		}
 
 		// Error logging - always log errors
-		if os.Getenv("LOG_LEVEL") == "DEBUG" {
+		if c.debug {
 			c.logger.Printf("❌ Webhook failed (attempt %d/%d, duration: %v): %v", attempt+1, maxRetries+1, duration, err)
 		} else {
 			c.logger.Printf("Webhook failed (attempt %d/%d): %v", attempt+1, maxRetries+1, err)
@@ -287,7 +733,7 @@ func (c *CoinbaseClient) SendWebhook(signal *SignalResponse) error {
 
 		// If this was the last attempt, give up
 		if attempt == maxRetries {
-			if os.Getenv("LOG_LEVEL") == "DEBUG" {
+			if c.debug {
 				c.logger.Printf("💀 Webhook failed after %d attempts, giving up (total time: %v)", maxRetries+1, time.Since(startTime))
 			} else {
 				c.logger.Printf("Webhook failed after %d attempts, giving up", maxRetries+1)
@@ -297,7 +743,7 @@ func (c *CoinbaseClient) SendWebhook(signal *SignalResponse) error {
 
 		// Calculate delay with exponential backoff
 		delay := time.Duration(float64(baseDelay) * math.Pow(2, float64(attempt)))
-		if os.Getenv("LOG_LEVEL") == "DEBUG" {
+		if c.debug {
 			c.logger.Printf("⏳ Retrying webhook in %v (exponential backoff: attempt %d)", delay, attempt+1)
 		} else {
 			c.logger.Printf("Retrying webhook in %v...", delay)
@@ -309,29 +755,26 @@ func (c *CoinbaseClient) SendWebhook(signal *SignalResponse) error {
 }
 
 // sendWebhookAttempt performs a single webhook attempt
-func (c *CoinbaseClient) sendWebhookAttempt(signal *SignalResponse) error {
-	// Create HTTP request
-	req, err := http.NewRequest("GET", c.webhookURL, nil)
+func (c *CoinbaseClient) sendWebhookAttempt(signal *SignalResponse, sequence uint64) error {
+	// Create HTTP request in the configured format
+	req, err := c.newSignalWebhookRequest(signal, sequence)
 	if err != nil {
 		return fmt.Errorf("failed to create webhook request: %w", err)
 	}
 
-	// Add query parameters for GET request
-	q := req.URL.Query()
-	q.Add("signal", "true")
-	q.Add("bearish", "true")
-	q.Add("triggers", strings.Join(signal.Triggers, ","))
-	q.Add("timestamp", fmt.Sprintf("%d", signal.Timestamp))
-	req.URL.RawQuery = q.Encode()
-
 	// Debug logging for webhook request
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("🔗 Webhook Request:")
 		c.logger.Printf("   URL: %s", req.URL.String())
 		c.logger.Printf("   Method: %s", req.Method)
 		c.logger.Printf("   Headers: %v", req.Header)
-		c.logger.Printf("   Query Params: signal=true, bearish=true, triggers=%s, timestamp=%d",
-			strings.Join(signal.Triggers, ","), signal.Timestamp)
+		if c.webhookFormat == WebhookFormatRaw {
+			c.logger.Printf("   Query Params: signal=true, bearish=%t, recommendation=%s, triggers=%s, timestamp=%d, pair=%s, sequence=%d",
+				signal.BearishSignal, signal.Recommendation, strings.Join(signal.Triggers, ","), signal.Timestamp, c.tradingPair, sequence)
+		} else {
+			c.logger.Printf("   Format: %s (recommendation=%s, triggers=%s, pair=%s, sequence=%d)",
+				c.webhookFormat, signal.Recommendation, strings.Join(signal.Triggers, ","), c.tradingPair, sequence)
+		}
 	}
 
 	// Set timeout for this attempt
@@ -342,7 +785,7 @@ func (c *CoinbaseClient) sendWebhookAttempt(signal *SignalResponse) error {
 	// Send request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		if os.Getenv("LOG_LEVEL") == "DEBUG" {
+		if c.debug {
 			c.logger.Printf("❌ Webhook Request Failed: %v", err)
 		}
 		return fmt.Errorf("failed to send webhook: %w", err)
@@ -350,7 +793,7 @@ func (c *CoinbaseClient) sendWebhookAttempt(signal *SignalResponse) error {
 	defer resp.Body.Close()
 
 	// Debug logging for webhook response
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("📡 Webhook Response:")
 		c.logger.Printf("   Status: %s", resp.Status)
 		c.logger.Printf("   Status Code: %d", resp.StatusCode)
@@ -369,21 +812,22 @@ func (c *CoinbaseClient) sendWebhookAttempt(signal *SignalResponse) error {
 
 	// Check response status
 	if resp.StatusCode >= 400 {
-		if os.Getenv("LOG_LEVEL") == "DEBUG" {
+		if c.debug {
 			c.logger.Printf("❌ Webhook Response Error: HTTP %d", resp.StatusCode)
 		}
 		return fmt.Errorf("webhook failed with status %d", resp.StatusCode)
 	}
 
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("✅ Webhook Response Success: HTTP %d", resp.StatusCode)
 	}
 
 	return nil
 }
 
-// detectTrendChange determines if there's been a significant trend change that warrants a webhook
-func (c *CoinbaseClient) detectTrendChange(indicators TechnicalIndicators) (bool, string, []string) {
+// detectTrendChange determines if there's been a significant trend change that warrants a webhook.
+// bias is the higher timeframe bias from confirmationBias, read before trendMutex is taken.
+func (c *CoinbaseClient) detectTrendChange(indicators TechnicalIndicators, bias string) (bool, string, []string) {
 	// Determine current trend state based on indicators
 	currentTrend := c.determineTrendState(indicators)
 
@@ -392,32 +836,43 @@ func (c *CoinbaseClient) detectTrendChange(indicators TechnicalIndicators) (bool
 	bullishScore := c.calculateBullishScore(indicators)
 
 	// Debug logging for weighted scores
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
-		c.logger.Printf("📊 Weighted Scores - Bearish: %.2f, Bullish: %.2f, Trend: %s",
-			bearishScore, bullishScore, currentTrend)
+	if c.debug {
+		c.logger.Printf("📊 Weighted Scores - Bearish: %.2f, Bullish: %.2f, Threshold: %.2f, Trend: %s",
+			bearishScore, bullishScore, c.effectiveThreshold(indicators), currentTrend)
+	}
+
+	// Hold back signals while liquidity is too thin for the indicators to be trusted
+	if c.belowVolumeFloor() {
+		return false, currentTrend, nil
 	}
 
+	// Decide and record the change atomically so concurrent checks can't both emit it
+	c.trendMutex.Lock()
+	defer c.trendMutex.Unlock()
+
 	// Check for immediate dip detection (more sensitive)
 	dipDetected, dipTriggers := c.detectImmediateDip(indicators)
 	if dipDetected {
-		// Check longer cooldown for dips (5 minutes instead of 2)
-		if time.Since(c.lastSignalTime) < 5*time.Minute {
-			if os.Getenv("LOG_LEVEL") == "DEBUG" {
+		// Check the shorter cooldown for dips
+		if time.Since(c.lastSignalTime) < dipCooldown {
+			if c.debug {
 				c.logger.Printf("🕐 Dip detected but cooldown active (last signal: %v ago)",
 					time.Since(c.lastSignalTime))
 			}
 		} else {
 			// Only trigger dip if it represents a trend change (neutral → bearish or bullish → bearish)
-			if c.lastTrendState != "bearish" {
+			// confirmed by the higher timeframe when MULTI_TIMEFRAME_CONFIRM is set
+			if c.lastTrendState != "bearish" && c.confirmTrend("bearish", bias) {
 				// Valid dip detected that changes the trend
 				c.lastSignalTime = time.Now()
-				if os.Getenv("LOG_LEVEL") == "DEBUG" {
+				c.saveTrendState()
+				if c.debug {
 					c.logger.Printf("📉 Immediate dip detected (trend change): %v", dipTriggers)
 				}
 				return true, "bearish", dipTriggers
-			} else {
+			} else if c.lastTrendState == "bearish" {
 				// Dip detected but trend is already bearish - no change
-				if os.Getenv("LOG_LEVEL") == "DEBUG" {
+				if c.debug {
 					c.logger.Printf("📉 Dip detected but trend already bearish - no change")
 				}
 			}
@@ -426,10 +881,11 @@ func (c *CoinbaseClient) detectTrendChange(indicators TechnicalIndicators) (bool
 
 	// Check if this is a significant change from the last known state
 	if c.lastTrendState == "neutral" {
-		// First signal - only send if we have a clear trend
-		if currentTrend != "neutral" {
+		// First signal - only send if we have a clear (and confirmed) trend
+		if currentTrend != "neutral" && c.confirmTrend(currentTrend, bias) {
 			c.lastTrendState = currentTrend
 			c.lastSignalTime = time.Now()
+			c.saveTrendState()
 			triggers := c.calculateTriggers(indicators, currentTrend)
 			return true, currentTrend, triggers
 		}
@@ -440,19 +896,25 @@ func (c *CoinbaseClient) detectTrendChange(indicators TechnicalIndicators) (bool
 	if currentTrend != c.lastTrendState {
 		// Check cooldown period to avoid spam (increased to 8 minutes)
 		if time.Since(c.lastSignalTime) < 8*time.Minute {
-			if os.Getenv("LOG_LEVEL") == "DEBUG" {
+			if c.debug {
 				c.logger.Printf("🕐 Trend change detected but cooldown active (last signal: %v ago)",
 					time.Since(c.lastSignalTime))
 			}
 			return false, currentTrend, nil
 		}
 
+		// Hold back a change the higher timeframe doesn't agree with; it is checked again on the next poll
+		if !c.confirmTrend(currentTrend, bias) {
+			return false, currentTrend, nil
+		}
+
 		// Valid trend change detected
 		oldTrend := c.lastTrendState
 		c.lastTrendState = currentTrend
 		c.lastSignalTime = time.Now()
+		c.saveTrendState()
 
-		if os.Getenv("LOG_LEVEL") == "DEBUG" {
+		if c.debug {
 			c.logger.Printf("🔄 Trend change detected: %s → %s", oldTrend, currentTrend)
 		}
 
@@ -463,82 +925,123 @@ func (c *CoinbaseClient) detectTrendChange(indicators TechnicalIndicators) (bool
 	return false, currentTrend, nil
 }
 
+// belowVolumeFloor reports whether the 24h volume is under MIN_VOLUME_FOR_SIGNAL (disabled when unset).
+// If the volume cannot be fetched, signals are not suppressed.
+func (c *CoinbaseClient) belowVolumeFloor() bool {
+	if c.minVolumeForSignal <= 0 {
+		return false
+	}
+	product, err := c.getProduct()
+	if err != nil {
+		c.errorLog.Printf("volume-floor", "[WARN] Could not check 24h volume for signal suppression: %v", err)
+		return false
+	}
+	c.errorLog.Clear("volume-floor")
+	volume, err := strconv.ParseFloat(product.Volume24h, 64)
+	if err != nil || volume >= c.minVolumeForSignal {
+		return false
+	}
+	c.logger.Printf("🔇 Signal suppressed: 24h volume %.4f is below MIN_VOLUME_FOR_SIGNAL %.4f", volume, c.minVolumeForSignal)
+	return true
+}
+
+// detectPriceAnomaly reports whether the price z-score exceeds the anomaly threshold.
+// Alerts are rate-limited by the trend change cooldown so a lasting spike isn't re-sent every poll.
+func (c *CoinbaseClient) detectPriceAnomaly(indicators TechnicalIndicators) (anomaly bool, alert bool) {
+	if math.Abs(indicators.PriceZScore) < c.anomalyZScoreThreshold {
+		return false, false
+	}
+
+	c.trendMutex.Lock()
+	defer c.trendMutex.Unlock()
+	if time.Since(c.lastAnomalyTime) < c.trendChangeCooldown {
+		return true, false
+	}
+	c.lastAnomalyTime = time.Now()
+	c.logger.Printf("⚡ Price anomaly: price is %.2f standard deviations from EMA%d", indicators.PriceZScore, c.indicatorPeriods.EMALong)
+	return true, true
+}
+
 // detectImmediateDip detects immediate price dips using weighted scoring
 func (c *CoinbaseClient) detectImmediateDip(indicators TechnicalIndicators) (bool, []string) {
+	score, triggers := scoreImmediateDip(indicators, c.scoringWeights.Dip)
+	if score >= c.scoringWeights.Dip.Threshold { // High confidence dip
+		return true, triggers
+	}
+	return false, nil
+}
+
+// scoreImmediateDip returns the weighted dip score of the indicators and the triggers that contributed to it
+func scoreImmediateDip(indicators TechnicalIndicators, w DipScoreWeights) (float64, []string) {
 	var triggers []string
 	dipScore := 0.0
 
-	// Price drop detection (weight: 2.0 - direct price action)
+	// Price drop detection (default weight: 2.0 - direct price action)
 	if indicators.PriceDropPct12h < -3 {
 		dropStrength := math.Abs(indicators.PriceDropPct12h)
 		if dropStrength > 7 {
-			dipScore += 3.0 // Strong drop
+			dipScore += w.PriceDropStrong // Strong drop
 			triggers = append(triggers, "STRONG_PRICE_DROP")
 		} else if dropStrength > 5 {
-			dipScore += 2.0 // Moderate drop
+			dipScore += w.PriceDropModerate // Moderate drop
 			triggers = append(triggers, "IMMEDIATE_PRICE_DROP")
 		} else {
-			dipScore += 1.0 // Slight drop
+			dipScore += w.PriceDropSlight // Slight drop
 		}
 	}
 
-	// RSI oversold condition (weight: 1.5 - momentum)
+	// RSI oversold condition (default weight: 1.5 - momentum)
 	if indicators.RSI < 35 {
 		if indicators.RSI < 25 {
-			dipScore += 2.5 // Extreme oversold
+			dipScore += w.RSIExtreme // Extreme oversold
 			triggers = append(triggers, "EXTREME_RSI_OVERSOLD")
 		} else {
-			dipScore += 1.5 // Moderate oversold
+			dipScore += w.RSIOversold // Moderate oversold
 			triggers = append(triggers, "RSI_OVERSOLD")
 		}
 	}
 
-	// MACD bearish crossover (weight: 2.0 - trend indicator)
+	// MACD bearish crossover (default weight: 2.0 - trend indicator)
 	if indicators.MACD < indicators.SignalLine {
 		macdStrength := math.Abs(indicators.MACD - indicators.SignalLine)
 		if indicators.MACD < -0.15 {
-			dipScore += 2.5 + (macdStrength * 10) // Strong bearish MACD
+			dipScore += w.MACDStrong + (macdStrength * w.MACDStrengthBonus) // Strong bearish MACD
 			triggers = append(triggers, "STRONG_MACD_BEARISH")
 		} else if indicators.MACD < -0.05 {
-			dipScore += 2.0 // Moderate bearish MACD
+			dipScore += w.MACDModerate // Moderate bearish MACD
 			triggers = append(triggers, "MACD_BEARISH_CROSSOVER")
 		} else {
-			dipScore += 1.0 // Slight bearish MACD
+			dipScore += w.MACDSlight // Slight bearish MACD
 		}
 	}
 
-	// EMA bearish crossover (weight: 2.0 - trend indicator)
+	// EMA bearish crossover (default weight: 2.0 - trend indicator)
 	if indicators.EMA12 < indicators.EMA26 {
 		emaStrength := (indicators.EMA26 - indicators.EMA12) / indicators.EMA26 * 100
-		dipScore += 2.0 + (emaStrength * 0.1) // Bonus for stronger crossover
+		dipScore += w.EMACrossover + (emaStrength * w.EMAStrengthBonus) // Bonus for stronger crossover
 		triggers = append(triggers, "EMA_BEARISH_CROSSOVER")
 	}
 
-	// Volume spike with price drop (weight: 1.0 - confirmation)
+	// Volume spike with price drop (default weight: 1.0 - confirmation)
 	if indicators.VolumeSpike && indicators.PriceDropPct12h < -2 {
-		dipScore += 1.0
+		dipScore += w.VolumeSpike
 		triggers = append(triggers, "VOLUME_SPIKE_WITH_DROP")
 	}
 
-	// Strong bearish momentum (weight: 1.5 - trend strength)
+	// Strong bearish momentum (default weight: 1.5 - trend strength)
 	if indicators.ADX > 25 && indicators.MACD < indicators.SignalLine {
-		dipScore += 1.5
+		dipScore += w.StrongMomentum
 		triggers = append(triggers, "STRONG_BEARISH_MOMENTUM")
 	}
 
-	// Price below EMA200 with momentum (weight: 1.0 - long-term trend)
-	if indicators.CurrentPrice < indicators.EMA200 && indicators.RSI < 40 {
+	// Price below EMA200 with momentum (default weight: 1.0 - long-term trend)
+	if indicators.hasTrendEMA() && indicators.CurrentPrice < indicators.EMA200 && indicators.RSI < 40 {
 		ema200Strength := (indicators.EMA200 - indicators.CurrentPrice) / indicators.EMA200 * 100
-		dipScore += 1.0 + (ema200Strength * 0.05)
+		dipScore += w.BelowTrendEMA + (ema200Strength * w.BelowTrendEMABonus)
 		triggers = append(triggers, "BELOW_EMA200_WITH_MOMENTUM")
 	}
 
-	// Require a minimum weighted score for dip detection
-	if dipScore >= 6.0 { // High confidence dip
-		return true, triggers
-	}
-
-	return false, nil
+	return dipScore, triggers
 }
 
 // calculateTriggers calculates the relevant triggers for the current trend
@@ -559,7 +1062,7 @@ func (c *CoinbaseClient) calculateTriggers(indicators TechnicalIndicators, trend
 		if indicators.PriceDropPct12h < -5 {
 			triggers = append(triggers, "PRICE_TREND_REVERSAL")
 		}
-		if indicators.CurrentPrice < indicators.EMA200 && indicators.RSI < 45 {
+		if indicators.hasTrendEMA() && indicators.CurrentPrice < indicators.EMA200 && indicators.RSI < 45 {
 			triggers = append(triggers, "MAJOR_TREND_BREAKDOWN")
 		}
 		// Triangle pattern triggers
@@ -584,7 +1087,7 @@ func (c *CoinbaseClient) calculateTriggers(indicators TechnicalIndicators, trend
 		if indicators.PriceDropPct12h > 5 {
 			triggers = append(triggers, "PRICE_TREND_REVERSAL")
 		}
-		if indicators.CurrentPrice > indicators.EMA200 && indicators.RSI > 55 {
+		if indicators.hasTrendEMA() && indicators.CurrentPrice > indicators.EMA200 && indicators.RSI > 55 {
 			triggers = append(triggers, "MAJOR_TREND_BREAKOUT")
 		}
 		// Triangle pattern triggers
@@ -608,90 +1111,117 @@ func (c *CoinbaseClient) determineTrendState(indicators TechnicalIndicators) str
 
 	// Determine trend based on weighted scores
 	// Higher threshold for trend change to avoid false signals
-	if bearishScore >= 7.0 { // High confidence bearish
+	threshold := c.effectiveThreshold(indicators)
+	if bearishScore >= threshold { // High confidence bearish
 		return "bearish"
-	} else if bullishScore >= 7.0 { // High confidence bullish
+	} else if bullishScore >= threshold { // High confidence bullish
 		return "bullish"
 	} else {
 		return "neutral"
 	}
 }
 
+// effectiveThreshold returns the weighted score needed to call a trend. With ADAPTIVE_THRESHOLDS the fixed
+// threshold is scaled by the ratio of EWMA volatility to its baseline, clamped to the configured bounds, so calm
+// markets need a lower score and volatile markets a higher one.
+func (c *CoinbaseClient) effectiveThreshold(indicators TechnicalIndicators) float64 {
+	if !c.adaptiveThresholds || indicators.Volatility <= 0 || indicators.BaselineVolatility <= 0 {
+		return trendScoreThreshold
+	}
+	scale := indicators.Volatility / indicators.BaselineVolatility
+	scale = math.Max(c.adaptiveMinScale, math.Min(c.adaptiveMaxScale, scale))
+	return trendScoreThreshold * scale
+}
+
+// recommendationForTrend maps a trend state to an actionable recommendation: a bearish trend (weighted bearish
+// score at the threshold, or an immediate dip) is SELL, a bullish trend is BUY and anything else is HOLD
+func recommendationForTrend(trend string) string {
+	switch trend {
+	case "bearish":
+		return "SELL"
+	case "bullish":
+		return "BUY"
+	default:
+		return "HOLD"
+	}
+}
+
 // calculateBearishScore calculates a weighted score for bearish signals
 func (c *CoinbaseClient) calculateBearishScore(indicators TechnicalIndicators) float64 {
+	w := c.scoringWeights.Bearish
 	score := 0.0
 
-	// MACD bearish crossover (weight: 2.0 - very reliable)
+	// MACD bearish crossover (default weight: 2.0 - very reliable)
 	if indicators.MACD < indicators.SignalLine {
 		macdStrength := math.Abs(indicators.MACD - indicators.SignalLine)
 		if indicators.MACD < -0.1 {
-			score += 2.0 + (macdStrength * 10) // Bonus for strong bearish MACD
+			score += w.MACDStrong + (macdStrength * w.MACDStrengthBonus) // Bonus for strong bearish MACD
 		} else {
-			score += 1.5
+			score += w.MACDCrossover
 		}
 	}
 
-	// EMA bearish crossover (weight: 2.0 - very reliable)
+	// EMA bearish crossover (default weight: 2.0 - very reliable)
 	if indicators.EMA12 < indicators.EMA26 {
 		emaStrength := (indicators.EMA26 - indicators.EMA12) / indicators.EMA26 * 100
-		score += 2.0 + (emaStrength * 0.1) // Bonus for stronger crossover
+		score += w.EMACrossover + (emaStrength * w.EMAStrengthBonus) // Bonus for stronger crossover
 	}
 
-	// RSI oversold conditions (weight: 1.5 - momentum indicator)
+	// RSI oversold conditions (default weight: 1.5 - momentum indicator)
 	if indicators.RSI < 40 {
 		if indicators.RSI < 30 {
-			score += 2.0 // Strong oversold
+			score += w.RSIStrong // Strong oversold
 		} else {
-			score += 1.5 // Moderate oversold
+			score += w.RSIModerate // Moderate oversold
 		}
 	} else if indicators.RSI < 45 {
-		score += 0.5 // Slight bearish momentum
+		score += w.RSISlight // Slight bearish momentum
 	}
 
-	// Price drop percentage (weight: 1.5 - direct price action)
+	// Price drop percentage (default weight: 1.5 - direct price action)
 	if indicators.PriceDropPct12h < 0 {
 		dropStrength := math.Abs(indicators.PriceDropPct12h)
 		if dropStrength > 5 {
-			score += 2.0 // Strong drop
+			score += w.PriceChangeStrong // Strong drop
 		} else if dropStrength > 3 {
-			score += 1.5 // Moderate drop
+			score += w.PriceChangeModerate // Moderate drop
 		} else if dropStrength > 1 {
-			score += 0.5 // Slight drop
+			score += w.PriceChangeSlight // Slight drop
 		}
 	}
 
-	// Price vs EMA200 (weight: 1.0 - long-term trend)
-	if indicators.CurrentPrice < indicators.EMA200 {
+	// Price vs EMA200 (default weight: 1.0 - long-term trend)
+	if indicators.hasTrendEMA() && indicators.CurrentPrice < indicators.EMA200 {
 		ema200Strength := (indicators.EMA200 - indicators.CurrentPrice) / indicators.EMA200 * 100
 		if indicators.RSI < 40 {
-			score += 1.5 + (ema200Strength * 0.1) // Bonus for RSI confirmation
+			score += w.TrendEMAConfirmed + (ema200Strength * w.TrendEMAConfirmedBonus) // Bonus for RSI confirmation
 		} else {
-			score += 1.0 + (ema200Strength * 0.05)
+			score += w.TrendEMA + (ema200Strength * w.TrendEMABonus)
 		}
 	}
 
-	// ADX trend strength (weight: 1.0 - trend confirmation)
+	// ADX trend strength (default weight: 1.0 - trend confirmation)
 	if indicators.ADX > 25 {
 		if indicators.MACD < indicators.SignalLine {
-			score += 1.5 // Strong trend with bearish momentum
+			score += w.ADXWithMomentum // Strong trend with bearish momentum
 		} else {
-			score += 0.5 // Strong trend but no bearish momentum
+			score += w.ADXWithoutMomentum // Strong trend but no bearish momentum
 		}
 	}
 
-	// Volume spike confirmation (weight: 0.5 - volume confirmation)
+	// Volume spike confirmation (default weight: 0.5 - volume confirmation)
 	if indicators.VolumeSpike && indicators.PriceDropPct12h < -2 {
-		score += 0.5
+		score += w.VolumeSpike
 	}
 
-	// Triangle pattern analysis (weight: 1.5 - consolidation/breakout)
+	// Triangle pattern analysis (default weight: 1.5 - consolidation/breakout)
 	if indicators.TrianglePattern != "none" && indicators.TriangleStrength > 0.7 {
 		if indicators.TriangleBreakout == "bearish" {
-			score += 2.0 // Strong bearish breakout
+			score += w.TriangleBreakout // Strong bearish breakout
 		} else if indicators.TrianglePattern == "descending" {
-			score += 1.5 // Descending triangle (bearish pattern)
+			score += w.TrianglePattern // Descending triangle (bearish pattern)
 		} else if indicators.TrianglePattern == "symmetrical" && indicators.TriangleBreakout == "none" {
-			score += 0.5 // Consolidation (neutral)
+			score += w.TriangleConsolidation // Consolidation (neutral)
 		}
 	}
 
@@ -700,79 +1230,80 @@ func (c *CoinbaseClient) calculateBearishScore(indicators TechnicalIndicators) f
 
 // calculateBullishScore calculates a weighted score for bullish signals
 func (c *CoinbaseClient) calculateBullishScore(indicators TechnicalIndicators) float64 {
+	w := c.scoringWeights.Bullish
 	score := 0.0
 
-	// MACD bullish crossover (weight: 2.0 - very reliable)
+	// MACD bullish crossover (default weight: 2.0 - very reliable)
 	if indicators.MACD > indicators.SignalLine {
 		macdStrength := math.Abs(indicators.MACD - indicators.SignalLine)
 		if indicators.MACD > 0.1 {
-			score += 2.0 + (macdStrength * 10) // Bonus for strong bullish MACD
+			score += w.MACDStrong + (macdStrength * w.MACDStrengthBonus) // Bonus for strong bullish MACD
 		} else {
-			score += 1.5
+			score += w.MACDCrossover
 		}
 	}
 
-	// EMA bullish crossover (weight: 2.0 - very reliable)
+	// EMA bullish crossover (default weight: 2.0 - very reliable)
 	if indicators.EMA12 > indicators.EMA26 {
 		emaStrength := (indicators.EMA12 - indicators.EMA26) / indicators.EMA26 * 100
-		score += 2.0 + (emaStrength * 0.1) // Bonus for stronger crossover
+		score += w.EMACrossover + (emaStrength * w.EMAStrengthBonus) // Bonus for stronger crossover
 	}
 
-	// RSI overbought conditions (weight: 1.5 - momentum indicator)
+	// RSI overbought conditions (default weight: 1.5 - momentum indicator)
 	if indicators.RSI > 60 {
 		if indicators.RSI > 70 {
-			score += 2.0 // Strong overbought
+			score += w.RSIStrong // Strong overbought
 		} else {
-			score += 1.5 // Moderate overbought
+			score += w.RSIModerate // Moderate overbought
 		}
 	} else if indicators.RSI > 55 {
-		score += 0.5 // Slight bullish momentum
+		score += w.RSISlight // Slight bullish momentum
 	}
 
-	// Price increase percentage (weight: 1.5 - direct price action)
+	// Price increase percentage (default weight: 1.5 - direct price action)
 	if indicators.PriceDropPct12h > 0 {
 		gainStrength := indicators.PriceDropPct12h
 		if gainStrength > 5 {
-			score += 2.0 // Strong gain
+			score += w.PriceChangeStrong // Strong gain
 		} else if gainStrength > 3 {
-			score += 1.5 // Moderate gain
+			score += w.PriceChangeModerate // Moderate gain
 		} else if gainStrength > 1 {
-			score += 0.5 // Slight gain
+			score += w.PriceChangeSlight // Slight gain
 		}
 	}
 
-	// Price vs EMA200 (weight: 1.0 - long-term trend)
-	if indicators.CurrentPrice > indicators.EMA200 {
+	// Price vs EMA200 (default weight: 1.0 - long-term trend)
+	if indicators.hasTrendEMA() && indicators.CurrentPrice > indicators.EMA200 {
 		ema200Strength := (indicators.CurrentPrice - indicators.EMA200) / indicators.EMA200 * 100
 		if indicators.RSI > 60 {
-			score += 1.5 + (ema200Strength * 0.1) // Bonus for RSI confirmation
+			score += w.TrendEMAConfirmed + (ema200Strength * w.TrendEMAConfirmedBonus) // Bonus for RSI confirmation
 		} else {
-			score += 1.0 + (ema200Strength * 0.05)
+			score += w.TrendEMA + (ema200Strength * w.TrendEMABonus)
 		}
 	}
 
-	// ADX trend strength (weight: 1.0 - trend confirmation)
+	// ADX trend strength (default weight: 1.0 - trend confirmation)
 	if indicators.ADX > 25 {
 		if indicators.MACD > indicators.SignalLine {
-			score += 1.5 // Strong trend with bullish momentum
+			score += w.ADXWithMomentum // Strong trend with bullish momentum
 		} else {
-			score += 0.5 // Strong trend but no bullish momentum
+			score += w.ADXWithoutMomentum // Strong trend but no bullish momentum
 		}
 	}
 
-	// Volume spike confirmation (weight: 0.5 - volume confirmation)
+	// Volume spike confirmation (default weight: 0.5 - volume confirmation)
 	if indicators.VolumeSpike && indicators.PriceDropPct12h > 2 {
-		score += 0.5
+		score += w.VolumeSpike
 	}
 
-	// Triangle pattern analysis (weight: 1.5 - consolidation/breakout)
+	// Triangle pattern analysis (default weight: 1.5 - consolidation/breakout)
 	if indicators.TrianglePattern != "none" && indicators.TriangleStrength > 0.7 {
 		if indicators.TriangleBreakout == "bullish" {
-			score += 2.0 // Strong bullish breakout
+			score += w.TriangleBreakout // Strong bullish breakout
 		} else if indicators.TrianglePattern == "ascending" {
-			score += 1.5 // Ascending triangle (bullish pattern)
+			score += w.TrianglePattern // Ascending triangle (bullish pattern)
 		} else if indicators.TrianglePattern == "symmetrical" && indicators.TriangleBreakout == "none" {
-			score += 0.5 // Consolidation (neutral)
+			score += w.TriangleConsolidation // Consolidation (neutral)
 		}
 	}
 
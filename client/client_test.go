@@ -0,0 +1,414 @@
+package client
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// discardLogger is a logger for clients built directly in tests
+func discardLogger() *log.Logger {
+	return log.New(io.Discard, "", 0)
+}
+
+func TestCalculatePriceZScore(t *testing.T) {
+	prices := wavyCloses(200)
+	spiked := append(append([]float64(nil), prices[:len(prices)-1]...), prices[len(prices)-1]*1.1)
+
+	if z := calculatePriceZScore(spiked, 26, anomalyResidualWindow); z < 5 {
+		t.Errorf("z-score of a 10%% spike = %.2f, want above 5", z)
+	}
+	if z := calculatePriceZScore(prices[:26+anomalyResidualWindow], 26, anomalyResidualWindow); z != 0 {
+		t.Errorf("z-score without enough residuals = %.2f, want 0", z)
+	}
+}
+
+func TestDetectPriceAnomaly(t *testing.T) {
+	c := &CoinbaseClient{
+		logger:                 discardLogger(),
+		anomalyZScoreThreshold: 3,
+		trendChangeCooldown:    time.Minute,
+	}
+
+	tests := []struct {
+		name    string
+		zScore  float64
+		anomaly bool
+		alert   bool
+	}{
+		{"below threshold", 2.9, false, false},
+		{"first spike alerts", 4, true, true},
+		{"downward spike within cooldown", -4, true, false},
+		{"back below threshold", -1, false, false},
+	}
+	for _, tt := range tests {
+		anomaly, alert := c.detectPriceAnomaly(TechnicalIndicators{PriceZScore: tt.zScore})
+		if anomaly != tt.anomaly || alert != tt.alert {
+			t.Errorf("%s: detectPriceAnomaly(%v) = %v, %v, want %v, %v", tt.name, tt.zScore, anomaly, alert, tt.anomaly, tt.alert)
+		}
+	}
+
+	c.trendMutex.Lock()
+	c.lastAnomalyTime = time.Now().Add(-time.Minute)
+	c.trendMutex.Unlock()
+	if _, alert := c.detectPriceAnomaly(TechnicalIndicators{PriceZScore: 4}); !alert {
+		t.Error("spike after the cooldown did not alert")
+	}
+}
+
+func TestDetectPriceAnomalyAlertsOnceUnderConcurrency(t *testing.T) {
+	c := &CoinbaseClient{
+		logger:                 discardLogger(),
+		anomalyZScoreThreshold: 3,
+		trendChangeCooldown:    time.Minute,
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	alerts := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, alert := c.detectPriceAnomaly(TechnicalIndicators{PriceZScore: 5}); alert {
+				mutex.Lock()
+				alerts++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if alerts != 1 {
+		t.Errorf("alerts = %d, want 1", alerts)
+	}
+}
+
+func TestTrackAssetValueKeepsDeprecatedTotalUSD(t *testing.T) {
+	c := newTestClient(t, newFakeCoinbase())
+	if err := c.TrackAssetValue(); err != nil {
+		t.Fatalf("TrackAssetValue: %v", err)
+	}
+
+	history := c.GetAssetValueHistory()
+	if len(history) != 1 {
+		t.Fatalf("history = %v, want one point", history)
+	}
+	data, err := json.Marshal(history[0])
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	// 1 BTC at the 50000 bid plus 10000 USDC
+	if fields["total_quote"] != 60000.0 || fields["total_usd"] != 60000.0 {
+		t.Errorf("total_quote = %v, total_usd = %v, want both 60000", fields["total_quote"], fields["total_usd"])
+	}
+}
+
+func TestRapidAssetValueCallsRecordOnePoint(t *testing.T) {
+	fake := newFakeCoinbase()
+	c := newTestClient(t, fake)
+	c.assetSampleEvery = time.Minute
+
+	if err := c.TrackAssetValue(); err != nil {
+		t.Fatalf("first TrackAssetValue: %v", err)
+	}
+	accountCalls := fake.called("GET /accounts")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.TrackAssetValue(); !errors.Is(err, ErrAssetSampleSkipped) {
+				t.Errorf("TrackAssetValue within the interval = %v, want ErrAssetSampleSkipped", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := len(c.GetAssetValueHistory()); n != 1 {
+		t.Errorf("history has %d points after rapid calls, want 1", n)
+	}
+	if calls := fake.called("GET /accounts"); calls != accountCalls {
+		t.Errorf("skipped samples fetched the accounts %d times", calls-accountCalls)
+	}
+
+	// Startup and other forced samples ignore the interval
+	if err := c.ForceTrackAssetValue(); err != nil {
+		t.Fatalf("ForceTrackAssetValue: %v", err)
+	}
+	if n := len(c.GetAssetValueHistory()); n != 2 {
+		t.Errorf("history has %d points after a forced sample, want 2", n)
+	}
+}
+
+func TestRecommendationForEachTrend(t *testing.T) {
+	flat := make([]float64, 300)
+	for i := range flat {
+		flat[i] = 100
+	}
+	tests := []struct {
+		trend          string
+		closes         []float64
+		recommendation string
+	}{
+		{"bearish", decliningCloses(300), "SELL"},
+		{"bullish", risingCloses(300), "BUY"},
+		{"neutral", flat, "HOLD"},
+	}
+	for _, tt := range tests {
+		if got := recommendationForTrend(tt.trend); got != tt.recommendation {
+			t.Errorf("recommendationForTrend(%s) = %s, want %s", tt.trend, got, tt.recommendation)
+		}
+
+		fake := newFakeCoinbase()
+		fake.candles = candlesFromCloses(tt.closes)
+		c := newTestClient(t, fake)
+		c.closedCandlesOnly = false
+		signal, err := c.GetSignalWithCandles(len(tt.closes), "FIVE_MINUTE")
+		if err != nil {
+			t.Fatalf("%s candles: %v", tt.trend, err)
+		}
+		if signal.Recommendation != tt.recommendation || signal.BearishSignal != (tt.trend == "bearish") {
+			t.Errorf("%s candles: recommendation %s, bearish %v, want %s", tt.trend, signal.Recommendation, signal.BearishSignal, tt.recommendation)
+		}
+	}
+}
+
+func TestAssetHistoryRetention(t *testing.T) {
+	now := time.Now()
+	samplesAt := func(ages ...time.Duration) []AccountValue {
+		samples := make([]AccountValue, len(ages))
+		for i, age := range ages {
+			samples[i] = AccountValue{Timestamp: now.Add(-age).Unix(), TotalValue: float64(i)}
+		}
+		return samples
+	}
+
+	tests := []struct {
+		name       string
+		max        int
+		maxAge     time.Duration
+		history    []AccountValue
+		wantValues []float64 // TotalValue of the kept preloaded samples, oldest first
+	}{
+		{"count cap", 3, 0, samplesAt(5*time.Minute, 4*time.Minute, 3*time.Minute, 2*time.Minute, time.Minute), []float64{3, 4}},
+		{"age cap", 100, time.Hour, samplesAt(3*time.Hour, 2*time.Hour, 30*time.Minute, time.Minute), []float64{2, 3}},
+		{"count cap within the age cap", 2, time.Hour, samplesAt(2*time.Hour, 50*time.Minute, 40*time.Minute, 30*time.Minute), []float64{3}},
+		{"nothing to evict", 10, time.Hour, samplesAt(30*time.Minute, time.Minute), []float64{0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, newFakeCoinbase())
+			c.assetHistoryMax = tt.max
+			c.assetHistoryMaxAge = tt.maxAge
+			c.assetValueHistory = tt.history
+
+			if err := c.TrackAssetValue(); err != nil {
+				t.Fatalf("TrackAssetValue: %v", err)
+			}
+			history := c.GetAssetValueHistory()
+			if len(history) != len(tt.wantValues)+1 {
+				t.Fatalf("kept %d samples, want %d plus the new one", len(history), len(tt.wantValues))
+			}
+			for i, want := range tt.wantValues {
+				if history[i].TotalValue != want {
+					t.Errorf("sample %d is preloaded sample %v, want %v", i, history[i].TotalValue, want)
+				}
+			}
+			if newest := history[len(history)-1]; newest.TotalValue != 60000 {
+				t.Errorf("newest sample = %+v, want the one just tracked", newest)
+			}
+		})
+	}
+}
+
+// testKeyPEM returns a throwaway EC private key and its PEM encoding
+func testKeyPEM(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+func TestPrivateKeySources(t *testing.T) {
+	inlineKey, inlinePEM := testKeyPEM(t)
+	fileKey, filePEM := testKeyPEM(t)
+	dir := t.TempDir()
+	writeSecret := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+		return path
+	}
+	// A mounted secret often ends with a newline; a bare base64 body gets its PEM headers back
+	mounted := writeSecret("mounted.pem", "\n"+filePEM+"\n\n")
+	lines := strings.Split(strings.TrimSpace(filePEM), "\n")
+	bare := writeSecret("bare.pem", strings.Join(lines[1:len(lines)-1], "\n")+"\n")
+
+	tests := []struct {
+		name       string
+		inline     string
+		file       string
+		want       *ecdsa.PrivateKey
+		errMention string
+	}{
+		{"inline only", inlinePEM, "", inlineKey, ""},
+		{"file only", "", mounted, fileKey, ""},
+		{"file over inline", inlinePEM, mounted, fileKey, ""},
+		{"file without PEM headers", "", bare, fileKey, ""},
+		{"unreadable file", inlinePEM, filepath.Join(dir, "missing.pem"), nil, "COINBASE_API_SECRET_FILE"},
+		{"empty file", "", writeSecret("empty.pem", "\n"), nil, "missing required environment variables"},
+		{"neither", "", "", nil, "missing required environment variables"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("COINBASE_API_KEY", "test-key")
+			t.Setenv("COINBASE_API_SECRET", tt.inline)
+			t.Setenv("COINBASE_API_SECRET_FILE", tt.file)
+			t.Setenv("LOG_LEVEL", "ERROR")
+
+			c, err := NewCoinbaseClient("BTC-USDC", "", 0, 0)
+			if tt.want == nil {
+				if err == nil || !strings.Contains(err.Error(), tt.errMention) {
+					t.Errorf("NewCoinbaseClient = %v, want an error mentioning %q", err, tt.errMention)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewCoinbaseClient: %v", err)
+			}
+			if !c.privateKey.Equal(tt.want) {
+				t.Error("loaded the key from the wrong source")
+			}
+		})
+	}
+}
+
+func TestIdleConnTimeoutValidation(t *testing.T) {
+	_, keyPEM := testKeyPEM(t)
+	tests := []struct {
+		value string
+		want  time.Duration
+		valid bool
+	}{
+		{"", 90 * time.Second, true},
+		{"30s", 30 * time.Second, true},
+		{"0", 0, true},
+		{"-1s", 0, false},
+		{"90", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("COINBASE_API_KEY", "test-key")
+			t.Setenv("COINBASE_API_SECRET", keyPEM)
+			t.Setenv("LOG_LEVEL", "ERROR")
+			t.Setenv("HTTP_IDLE_CONN_TIMEOUT", tt.value)
+
+			c, err := NewCoinbaseClient("BTC-USDC", "", 0, 0)
+			if !tt.valid {
+				if err == nil || !strings.Contains(err.Error(), "HTTP_IDLE_CONN_TIMEOUT") {
+					t.Errorf("NewCoinbaseClient = %v, want an error mentioning HTTP_IDLE_CONN_TIMEOUT", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewCoinbaseClient: %v", err)
+			}
+			if got := c.httpClient.Transport.(*http.Transport).IdleConnTimeout; got != tt.want {
+				t.Errorf("IdleConnTimeout = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackAssetValueWithAnEmptyBook(t *testing.T) {
+	fake := newFakeCoinbase()
+	fake.emptyBook = true
+	c := newTestClient(t, fake)
+
+	// Valued at the product's last price instead of failing
+	if err := c.TrackAssetValue(); err != nil {
+		t.Fatalf("TrackAssetValue with an empty book: %v", err)
+	}
+	history := c.GetAssetValueHistory()
+	if len(history) != 1 || history[0].TotalQuote != 60000 {
+		t.Errorf("history = %+v, want 1 BTC at the 50000 last price plus 10000 USDC", history)
+	}
+	if fake.called("GET /products/BTC-USDC") == 0 {
+		t.Error("the product's last price was not looked up")
+	}
+
+	// Without a last price either there is nothing to value the assets at
+	fake.mutex.Lock()
+	fake.bid = ""
+	fake.mutex.Unlock()
+	c.InvalidateMarketCache()
+	if err := c.ForceTrackAssetValue(); err == nil || !strings.Contains(err.Error(), "no current price available") {
+		t.Errorf("TrackAssetValue without any price = %v, want no current price available", err)
+	}
+}
+
+func TestChartLocationFromEnv(t *testing.T) {
+	tests := []struct {
+		name, chartTimezone, tz string
+		want                    string
+		wantErr, wantWarning    bool
+	}{
+		{"default", "", "", "UTC", false, false},
+		{"chart timezone", "Europe/Brussels", "", "Europe/Brussels", false, false},
+		{"chart timezone over TZ", "Asia/Tokyo", "Europe/Brussels", "Asia/Tokyo", false, false},
+		{"TZ name", "", "Europe/Brussels", "Europe/Brussels", false, false},
+		{"POSIX TZ", "", "UTC0", "UTC", false, true},
+		{"TZ file", "", ":/etc/localtime", "UTC", false, true},
+		{"invalid chart timezone", "Mars/Olympus", "", "", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CHART_TIMEZONE", tt.chartTimezone)
+			t.Setenv("TZ", tt.tz)
+			var logged bytes.Buffer
+
+			location, err := chartLocationFromEnv(log.New(&logged, "", 0))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("location = %v, want an error", location)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("chartLocationFromEnv: %v", err)
+			}
+			if location.String() != tt.want {
+				t.Errorf("location = %v, want %s", location, tt.want)
+			}
+			if warned := strings.Contains(logged.String(), "[WARN]"); warned != tt.wantWarning {
+				t.Errorf("warning logged = %v, want %v (log: %q)", warned, tt.wantWarning, logged.String())
+			}
+		})
+	}
+}
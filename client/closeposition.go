@@ -0,0 +1,147 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// defaultCloseSlippagePct is how far below the best bid a close-position sell is priced, so the IOC order
+// still fills when the book moves between reading it and the order arriving
+const defaultCloseSlippagePct = 0.5
+
+// ClosePosition goes flat: it cancels every open order, which also releases the balance they held, then
+// sells the whole available base balance with an IOC limit order slippagePct below the best bid (zero uses
+// the default). With dryRun or DRY_RUN nothing is cancelled or placed and only the planned sell is returned.
+// The usual order guards (MAX_ORDER_NOTIONAL_USD, MAX_SPREAD_BPS) are checked on the planned sell (available
+// plus held balance) before anything is cancelled, so a close they reject leaves the open orders in place.
+// When the sell fails after the cancellations, the result is returned with the error so callers can report
+// what was cancelled.
+func (c *CoinbaseClient) ClosePosition(slippagePct float64, dryRun bool) (*ClosePositionResult, error) {
+	if slippagePct < 0 || slippagePct >= 100 {
+		return nil, fmt.Errorf("slippage_pct must be between 0 and 100")
+	}
+	if slippagePct == 0 {
+		slippagePct = defaultCloseSlippagePct
+	}
+	dryRun = dryRun || c.dryRun
+	if !dryRun {
+		done, err := c.beginTrade()
+		if err != nil {
+			return nil, err
+		}
+		defer done()
+	}
+
+	base, _, err := c.pairCurrencies()
+	if err != nil {
+		return nil, err
+	}
+
+	// Plan the sell of everything, including the balance open orders hold, and check it against the guards
+	accounts, err := c.GetAccounts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch accounts: %w", err)
+	}
+	available, held := baseBalances(accounts, base)
+	plannedSize := floorToIncrement(available.Add(held), c.baseIncrement())
+	if plannedSize.IsPositive() {
+		_, price, err := c.closePrice(slippagePct)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.checkOrderNotional("SELL", plannedSize, price); err != nil {
+			return nil, err
+		}
+		if err := c.checkSpread("SELL"); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &ClosePositionResult{DryRun: dryRun}
+
+	if !dryRun {
+		cancellation, err := c.CancelAllOrders()
+		if err != nil {
+			return nil, fmt.Errorf("failed to cancel open orders: %w", err)
+		}
+		result.Cancellation = cancellation
+		result.CancelledCount = len(cancellation.Cancelled)
+		if len(cancellation.Failed) > 0 || len(cancellation.Rejected) > 0 {
+			c.logger.Printf("[WARN] Close position: %d orders could not be cancelled, their balance stays on hold",
+				len(cancellation.Failed)+len(cancellation.Rejected))
+		}
+
+		// Read the balance after cancelling so released holds are included
+		accounts, err = c.GetAccounts()
+		if err != nil {
+			return result, fmt.Errorf("failed to fetch accounts: %w", err)
+		}
+		available, _ = baseBalances(accounts, base)
+	}
+
+	// A dry run sells what cancelling would release too
+	size := floorToIncrement(available, c.baseIncrement())
+	if dryRun {
+		size = plannedSize
+	}
+	if !size.IsPositive() {
+		result.NoOp = true
+		result.Reason = fmt.Sprintf("no %s balance to sell", base)
+		return result, nil
+	}
+
+	bestBid, price, err := c.closePrice(slippagePct)
+	if err != nil {
+		return result, err
+	}
+
+	result.Size = size.String()
+	result.Price = Money{price}
+	result.Value = Money{size.Mul(price)}
+
+	if dryRun {
+		result.Reason = "dry run, no orders cancelled or placed"
+		c.logger.Printf("Close position (dry run): SELL %s %s @ %s (best bid %s - %.2f%%)",
+			result.Size, base, price.String(), bestBid.String(), slippagePct)
+		return result, nil
+	}
+
+	order, err := c.createOrder("SELL", result.Size, result.Price.InexactFloat64(), OrderOptions{ImmediateOrCancel: true})
+	if err != nil {
+		return result, fmt.Errorf("failed to place close-position sell: %w", err)
+	}
+	result.SellOrder = order
+
+	c.logger.Printf("Close Position Result: cancelled %d orders, %s", result.CancelledCount, c.GetOrderResult(order))
+	return result, nil
+}
+
+// baseBalances returns the available and held balance of the base currency
+func baseBalances(accounts []Account, base string) (available, held decimal.Decimal) {
+	for _, account := range accounts {
+		if account.Currency == base {
+			available = parseDecimal(account.AvailableBalance)
+			held = parseDecimal(account.Hold)
+		}
+	}
+	return available, held
+}
+
+// closePrice returns the best bid and the close-position sell price slippagePct below it
+func (c *CoinbaseClient) closePrice(slippagePct float64) (bestBid, price decimal.Decimal, err error) {
+	orderBook, err := c.GetOrderBook(1)
+	if err != nil {
+		return bestBid, price, fmt.Errorf("failed to get order book: %w", err)
+	}
+	if len(orderBook.Bids) == 0 {
+		return bestBid, price, fmt.Errorf("order book has no bids, cannot price the sell")
+	}
+	bestBid = parseDecimal(orderBook.Bids[0].Price)
+	if !bestBid.IsPositive() {
+		return bestBid, price, fmt.Errorf("invalid best bid: %s", orderBook.Bids[0].Price)
+	}
+
+	factor := decimal.NewFromInt(1).Sub(decimal.NewFromFloat(slippagePct).Div(decimal.NewFromInt(100)))
+	return bestBid, floorToIncrement(bestBid.Mul(factor), c.quoteIncrement()), nil
+}
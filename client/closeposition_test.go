@@ -0,0 +1,87 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestClosePositionChecksGuardsBeforeCancelling(t *testing.T) {
+	tests := []struct {
+		name            string
+		dryRun          bool
+		available, hold string
+		bid, ask        string
+		maxNotional     float64
+		maxSpreadBps    float64
+		wantErr         error
+	}{
+		{name: "over the notional cap", available: "1", bid: "50000", ask: "50010", maxNotional: 1000, wantErr: ErrOrderNotionalExceeded},
+		{name: "over the cap dry run", dryRun: true, available: "1", bid: "50000", ask: "50010", maxNotional: 1000, wantErr: ErrOrderNotionalExceeded},
+		{name: "held balance counts toward the cap", available: "0.4", hold: "0.6", bid: "50000", ask: "50010", maxNotional: 30000, wantErr: ErrOrderNotionalExceeded},
+		{name: "spread too wide", available: "1", bid: "50000", ask: "50100", maxSpreadBps: 10, wantErr: ErrSpreadTooWide},
+		{name: "spread too wide dry run", dryRun: true, available: "1", bid: "50000", ask: "50100", maxSpreadBps: 10, wantErr: ErrSpreadTooWide},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeCoinbase()
+			fake.baseAvailable, fake.baseHold, fake.bid, fake.ask = tt.available, tt.hold, tt.bid, tt.ask
+			fake.openOrders = []string{"open-1"}
+			c := newTestClient(t, fake)
+			c.maxOrderNotional = decimal.NewFromFloat(tt.maxNotional)
+			c.maxSpreadBps = tt.maxSpreadBps
+
+			_, err := c.ClosePosition(0, tt.dryRun)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if n := fake.called("GET /orders/historical/batch") + fake.called("POST /orders/batch_cancel"); n != 0 {
+				t.Errorf("made %d order list/cancel requests, want none before the guards pass", n)
+			}
+			if len(fake.orders) != 0 {
+				t.Errorf("placed %d orders, want none", len(fake.orders))
+			}
+		})
+	}
+}
+
+func TestClosePositionDryRunPlansAvailableAndHeld(t *testing.T) {
+	fake := newFakeCoinbase()
+	fake.baseAvailable, fake.baseHold = "0.4", "0.6"
+	fake.openOrders = []string{"open-1"}
+	c := newTestClient(t, fake)
+
+	result, err := c.ClosePosition(1, true)
+	if err != nil {
+		t.Fatalf("ClosePosition: %v", err)
+	}
+	if !result.DryRun || result.Size != "1" || result.Price.String() != "49500" {
+		t.Errorf("plan = %+v, want a dry run selling 1 BTC at 49500", result)
+	}
+	if n := fake.called("POST /orders/batch_cancel"); n != 0 || len(fake.orders) != 0 {
+		t.Errorf("dry run cancelled (%d requests) or placed (%d) orders", n, len(fake.orders))
+	}
+}
+
+func TestClosePositionCancelsThenSells(t *testing.T) {
+	fake := newFakeCoinbase()
+	fake.openOrders = []string{"open-1", "open-2"}
+	c := newTestClient(t, fake)
+	c.maxOrderNotional = decimal.NewFromInt(100000)
+	c.maxSpreadBps = 10
+
+	result, err := c.ClosePosition(0, false)
+	if err != nil {
+		t.Fatalf("ClosePosition: %v", err)
+	}
+	if result.CancelledCount != 2 || result.SellOrder == nil {
+		t.Fatalf("result = %+v, want 2 cancelled orders and a sell", result)
+	}
+	if len(fake.orders) != 1 || fake.orders[0].Side != "SELL" || fake.orders[0].OrderConfiguration.SorLimitIoc == nil {
+		t.Fatalf("orders = %+v, want one IOC sell", fake.orders)
+	}
+	if size := fake.orders[0].OrderConfiguration.SorLimitIoc.BaseSize; size != "1" {
+		t.Errorf("sell size = %s, want 1", size)
+	}
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultConfirmGranularity is the higher timeframe used by MULTI_TIMEFRAME_CONFIRM
+const defaultConfirmGranularity = "ONE_HOUR"
+
+// validateConfirmGranularity checks that the confirmation timeframe is supported and longer than the signal's
+func validateConfirmGranularity(confirmGranularity, signalGranularity string) error {
+	confirmInterval, err := granularityDuration(confirmGranularity)
+	if err != nil {
+		return err
+	}
+	signalInterval, err := granularityDuration(signalGranularity)
+	if err != nil {
+		return err
+	}
+	if confirmInterval <= signalInterval {
+		return fmt.Errorf("%s is not a higher timeframe than the signal granularity %s", confirmGranularity, signalGranularity)
+	}
+	return nil
+}
+
+// trendBias returns the direction weighted scores lean to, without requiring the trend threshold: a higher
+// timeframe moves slowly and rarely scores a full trend, but it should at least not point the other way
+func trendBias(bearishScore, bullishScore float64) string {
+	switch {
+	case bearishScore > bullishScore:
+		return "bearish"
+	case bullishScore > bearishScore:
+		return "bullish"
+	default:
+		return "neutral"
+	}
+}
+
+// higherTimeframeBias fetches candles at MULTI_TIMEFRAME_GRANULARITY and returns the direction their
+// indicators lean to. The fetch bypasses the signal candle cache, which holds the signal granularity.
+func (c *CoinbaseClient) higherTimeframeBias() (string, error) {
+	candles, err := c.GetCandles("", "", c.confirmGranularity, c.indicatorPeriods.minSignalCandles())
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s candles: %w", c.confirmGranularity, err)
+	}
+	if c.closedCandlesOnly {
+		candles = dropFormingCandle(candles, c.confirmGranularity, time.Now())
+	}
+	if len(candles) < minIndicatorCandles {
+		return "", fmt.Errorf("only %d %s candles available", len(candles), c.confirmGranularity)
+	}
+
+	// Confirmation isn't latency bound, so take the deterministic path
+	indicators := calculateTechnicalIndicatorsSequential(candles, c.indicatorPeriods)
+	return trendBias(c.calculateBearishScore(indicators), c.calculateBullishScore(indicators)), nil
+}
+
+// confirmationBias returns the higher timeframe bias detectTrendChange confirms signals against. It is read
+// before trendMutex is taken so the fetch doesn't hold up concurrent signal checks, and only when there is a
+// trend or dip to confirm. An empty bias means confirmation is off or the higher timeframe can't be read.
+func (c *CoinbaseClient) confirmationBias(indicators TechnicalIndicators) string {
+	if !c.multiTimeframeConfirm {
+		return ""
+	}
+	if c.determineTrendState(indicators) == "neutral" {
+		if dip, _ := c.detectImmediateDip(indicators); !dip {
+			return ""
+		}
+	}
+
+	bias, err := c.higherTimeframeBias()
+	if err != nil {
+		c.errorLog.Printf("confirm-trend", "[WARN] Could not read the %s trend to confirm signals: %v", c.confirmGranularity, err)
+		return ""
+	}
+	c.errorLog.Clear("confirm-trend")
+	return bias
+}
+
+// confirmTrend reports whether a bearish or bullish signal may be emitted. With MULTI_TIMEFRAME_CONFIRM the
+// higher timeframe bias must lean the same way; neutral signals always pass. If the higher timeframe couldn't
+// be read (empty bias), signals are not held back.
+func (c *CoinbaseClient) confirmTrend(trend, bias string) bool {
+	if !c.multiTimeframeConfirm || (trend != "bearish" && trend != "bullish") || bias == "" {
+		return true
+	}
+	if bias != trend {
+		c.logger.Printf("🔇 %s signal not confirmed: %s trend leans %s", trend, c.confirmGranularity, bias)
+		return false
+	}
+	if c.debug {
+		c.logger.Printf("✅ %s signal confirmed on %s", trend, c.confirmGranularity)
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+package client
+
+import "testing"
+
+func TestTrendBias(t *testing.T) {
+	tests := []struct {
+		bearish, bullish float64
+		want             string
+	}{
+		{3, 1, "bearish"},
+		{1, 3, "bullish"},
+		{2, 2, "neutral"},
+		{0, 0, "neutral"},
+	}
+	for _, tt := range tests {
+		if got := trendBias(tt.bearish, tt.bullish); got != tt.want {
+			t.Errorf("trendBias(%v, %v) = %s, want %s", tt.bearish, tt.bullish, got, tt.want)
+		}
+	}
+}
+
+// risingCloses mirrors decliningCloses: the wavy uptrend accelerating over its last quarter
+func risingCloses(n int) []float64 {
+	closes := wavyCloses(n)
+	bottom := closes[n*3/4]
+	for i := n * 3 / 4; i < n; i++ {
+		closes[i] = bottom * (1 + 0.002*float64(i-n*3/4))
+	}
+	return closes
+}
+
+func TestConfirmTrend(t *testing.T) {
+	fake := newFakeCoinbase()
+	c := newTestClient(t, fake)
+	c.multiTimeframeConfirm = true
+	c.confirmGranularity = "ONE_HOUR"
+	c.closedCandlesOnly = false
+
+	// The candle sets must lean the way the cases below assume
+	lean := func(candles []Candle) string {
+		indicators := calculateTechnicalIndicatorsSequential(candles, c.indicatorPeriods)
+		return trendBias(c.calculateBearishScore(indicators), c.calculateBullishScore(indicators))
+	}
+	declining := candlesFromCloses(decliningCloses(300))
+	rising := candlesFromCloses(risingCloses(300))
+	if lean(declining) != "bearish" || lean(rising) != "bullish" {
+		t.Fatalf("declining candles lean %s and rising ones %s, want bearish and bullish", lean(declining), lean(rising))
+	}
+
+	tests := []struct {
+		name      string
+		candles   []Candle
+		trend     string
+		confirmed bool
+	}{
+		{"bearish agrees", declining, "bearish", true},
+		{"bullish disagrees with a bearish higher timeframe", declining, "bullish", false},
+		{"bullish agrees", rising, "bullish", true},
+		{"bearish disagrees with a bullish higher timeframe", rising, "bearish", false},
+		{"neutral always passes", declining, "neutral", true},
+		{"unreadable higher timeframe doesn't hold signals back", nil, "bearish", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake.mutex.Lock()
+			fake.candles = tt.candles
+			fake.mutex.Unlock()
+			bias, err := c.higherTimeframeBias()
+			if (err != nil) != (tt.candles == nil) {
+				t.Fatalf("higherTimeframeBias() error = %v", err)
+			}
+			if got := c.confirmTrend(tt.trend, bias); got != tt.confirmed {
+				t.Errorf("confirmTrend(%s, %q) = %v, want %v", tt.trend, bias, got, tt.confirmed)
+			}
+		})
+	}
+}
+
+func TestConfirmationBias(t *testing.T) {
+	fake := newFakeCoinbase()
+	fake.candles = candlesFromCloses(decliningCloses(300))
+	c := newTestClient(t, fake)
+	c.multiTimeframeConfirm = true
+	c.confirmGranularity = "ONE_HOUR"
+	c.closedCandlesOnly = false
+
+	neutral := TechnicalIndicators{CurrentPrice: 100, RSI: 50}
+	dip := TechnicalIndicators{CurrentPrice: 100, RSI: 20, PriceDropPct12h: -8, MACD: -1, EMA12: 99, EMA26: 100}
+	if c.determineTrendState(neutral) != "neutral" {
+		t.Fatalf("neutral indicators score %s", c.determineTrendState(neutral))
+	}
+	if detected, _ := c.detectImmediateDip(dip); !detected {
+		t.Fatal("dip indicators don't score a dip")
+	}
+
+	// Nothing to confirm: the higher timeframe isn't fetched
+	if bias := c.confirmationBias(neutral); bias != "" || fake.called("GET /products/BTC-USDC/candles") != 0 {
+		t.Errorf("neutral indicators: bias %q after %d fetches, want none", bias, fake.called("GET /products/BTC-USDC/candles"))
+	}
+	if bias := c.confirmationBias(dip); bias != "bearish" {
+		t.Errorf("dip bias = %q, want bearish", bias)
+	}
+
+	// Without MULTI_TIMEFRAME_CONFIRM nothing is fetched and every trend passes
+	c.multiTimeframeConfirm = false
+	calls := fake.called("GET /products/BTC-USDC/candles")
+	if c.confirmationBias(dip) != "" || fake.called("GET /products/BTC-USDC/candles") != calls || !c.confirmTrend("bullish", "bearish") {
+		t.Error("confirmation without MULTI_TIMEFRAME_CONFIRM fetched or held a signal back")
+	}
+}
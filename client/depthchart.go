@@ -0,0 +1,139 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"sort"
+	"strconv"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+// ErrEmptyOrderBook is returned by GenerateDepthChartPNG when the order book has no usable bids or asks
+var ErrEmptyOrderBook = errors.New("order book is empty")
+
+// depthLevel is one order book price level with the size available at it or better
+type depthLevel struct {
+	price      float64
+	cumulative float64
+}
+
+// cumulativeDepth parses order book entries and accumulates their sizes from the best price outwards:
+// bids from the highest price down, asks from the lowest up. Entries that don't parse are skipped.
+// Levels are returned in ascending price order, ready to plot.
+func cumulativeDepth(entries []OrderBookEntry, bids bool) []depthLevel {
+	levels := make([]depthLevel, 0, len(entries))
+	for _, entry := range entries {
+		price, errPrice := strconv.ParseFloat(entry.Price, 64)
+		size, errSize := strconv.ParseFloat(entry.Size, 64)
+		if errPrice != nil || errSize != nil || price <= 0 || size <= 0 {
+			continue
+		}
+		levels = append(levels, depthLevel{price: price, cumulative: size})
+	}
+
+	// Best price first
+	sort.Slice(levels, func(i, j int) bool {
+		if bids {
+			return levels[i].price > levels[j].price
+		}
+		return levels[i].price < levels[j].price
+	})
+	for i := 1; i < len(levels); i++ {
+		levels[i].cumulative += levels[i-1].cumulative
+	}
+
+	if bids {
+		for i, j := 0, len(levels)-1; i < j; i, j = i+1, j-1 {
+			levels[i], levels[j] = levels[j], levels[i]
+		}
+	}
+	return levels
+}
+
+// depthLine plots cumulative depth as a filled step line
+func depthLine(levels []depthLevel, step plotter.StepKind, lineColor, fillColor color.Color) (*plotter.Line, error) {
+	points := make(plotter.XYs, len(levels))
+	for i, level := range levels {
+		points[i] = plotter.XY{X: level.price, Y: level.cumulative}
+	}
+	line, err := plotter.NewLine(points)
+	if err != nil {
+		return nil, err
+	}
+	line.StepStyle = step
+	line.Color = lineColor
+	line.FillColor = fillColor
+	line.Width = vg.Points(2)
+	return line, nil
+}
+
+// GenerateDepthChartPNG renders the cumulative order book depth as a PNG: bids stepping down to the left of
+// the mid price in green, asks stepping up to the right in red. Returns ErrEmptyOrderBook when neither side
+// has a usable level, rather than an empty image.
+func (c *CoinbaseClient) GenerateDepthChartPNG(orderBook *OrderBook) ([]byte, error) {
+	bids := cumulativeDepth(orderBook.Bids, true)
+	asks := cumulativeDepth(orderBook.Asks, false)
+	if len(bids) == 0 && len(asks) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrEmptyOrderBook, c.tradingPair)
+	}
+
+	base, quote, err := c.pairCurrencies()
+	if err != nil {
+		return nil, err
+	}
+
+	depthChart := plot.New()
+	depthChart.Title.Text = fmt.Sprintf("%s Order Book Depth", c.tradingPair)
+	depthChart.X.Label.Text = fmt.Sprintf("Price (%s)", quote)
+	depthChart.Y.Label.Text = fmt.Sprintf("Cumulative Size (%s)", base)
+	depthChart.Y.Min = 0
+	depthChart.Legend.Top = true
+
+	// Bid depth at a price counts every bid at or above it, so each step holds up to the next level;
+	// ask depth counts every ask at or below it, so each step holds from its level onwards
+	if len(bids) > 0 {
+		bidLine, err := depthLine(bids, plotter.PreStep,
+			color.RGBA{R: 0, G: 160, B: 0, A: 255}, color.NRGBA{R: 0, G: 200, B: 0, A: 60})
+		if err == nil {
+			depthChart.Add(bidLine)
+			depthChart.Legend.Add("Bids", bidLine)
+		}
+	}
+	if len(asks) > 0 {
+		askLine, err := depthLine(asks, plotter.PostStep,
+			color.RGBA{R: 200, G: 0, B: 0, A: 255}, color.NRGBA{R: 255, G: 0, B: 0, A: 60})
+		if err == nil {
+			depthChart.Add(askLine)
+			depthChart.Legend.Add("Asks", askLine)
+		}
+	}
+
+	// Mark the mid price between the best bid and ask
+	if len(bids) > 0 && len(asks) > 0 {
+		bestBid := bids[len(bids)-1].price
+		bestAsk := asks[0].price
+		mid := (bestBid + bestAsk) / 2
+		maxDepth := max(bids[0].cumulative, asks[len(asks)-1].cumulative)
+
+		midLine, err := plotter.NewLine(plotter.XYs{{X: mid, Y: 0}, {X: mid, Y: maxDepth}})
+		if err == nil {
+			midLine.Color = color.NRGBA{R: 0, G: 0, B: 255, A: 150}
+			midLine.Width = vg.Points(1)
+			midLine.Dashes = []vg.Length{vg.Points(4), vg.Points(4)}
+			depthChart.Add(midLine)
+			depthChart.Legend.Add("Mid "+formatAmount(mid, quote), midLine)
+		}
+		depthChart.Title.Text += fmt.Sprintf(" - Spread %s (%.3f%%)", formatAmount(bestAsk-bestBid, quote), (bestAsk-bestBid)/mid*100)
+	}
+
+	img := vgimg.New(12*vg.Inch, 6*vg.Inch)
+	depthChart.Draw(draw.New(img))
+
+	return encodeChartPNG(img)
+}
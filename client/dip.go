@@ -0,0 +1,37 @@
+package client
+
+import "time"
+
+// dipCooldown is the minimum time since the last signal before an immediate dip signals again
+const dipCooldown = 5 * time.Minute
+
+// GetDipStatus runs the immediate-dip detector on the candles background polling scores and reports its score
+// and triggers. Unlike GetSignal it has no side effects: the trend state, cooldown and webhooks are left alone.
+func (c *CoinbaseClient) GetDipStatus() (*DipStatus, error) {
+	indicators, err := c.signalIndicators(candlesForSpan(lightweightSignalSpan, lightweightSignalGranularity), lightweightSignalGranularity)
+	if err != nil {
+		return nil, err
+	}
+	lastTrend, lastSignalTime := c.trendSnapshot()
+
+	score, triggers := scoreImmediateDip(indicators, c.scoringWeights.Dip)
+	status := &DipStatus{
+		ProductID:  c.tradingPair,
+		Detected:   score >= c.scoringWeights.Dip.Threshold,
+		Score:      score,
+		Threshold:  c.scoringWeights.Dip.Threshold,
+		Triggers:   triggers,
+		LastTrend:  lastTrend,
+		Indicators: indicators,
+		Timestamp:  time.Now().Unix(),
+	}
+	if status.Triggers == nil {
+		status.Triggers = []string{}
+	}
+	if !lastSignalTime.IsZero() {
+		if remaining := dipCooldown - time.Since(lastSignalTime); remaining > 0 {
+			status.CooldownRemainingSeconds = int64(remaining.Seconds())
+		}
+	}
+	return status, nil
+}
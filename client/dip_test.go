@@ -0,0 +1,61 @@
+package client
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetDipStatusLeavesTrendStateAlone(t *testing.T) {
+	// 12 hours of slow climb, then a sharp drop over the last hour
+	closes := make([]float64, 144)
+	for i := range closes {
+		closes[i] = 40000 + float64(i)*10
+		if i >= 132 {
+			closes[i] = closes[131] * (1 - 0.008*float64(i-131))
+		}
+	}
+
+	var webhooks atomic.Int32
+	var limit, granularity string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/brokerage/products/BTC-USDC/candles", func(w http.ResponseWriter, r *http.Request) {
+		limit, granularity = r.URL.Query().Get("limit"), r.URL.Query().Get("granularity")
+		writeJSON(w, CandlesResponse{Candles: closedCandlesUntil(time.Now(), closes)})
+	})
+	mux.HandleFunc("/webhook", func(w http.ResponseWriter, r *http.Request) {
+		webhooks.Add(1)
+	})
+	c := newTestClient(t, mux)
+	c.webhookURL = "https://webhook.test/webhook"
+
+	lastSignal := time.Now().Add(-time.Minute)
+	c.lastTrendState = "neutral"
+	c.lastSignalTime = lastSignal
+
+	status, err := c.GetDipStatus()
+	if err != nil {
+		t.Fatalf("GetDipStatus: %v", err)
+	}
+
+	if limit != "144" || granularity != "FIVE_MINUTE" {
+		t.Errorf("fetched %s %s candles, want the 144 FIVE_MINUTE candles background polling scores", limit, granularity)
+	}
+	if !status.Detected || status.Score < status.Threshold {
+		t.Errorf("dip not detected on a sharp drop: score %.2f, threshold %.2f, triggers %v", status.Score, status.Threshold, status.Triggers)
+	}
+	if status.LastTrend != "neutral" {
+		t.Errorf("LastTrend = %q, want neutral", status.LastTrend)
+	}
+	if status.CooldownRemainingSeconds <= 0 || status.CooldownRemainingSeconds > int64(dipCooldown.Seconds()) {
+		t.Errorf("CooldownRemainingSeconds = %d, want within the %s dip cooldown", status.CooldownRemainingSeconds, dipCooldown)
+	}
+
+	if trend, signalTime := c.trendSnapshot(); trend != "neutral" || !signalTime.Equal(lastSignal) {
+		t.Errorf("trend state changed to %q at %s", trend, signalTime)
+	}
+	if n := webhooks.Load(); n != 0 {
+		t.Errorf("sent %d webhooks, want none", n)
+	}
+}
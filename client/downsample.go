@@ -0,0 +1,60 @@
+package client
+
+import "math"
+
+// defaultChartPoints is the asset value point budget used when plotting long series
+const defaultChartPoints = 200
+
+// downsampleLTTB reduces an account value series to threshold points using
+// Largest-Triangle-Three-Buckets, keeping the first and last points and the visual shape.
+// Series at or below the threshold (or thresholds below 3) are returned unchanged.
+func downsampleLTTB(data []AccountValue, threshold int) []AccountValue {
+	if threshold < 3 || len(data) <= threshold {
+		return data
+	}
+
+	sampled := make([]AccountValue, 0, threshold)
+	sampled = append(sampled, data[0])
+
+	// Every bucket except the first and last point holds this many samples
+	bucketSize := float64(len(data)-2) / float64(threshold-2)
+
+	selected := 0
+	for i := 0; i < threshold-2; i++ {
+		// Average of the next bucket is the third triangle vertex
+		avgStart := int(float64(i+1)*bucketSize) + 1
+		avgEnd := int(float64(i+2)*bucketSize) + 1
+		if avgEnd > len(data) {
+			avgEnd = len(data)
+		}
+		var avgX, avgY float64
+		for j := avgStart; j < avgEnd; j++ {
+			avgX += float64(data[j].Timestamp)
+			avgY += data[j].TotalValue
+		}
+		if count := float64(avgEnd - avgStart); count > 0 {
+			avgX /= count
+			avgY /= count
+		}
+
+		// Pick the point in the current bucket forming the largest triangle
+		rangeStart := int(float64(i)*bucketSize) + 1
+		rangeEnd := int(float64(i+1)*bucketSize) + 1
+		pointX, pointY := float64(data[selected].Timestamp), data[selected].TotalValue
+
+		maxArea := -1.0
+		next := rangeStart
+		for j := rangeStart; j < rangeEnd; j++ {
+			area := math.Abs((pointX-avgX)*(data[j].TotalValue-pointY) - (pointX-float64(data[j].Timestamp))*(avgY-pointY))
+			if area > maxArea {
+				maxArea = area
+				next = j
+			}
+		}
+
+		sampled = append(sampled, data[next])
+		selected = next
+	}
+
+	return append(sampled, data[len(data)-1])
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDownsampleLTTB(t *testing.T) {
+	data := make([]AccountValue, 1000)
+	for i := range data {
+		data[i] = AccountValue{Timestamp: 1704067200 + int64(i)*60, TotalValue: 10000 + 500*math.Sin(float64(i)/40)}
+	}
+	data[500].TotalValue = 20000 // A spike the chart must keep
+
+	for _, threshold := range []int{3, 10, 200, 999} {
+		sampled := downsampleLTTB(data, threshold)
+		if len(sampled) != threshold {
+			t.Errorf("threshold %d: %d points", threshold, len(sampled))
+			continue
+		}
+		if sampled[0] != data[0] || sampled[len(sampled)-1] != data[len(data)-1] {
+			t.Errorf("threshold %d: endpoints %+v, %+v not kept", threshold, sampled[0], sampled[len(sampled)-1])
+		}
+		for i := 1; i < len(sampled); i++ {
+			if sampled[i].Timestamp <= sampled[i-1].Timestamp {
+				t.Fatalf("threshold %d: points out of order at %d", threshold, i)
+			}
+		}
+		spikeKept := false
+		for _, point := range sampled {
+			spikeKept = spikeKept || point.TotalValue == 20000
+		}
+		if threshold >= 10 && !spikeKept {
+			t.Errorf("threshold %d: the spike was dropped", threshold)
+		}
+	}
+
+	// Short series and unusable thresholds are returned unchanged
+	for _, threshold := range []int{0, 2, 1000, 5000} {
+		if sampled := downsampleLTTB(data, threshold); len(sampled) != len(data) {
+			t.Errorf("threshold %d: %d points, want the full %d", threshold, len(sampled), len(data))
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrShuttingDown is returned for trades started after shutdown began draining the client
+var ErrShuttingDown = errors.New("shutting down, not accepting new orders")
+
+// AcceptingOrders returns ErrShuttingDown once the client is draining, so handlers can refuse a trade
+// before doing any work for it
+func (c *CoinbaseClient) AcceptingOrders() error {
+	c.drainMutex.Lock()
+	defer c.drainMutex.Unlock()
+	if c.draining {
+		return ErrShuttingDown
+	}
+	return nil
+}
+
+// beginTrade registers a trade (order placement and status polling, or a replace, rebalance or close that
+// places one) so shutdown waits for it. The returned function must be called when the trade is done.
+func (c *CoinbaseClient) beginTrade() (func(), error) {
+	c.drainMutex.Lock()
+	defer c.drainMutex.Unlock()
+	if c.draining {
+		return nil, ErrShuttingDown
+	}
+	c.inFlightTrades.Add(1)
+	return c.inFlightTrades.Done, nil
+}
+
+// stopTrades makes new trades fail with ErrShuttingDown; trades already started carry on
+func (c *CoinbaseClient) stopTrades() {
+	c.drainMutex.Lock()
+	c.draining = true
+	c.drainMutex.Unlock()
+}
+
+// waitTrades waits for the trades in flight to finish, or for ctx to end
+func (c *CoinbaseClient) waitTrades(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		c.inFlightTrades.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%s trades still in flight: %w", c.tradingPair, ctx.Err())
+	}
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDrainWaitsForTradeInFlight(t *testing.T) {
+	placing := make(chan struct{})
+	release := make(chan struct{})
+	fake := newFakeCoinbase()
+	fake.createOrder = func(req CoinbaseCreateOrderRequest) interface{} {
+		// Hold the order at Coinbase until the test lets it through
+		close(placing)
+		<-release
+		return map[string]interface{}{"success": true, "order_id": "order-1"}
+	}
+	c := newTestClient(t, fake)
+	manager := &ClientManager{clients: map[string]*CoinbaseClient{"BTC-USDC": c}, pairs: []string{"BTC-USDC"}}
+
+	traded := make(chan error, 1)
+	go func() {
+		_, err := c.BuyBTC("0.1", 50000, OrderOptions{})
+		traded <- err
+	}()
+	<-placing
+
+	// Shutdown starts while the order is being placed: the deadline passes with the trade still in flight
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := manager.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drain with a trade in flight = %v, want the deadline exceeded", err)
+	}
+
+	// New trades are refused from then on
+	if err := c.AcceptingOrders(); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("AcceptingOrders while draining = %v, want ErrShuttingDown", err)
+	}
+	if _, err := c.SellBTC("0.1", 50000, OrderOptions{}); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("SellBTC while draining = %v, want ErrShuttingDown", err)
+	}
+
+	// A longer drain returns once the trade in flight finishes, status poll included
+	drained := make(chan error, 1)
+	go func() { drained <- manager.Drain(context.Background()) }()
+	select {
+	case err := <-drained:
+		t.Fatalf("Drain returned %v before the trade finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+
+	if err := <-traded; err != nil {
+		t.Errorf("trade in flight failed: %v", err)
+	}
+	select {
+	case err := <-drained:
+		if err != nil {
+			t.Errorf("Drain = %v, want nil once the trade finished", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Drain did not return after the trade finished")
+	}
+	if len(fake.orders) != 1 {
+		t.Errorf("%d orders placed, want only the one in flight", len(fake.orders))
+	}
+}
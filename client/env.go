@@ -0,0 +1,82 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// getEnvBool helper function to parse boolean environment variables
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	return strings.ToLower(value) == "true" || value == "1"
+}
+
+// getEnvFloat helper function to parse positive float environment variables
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	if parsed, err := strconv.ParseFloat(value, 64); err == nil && parsed > 0 {
+		return parsed
+	}
+	return defaultValue // Default on invalid value
+}
+
+// getEnvInt helper function to parse positive integer environment variables
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+		return parsed
+	}
+	return defaultValue // Default on invalid value
+}
+
+// getEnvDuration helper function to parse positive duration environment variables (e.g. "72h", "30m")
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+		return parsed
+	}
+	return defaultValue // Default on invalid value
+}
+
+// getEnvNonNegativeInt parses a non-negative integer environment variable, where zero is a meaningful
+// value (e.g. "no limit"), and rejects negative or malformed values instead of silently defaulting
+func getEnvNonNegativeInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %q", key, value)
+	}
+	return parsed, nil
+}
+
+// getEnvNonNegativeDuration parses a non-negative duration environment variable, where zero is a meaningful
+// value (e.g. "no limit"), and rejects negative or malformed values instead of silently defaulting
+func getEnvNonNegativeDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative duration, got %q", key, value)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// defaultExecutionPollInterval is how often orders still open after placement are checked for fills
+const defaultExecutionPollInterval = time.Minute
+
+// ExecutionPollInterval returns the EXECUTION_POLL_INTERVAL setting: how often orders left open after placement
+// are checked for fills while EXECUTION_WEBHOOK_URL is set
+func ExecutionPollInterval() time.Duration {
+	return getEnvDuration("EXECUTION_POLL_INTERVAL", defaultExecutionPollInterval)
+}
+
+// notifyExecution sends the execution webhook in the background when an order has (partially) filled.
+// It is a no-op unless EXECUTION_WEBHOOK_URL is set.
+func (c *CoinbaseClient) notifyExecution(order *Order, fee string) {
+	if c.executionWebhookURL == "" || !parseDecimal(order.FilledSize).IsPositive() {
+		return
+	}
+	go func() {
+		if err := c.sendExecutionWebhook(order, fee); err != nil {
+			c.logger.Printf("Execution webhook failed for order %s: %v", order.ID, err)
+		}
+	}()
+}
+
+// watchOrderFills keeps checking an order left open after placement, so fills after the placement poll still
+// reach the execution webhook. notified is the filled size already sent.
+func (c *CoinbaseClient) watchOrderFills(orderID string, notified decimal.Decimal) {
+	if c.executionWebhookURL == "" {
+		return
+	}
+	c.watchedOrdersMutex.Lock()
+	defer c.watchedOrdersMutex.Unlock()
+	if c.watchedOrders == nil {
+		c.watchedOrders = make(map[string]decimal.Decimal)
+	}
+	c.watchedOrders[orderID] = notified
+}
+
+// observeOrderFill is given every order status read from Coinbase. When a watched order filled more since
+// the last notification, the execution webhook is sent with its cumulative fill; once the order can no longer
+// change it is not watched anymore.
+func (c *CoinbaseClient) observeOrderFill(status *CoinbaseOrder) {
+	c.watchedOrdersMutex.Lock()
+	notified, watched := c.watchedOrders[status.OrderID]
+	filled := parseDecimal(status.FilledSize)
+	newFill := watched && filled.GreaterThan(notified)
+	if newFill {
+		c.watchedOrders[status.OrderID] = filled
+	}
+	if watched && isTerminalOrderStatus(status.Status) {
+		delete(c.watchedOrders, status.OrderID)
+	}
+	c.watchedOrdersMutex.Unlock()
+
+	if newFill {
+		c.notifyExecution(&Order{
+			ID:           status.OrderID,
+			ProductID:    status.ProductID,
+			Side:         status.Side,
+			Status:       status.Status,
+			FilledSize:   status.FilledSize,
+			FilledValue:  status.FilledValue,
+			AveragePrice: status.AverageFilledPrice,
+		}, status.TotalFees)
+	}
+}
+
+// PollWatchedOrders reads the status of every order left open after placement, which sends the execution
+// webhook for the fills found since the last check
+func (c *CoinbaseClient) PollWatchedOrders() {
+	c.watchedOrdersMutex.Lock()
+	orderIDs := make([]string, 0, len(c.watchedOrders))
+	for orderID := range c.watchedOrders {
+		orderIDs = append(orderIDs, orderID)
+	}
+	c.watchedOrdersMutex.Unlock()
+
+	for _, orderID := range orderIDs {
+		if _, err := c.GetOrderStatus(orderID); err != nil {
+			c.errorLog.Printf("fill-watch", "[WARN] Could not check order %s for fills: %v", orderID, err)
+			return
+		}
+	}
+	c.errorLog.Clear("fill-watch")
+}
+
+// sendExecutionWebhook delivers an execution event (side, size, fill price, fee and resulting balances)
+// to EXECUTION_WEBHOOK_URL, with retries
+func (c *CoinbaseClient) sendExecutionWebhook(order *Order, fee string) error {
+	req, err := http.NewRequest("GET", c.executionWebhookURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create execution webhook request: %w", err)
+	}
+
+	q := req.URL.Query()
+	q.Add("execution", "true")
+	q.Add("order_id", order.ID)
+	q.Add("product_id", order.ProductID)
+	q.Add("side", order.Side)
+	q.Add("status", order.Status)
+	q.Add("size", order.FilledSize)
+	q.Add("fill_price", order.AveragePrice)
+	q.Add("filled_value", order.FilledValue)
+	q.Add("fee", fee)
+	q.Add("timestamp", fmt.Sprintf("%d", time.Now().Unix()))
+	q.Add("pair", c.tradingPair)
+	q.Add("sequence", fmt.Sprintf("%d", c.NextWebhookSequence()))
+
+	// Resulting balances after the fill (best effort)
+	if accounts, err := c.GetAccounts(); err == nil {
+		parts := strings.Split(c.tradingPair, "-")
+		for _, account := range accounts {
+			if len(parts) == 2 && (account.Currency == parts[0] || account.Currency == parts[1]) {
+				q.Add(strings.ToLower(account.Currency)+"_balance", account.AvailableBalance)
+			}
+		}
+	} else {
+		c.logger.Printf("[WARN] Could not fetch balances for execution webhook: %v", err)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	if err := c.deliverWebhook(req, "Execution"); err != nil {
+		return err
+	}
+	if c.debug {
+		c.logger.Printf("✅ Execution webhook sent for order %s", order.ID)
+	}
+	return nil
+}
+
+// deliverWebhook sends a prepared webhook request, retrying with the same exponential backoff as the signal
+// webhook (WEBHOOK_MAX_RETRIES attempts after the first, WEBHOOK_TIMEOUT_SECONDS each). name labels the logs.
+func (c *CoinbaseClient) deliverWebhook(req *http.Request, name string) error {
+	var err error
+	baseDelay := 1 * time.Second
+	for attempt := 0; attempt <= c.webhookMaxRetries; attempt++ {
+		err = c.sendWebhookRequest(req)
+		if err == nil {
+			return nil
+		}
+		c.logger.Printf("%s webhook failed (attempt %d/%d): %v", name, attempt+1, c.webhookMaxRetries+1, err)
+		if attempt < c.webhookMaxRetries {
+			time.Sleep(time.Duration(float64(baseDelay) * math.Pow(2, float64(attempt))))
+		}
+	}
+	return fmt.Errorf("%s webhook failed after %d attempts: %w", strings.ToLower(name), c.webhookMaxRetries+1, err)
+}
+
+// sendWebhookRequest performs a single webhook request
+func (c *CoinbaseClient) sendWebhookRequest(req *http.Request) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.webhookTimeout)*time.Second)
+	defer cancel()
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
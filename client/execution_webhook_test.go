@@ -0,0 +1,101 @@
+package client
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// waitForWebhooks waits until the fake received n execution webhooks, which are sent asynchronously
+func waitForWebhooks(t *testing.T, fake *fakeCoinbase, n int) []url.Values {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		fake.mutex.Lock()
+		webhooks := append([]url.Values(nil), fake.webhooks...)
+		fake.mutex.Unlock()
+		if len(webhooks) >= n || time.Now().After(deadline) {
+			return webhooks
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRestingOrderFillsReachExecutionWebhook(t *testing.T) {
+	fake := newFakeCoinbase()
+	fake.createOrder = func(req CoinbaseCreateOrderRequest) interface{} {
+		return map[string]interface{}{"success": true, "order_id": "gtc-1"}
+	}
+	fake.orderStatuses["gtc-1"] = CoinbaseOrder{OrderID: "gtc-1", ProductID: "BTC-USDC", Side: "BUY", Status: "OPEN", FilledSize: "0"}
+
+	c := newTestClient(t, fake)
+	c.executionWebhookURL = "http://webhook.test/webhook/execution"
+	c.webhookTimeout = 5
+
+	order, err := c.BuyBTC("0.1", 49000, OrderOptions{})
+	if err != nil {
+		t.Fatalf("BuyBTC: %v", err)
+	}
+	if order.Status != "OPEN" {
+		t.Fatalf("status = %s, want OPEN", order.Status)
+	}
+
+	// Nothing filled yet: no notification
+	c.PollWatchedOrders()
+	time.Sleep(100 * time.Millisecond)
+	fake.mutex.Lock()
+	early := len(fake.webhooks)
+	fake.mutex.Unlock()
+	if early != 0 {
+		t.Fatalf("%d webhooks before any fill", early)
+	}
+
+	fill := func(status, filledSize string) {
+		fake.mutex.Lock()
+		fake.orderStatuses["gtc-1"] = CoinbaseOrder{
+			OrderID: "gtc-1", ProductID: "BTC-USDC", Side: "BUY", Status: status,
+			FilledSize: filledSize, AverageFilledPrice: "49000", TotalFees: "1",
+		}
+		fake.mutex.Unlock()
+	}
+
+	fill("OPEN", "0.04")
+	c.PollWatchedOrders()
+	c.PollWatchedOrders() // Same fill seen twice: one notification
+	webhooks := waitForWebhooks(t, fake, 1)
+	if len(webhooks) != 1 || webhooks[0].Get("size") != "0.04" || webhooks[0].Get("status") != "OPEN" {
+		t.Fatalf("webhooks after the partial fill = %v, want one with size 0.04", webhooks)
+	}
+
+	fill("FILLED", "0.1")
+	if _, err := c.GetOrderStatus("gtc-1"); err != nil {
+		t.Fatalf("GetOrderStatus: %v", err)
+	}
+	webhooks = waitForWebhooks(t, fake, 2)
+	if len(webhooks) != 2 || webhooks[1].Get("size") != "0.1" || webhooks[1].Get("status") != "FILLED" || webhooks[1].Get("order_id") != "gtc-1" {
+		t.Fatalf("webhooks after the fill = %v, want a second one with size 0.1", webhooks)
+	}
+
+	// Filled orders are not watched anymore
+	calls := fake.called("GET /orders/historical/gtc-1")
+	c.PollWatchedOrders()
+	if fake.called("GET /orders/historical/gtc-1") != calls {
+		t.Error("filled order is still polled")
+	}
+}
+
+func TestOrdersAreNotWatchedWithoutExecutionWebhook(t *testing.T) {
+	fake := newFakeCoinbase()
+	fake.createOrder = func(req CoinbaseCreateOrderRequest) interface{} {
+		return map[string]interface{}{"success": true, "order_id": "gtc-1"}
+	}
+	fake.orderStatuses["gtc-1"] = CoinbaseOrder{OrderID: "gtc-1", ProductID: "BTC-USDC", Side: "BUY", Status: "OPEN", FilledSize: "0"}
+
+	c := newTestClient(t, fake)
+	if _, err := c.BuyBTC("0.1", 49000, OrderOptions{}); err != nil {
+		t.Fatalf("BuyBTC: %v", err)
+	}
+	if len(c.watchedOrders) != 0 {
+		t.Errorf("watched orders = %v, want none", c.watchedOrders)
+	}
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxOrderBookDepth is the deepest order book GetOrderBook returns
+const maxOrderBookDepth = 100
+
+// EstimateFillPrice walks the order book (asks for a BUY, bids for a SELL) until size is filled and returns the
+// size-weighted average price, the price of the last level touched and the size the visible depth can fill.
+// filled is below size when the book is too thin, in which case the prices cover only the filled part.
+func (c *CoinbaseClient) EstimateFillPrice(side string, size float64) (avgPrice, worstPrice, filled float64, err error) {
+	if size <= 0 {
+		return 0, 0, 0, fmt.Errorf("size must be positive")
+	}
+
+	orderBook, err := c.GetOrderBook(maxOrderBookDepth)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to get order book: %w", err)
+	}
+
+	var levels []OrderBookEntry
+	switch strings.ToUpper(side) {
+	case "BUY":
+		levels = orderBook.Asks
+	case "SELL":
+		levels = orderBook.Bids
+	default:
+		return 0, 0, 0, fmt.Errorf("side must be BUY or SELL")
+	}
+
+	var notional float64
+	for _, level := range levels {
+		price, err := strconv.ParseFloat(level.Price, 64)
+		if err != nil || price <= 0 {
+			continue
+		}
+		levelSize, err := strconv.ParseFloat(level.Size, 64)
+		if err != nil || levelSize <= 0 {
+			continue
+		}
+
+		take := min(levelSize, size-filled)
+		notional += take * price
+		filled += take
+		worstPrice = price
+		if filled >= size {
+			break
+		}
+	}
+
+	if filled > 0 {
+		avgPrice = notional / filled
+	}
+	return avgPrice, worstPrice, filled, nil
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Health states reported to HEALTH_WEBHOOK_URL
+const (
+	HealthStateHealthy   = "healthy"
+	HealthStateUnhealthy = "unhealthy"
+)
+
+// HealthMonitor tracks the results of the health check and calls HEALTH_WEBHOOK_URL when the service flips
+// between healthy and unhealthy. A new state must be seen debounce times in a row before it counts, so a
+// single failed Coinbase call doesn't raise (and then clear) an alert.
+type HealthMonitor struct {
+	client   *CoinbaseClient
+	url      string
+	debounce int
+
+	mutex        sync.Mutex
+	state        string // Confirmed state, empty until the first result
+	pending      string // Candidate state that differs from the confirmed one
+	pendingCount int
+	since        time.Time // When the confirmed state was entered
+}
+
+// NewHealthMonitor creates a health monitor alerting url through client's webhook settings (retries, timeout,
+// sequence numbers). debounce below one is treated as one (alert on the first differing result).
+func NewHealthMonitor(client *CoinbaseClient, url string, debounce int) *HealthMonitor {
+	return &HealthMonitor{
+		client:   client,
+		url:      url,
+		debounce: max(debounce, 1),
+	}
+}
+
+// Record adds a health check result and reports whether it confirmed a transition. The first result only
+// sets the initial state. The webhook is sent in the background; reason describes the failure, if any.
+func (m *HealthMonitor) Record(healthy bool, reason string) bool {
+	state := HealthStateHealthy
+	if !healthy {
+		state = HealthStateUnhealthy
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	if m.state == "" {
+		m.state, m.since = state, now
+		return false
+	}
+	if state == m.state {
+		m.pending, m.pendingCount = "", 0
+		return false
+	}
+
+	if state != m.pending {
+		m.pending, m.pendingCount = state, 0
+	}
+	m.pendingCount++
+	if m.pendingCount < m.debounce {
+		return false
+	}
+
+	previous, duration := m.state, now.Sub(m.since)
+	m.state, m.since = state, now
+	m.pending, m.pendingCount = "", 0
+
+	m.client.logger.Printf("🩺 Health changed: %s → %s after %v (%s)", previous, state, duration.Round(time.Second), reason)
+	if m.url != "" {
+		go func() {
+			if err := m.send(state, previous, reason, duration); err != nil {
+				m.client.logger.Printf("Health webhook failed: %v", err)
+			}
+		}()
+	}
+	return true
+}
+
+// State returns the confirmed health state, empty before the first result
+func (m *HealthMonitor) State() string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.state
+}
+
+// send delivers a health transition to HEALTH_WEBHOOK_URL as a GET with query parameters
+func (m *HealthMonitor) send(state, previous, reason string, duration time.Duration) error {
+	req, err := http.NewRequest("GET", m.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create health webhook request: %w", err)
+	}
+
+	q := req.URL.Query()
+	q.Add("health", "true")
+	q.Add("status", state)
+	q.Add("previous", previous)
+	q.Add("previous_duration_seconds", fmt.Sprintf("%d", int64(duration.Seconds())))
+	if reason != "" {
+		q.Add("reason", reason)
+	}
+	q.Add("timestamp", fmt.Sprintf("%d", time.Now().Unix()))
+	q.Add("pair", m.client.tradingPair)
+	q.Add("sequence", fmt.Sprintf("%d", m.client.NextWebhookSequence()))
+	req.URL.RawQuery = q.Encode()
+
+	return m.client.deliverWebhook(req, "Health")
+}
@@ -0,0 +1,51 @@
+package client
+
+import "testing"
+
+func TestHealthMonitorRecord(t *testing.T) {
+	fake := newFakeCoinbase()
+	c := newTestClient(t, fake)
+	c.webhookTimeout = 5
+	monitor := NewHealthMonitor(c, "http://webhook.test/webhook/health", 2)
+
+	steps := []struct {
+		healthy    bool
+		reason     string
+		transition bool
+		state      string
+	}{
+		{true, "", false, HealthStateHealthy},                                   // First result only sets the state
+		{false, "Coinbase API communication failed", false, HealthStateHealthy}, // Debounced
+		{true, "", false, HealthStateHealthy},                                   // Blip cleared
+		{false, "Coinbase API communication failed", false, HealthStateHealthy},
+		{false, "Coinbase API communication failed", true, HealthStateUnhealthy},
+		{false, "Coinbase API communication failed", false, HealthStateUnhealthy},
+		{true, "", false, HealthStateUnhealthy},
+		{true, "", true, HealthStateHealthy},
+	}
+	for i, step := range steps {
+		if transition := monitor.Record(step.healthy, step.reason); transition != step.transition {
+			t.Errorf("step %d: Record(%v) = %v, want %v", i, step.healthy, transition, step.transition)
+		}
+		if state := monitor.State(); state != step.state {
+			t.Errorf("step %d: state = %s, want %s", i, state, step.state)
+		}
+	}
+
+	webhooks := waitForWebhooks(t, fake, 2)
+	if len(webhooks) != 2 {
+		t.Fatalf("%d health webhooks sent, want 2", len(webhooks))
+	}
+	// Sent in the background, so in either order
+	byStatus := map[string]int{}
+	for i, webhook := range webhooks {
+		byStatus[webhook.Get("status")] = i
+	}
+	down, up := webhooks[byStatus[HealthStateUnhealthy]], webhooks[byStatus[HealthStateHealthy]]
+	if down.Get("status") != HealthStateUnhealthy || down.Get("previous") != HealthStateHealthy || down.Get("reason") != "Coinbase API communication failed" {
+		t.Errorf("unhealthy webhook = %v", down)
+	}
+	if up.Get("status") != HealthStateHealthy || up.Get("previous") != HealthStateUnhealthy || up.Has("reason") {
+		t.Errorf("healthy webhook = %v, want no reason", up)
+	}
+}
@@ -4,20 +4,58 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"strings"
 	"sync/atomic"
+
+	"coinbase-base/redact"
 )
 
 const baseURL = "https://api.coinbase.com/api/v3/brokerage"
 
+// maxBodySnippet limits how much of a response body is echoed back in errors
+const maxBodySnippet = 256
+
+// ErrUpstreamNonJSON is returned when Coinbase answers with a non-JSON body, such as an HTML maintenance page
+var ErrUpstreamNonJSON = errors.New("upstream returned a non-JSON response")
+
+// isNonJSONResponse reports whether a response looks like HTML or another non-JSON payload
+func isNonJSONResponse(contentType string, body []byte) bool {
+	if strings.Contains(strings.ToLower(contentType), "text/html") {
+		return true
+	}
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && trimmed[0] == '<'
+}
+
+// decodeJSON unmarshals a response body and includes a truncated copy of the body on failure
+// so upstream schema changes can be diagnosed from the error alone
+func decodeJSON(body []byte, v interface{}, name string) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to unmarshal %s response: %w (body: %s)", name, err, truncateBody(body))
+	}
+	return nil
+}
+
+// truncateBody returns at most maxBodySnippet bytes of a response body
+func truncateBody(body []byte) string {
+	if len(body) <= maxBodySnippet {
+		return string(body)
+	}
+	return string(body[:maxBodySnippet]) + "...(truncated)"
+}
+
 // makeRequest makes an authenticated HTTP request to the Coinbase API
 func (c *CoinbaseClient) makeRequest(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
-	// Track request count
-	atomic.AddInt64(&c.requestCount, 1)
+	// Wait for the rate limiter (blocks until a token is available or the context is done)
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("rate limiter wait failed: %w", err)
+		}
+	}
 
 	// Extract the path for JWT URI construction (exclude query parameters)
 	path := endpoint
@@ -25,6 +63,10 @@ func (c *CoinbaseClient) makeRequest(ctx context.Context, method, endpoint strin
 		path = endpoint[:idx]
 	}
 
+	// Track request count, overall and per endpoint
+	atomic.AddInt64(&c.requestCount, 1)
+	c.countEndpointRequest(method, path)
+
 	fullPath := "/api/v3/brokerage" + path
 
 	jwt, err := c.createJWT(ctx, method, fullPath)
@@ -58,7 +100,7 @@ func (c *CoinbaseClient) makeRequest(ctx context.Context, method, endpoint strin
 	}
 
 	// Debug: Log request details (skip for health checks)
-	if os.Getenv("LOG_LEVEL") == "DEBUG" && endpoint != "/health" && ctx.Value(healthCheckKey) != true {
+	if c.debug && endpoint != "/health" && ctx.Value(healthCheckKey) != true {
 		c.logger.Printf("=== REQUEST DUMP ===")
 		c.logger.Printf("Method: %s", method)
 		c.logger.Printf("URL: %s", url)
@@ -66,7 +108,7 @@ func (c *CoinbaseClient) makeRequest(ctx context.Context, method, endpoint strin
 		for key, values := range req.Header {
 			for _, value := range values {
 				if key == "Authorization" {
-					c.logger.Printf("  %s: Bearer %s", key, jwt)
+					c.logger.Printf("  %s: Bearer %s (kid %s)", key, redact.JWT(jwt), redact.Secret(c.apiKey))
 				} else {
 					c.logger.Printf("  %s: %s", key, value)
 				}
@@ -88,6 +130,9 @@ func (c *CoinbaseClient) makeRequest(ctx context.Context, method, endpoint strin
 	}
 	defer resp.Body.Close()
 
+	// Track Coinbase rate-limit headers and slow down before hitting the limit
+	c.recordRateLimitHeaders(resp.Header)
+
 	// Read response
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -95,7 +140,7 @@ func (c *CoinbaseClient) makeRequest(ctx context.Context, method, endpoint strin
 	}
 
 	// Debug: Log response details (skip for health checks)
-	if os.Getenv("LOG_LEVEL") == "DEBUG" && endpoint != "/health" && ctx.Value(healthCheckKey) != true {
+	if c.debug && endpoint != "/health" && ctx.Value(healthCheckKey) != true {
 		c.logger.Printf("=== RESPONSE DUMP ===")
 		c.logger.Printf("Status: %s", resp.Status)
 		c.logger.Printf("Status Code: %d", resp.StatusCode)
@@ -118,6 +163,11 @@ func (c *CoinbaseClient) makeRequest(ctx context.Context, method, endpoint strin
 		c.logger.Printf("==================")
 	}
 
+	// Detect HTML error pages (e.g. during Coinbase incidents) before attempting to parse them as JSON
+	if isNonJSONResponse(resp.Header.Get("Content-Type"), respBody) {
+		return nil, fmt.Errorf("%w: status %d: %s", ErrUpstreamNonJSON, resp.StatusCode, truncateBody(respBody))
+	}
+
 	// Check status code
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
@@ -125,3 +175,37 @@ func (c *CoinbaseClient) makeRequest(ctx context.Context, method, endpoint strin
 
 	return respBody, nil
 }
+
+// countEndpointRequest increments the per-endpoint counter reported by GetPerformanceStats
+func (c *CoinbaseClient) countEndpointRequest(method, path string) {
+	key := method + " " + endpointTemplate(path)
+	c.endpointCountsMux.Lock()
+	if c.endpointCounts == nil {
+		c.endpointCounts = make(map[string]int64)
+	}
+	c.endpointCounts[key]++
+	c.endpointCountsMux.Unlock()
+}
+
+// endpointRequestCounts returns a snapshot of the per-endpoint request counters
+func (c *CoinbaseClient) endpointRequestCounts() map[string]int64 {
+	c.endpointCountsMux.Lock()
+	defer c.endpointCountsMux.Unlock()
+	counts := make(map[string]int64, len(c.endpointCounts))
+	for key, count := range c.endpointCounts {
+		counts[key] = count
+	}
+	return counts
+}
+
+// endpointTemplate replaces variable path segments (product IDs, order and account UUIDs) with {id}
+// so the counters stay bounded, e.g. /products/BTC-USDC/candles -> /products/{id}/candles
+func endpointTemplate(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.ContainsAny(segment, "-0123456789") {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
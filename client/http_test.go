@@ -0,0 +1,325 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// serverTransport sends every request, whatever its host, to a test server
+type serverTransport struct {
+	target *url.URL
+}
+
+func (t serverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newTestClient returns a BTC-USDC client signed with a throwaway key whose Coinbase requests are served by handler
+func newTestClient(t testing.TB, handler http.Handler) *CoinbaseClient {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	t.Setenv("COINBASE_API_KEY", "test-key")
+	t.Setenv("COINBASE_API_SECRET", string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})))
+
+	c, err := NewCoinbaseClient("BTC-USDC", "", 0, 0)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	target, _ := url.Parse(server.URL)
+	c.httpClient = &http.Client{Transport: serverTransport{target: target}, Timeout: 5 * time.Second}
+	c.rateLimiter = nil // Nothing to protect, don't slow the tests down
+	c.logger = discardLogger()
+	c.errorLog = NewLogThrottle(c.logger, time.Hour)
+	return c
+}
+
+// writeJSON encodes v as the response body
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// fakeCoinbase serves the brokerage endpoints the order paths use from in-memory BTC-USDC state, and counts
+// the requests it receives by "METHOD /path"
+type fakeCoinbase struct {
+	mutex sync.Mutex
+
+	baseAvailable, baseHold, quoteAvailable string
+	bid, ask                                string
+	emptyBook                               bool // Serve no bids or asks; the product price stays bid
+	openOrders                              []string
+	cancelFailures                          map[string]string        // Order ID to the failure reason batch_cancel reports
+	orderStatuses                           map[string]CoinbaseOrder // Order ID to its status (default FILLED)
+	statusFailures                          int                      // Order status requests answered with a 500 before the status
+	candles                                 []Candle                 // Served for every candle request, whatever the window
+	maintenancePage                         string                   // If set, every API request gets it as a 503 HTML page
+
+	// createOrder answers POST /orders (the default accepts every order); orders records the requests
+	createOrder func(req CoinbaseCreateOrderRequest) interface{}
+	orders      []CoinbaseCreateOrderRequest
+
+	// webhooks records the query of every request under /webhook/ (execution and health webhooks)
+	webhooks []url.Values
+
+	calls map[string]int
+}
+
+// newFakeCoinbase returns a fake holding 1 BTC and 10000 USDC with a 50000/50010 book
+func newFakeCoinbase() *fakeCoinbase {
+	return &fakeCoinbase{
+		baseAvailable:  "1",
+		baseHold:       "0",
+		quoteAvailable: "10000",
+		bid:            "50000",
+		ask:            "50010",
+		cancelFailures: map[string]string{},
+		orderStatuses:  map[string]CoinbaseOrder{},
+		calls:          map[string]int{},
+	}
+}
+
+// called returns how many requests were made to "METHOD /path" (path after /api/v3/brokerage)
+func (f *fakeCoinbase) called(endpoint string) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.calls[endpoint]
+}
+
+func (f *fakeCoinbase) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v3/brokerage")
+	f.calls[r.Method+" "+path]++
+
+	if f.maintenancePage != "" && !strings.HasPrefix(path, "/webhook/") {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(f.maintenancePage))
+		return
+	}
+
+	switch {
+	case path == "/accounts":
+		account := func(currency, available, hold string) map[string]interface{} {
+			return map[string]interface{}{
+				"uuid":              currency + "-account",
+				"currency":          currency,
+				"available_balance": map[string]string{"value": available},
+				"hold":              map[string]string{"value": hold},
+			}
+		}
+		writeJSON(w, map[string]interface{}{"accounts": []interface{}{
+			account("BTC", f.baseAvailable, f.baseHold),
+			account("USDC", f.quoteAvailable, "0"),
+		}})
+	case path == "/product_book":
+		bids, asks := []OrderBookEntry{{Price: f.bid, Size: "1"}}, []OrderBookEntry{{Price: f.ask, Size: "1"}}
+		if f.emptyBook {
+			bids, asks = []OrderBookEntry{}, []OrderBookEntry{}
+		}
+		writeJSON(w, map[string]interface{}{"pricebook": map[string]interface{}{
+			"product_id": "BTC-USDC",
+			"bids":       bids,
+			"asks":       asks,
+		}})
+	case path == "/products/BTC-USDC/candles":
+		writeJSON(w, CandlesResponse{Candles: f.candles})
+	case path == "/products/BTC-USDC":
+		writeJSON(w, CoinbaseProduct{ProductID: "BTC-USDC", Price: f.bid, BaseIncrement: "0.00000001", QuoteIncrement: "0.01"})
+	case path == "/orders/historical/batch":
+		orders := make([]map[string]string, len(f.openOrders))
+		for i, id := range f.openOrders {
+			orders[i] = map[string]string{"order_id": id, "product_id": "BTC-USDC", "side": "SELL", "status": "OPEN"}
+		}
+		writeJSON(w, map[string]interface{}{"orders": orders})
+	case strings.HasPrefix(path, "/orders/historical/"):
+		if f.statusFailures > 0 {
+			f.statusFailures--
+			http.Error(w, `{"error":"INTERNAL","message":"status unavailable"}`, http.StatusInternalServerError)
+			return
+		}
+		id := strings.TrimPrefix(path, "/orders/historical/")
+		if status, ok := f.orderStatuses[id]; ok {
+			writeJSON(w, status)
+			return
+		}
+		writeJSON(w, CoinbaseOrder{OrderID: id, ProductID: "BTC-USDC", Status: "FILLED"})
+	case path == "/orders/batch_cancel":
+		var req struct {
+			OrderIDs []string `json:"order_ids"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		results := make([]map[string]interface{}, len(req.OrderIDs))
+		for i, id := range req.OrderIDs {
+			reason, failed := f.cancelFailures[id]
+			results[i] = map[string]interface{}{"order_id": id, "success": !failed, "failure_reason": reason}
+		}
+		writeJSON(w, map[string]interface{}{"results": results})
+	case path == "/orders" && r.Method == http.MethodPost:
+		var req CoinbaseCreateOrderRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		f.orders = append(f.orders, req)
+		if f.createOrder != nil {
+			writeJSON(w, f.createOrder(req))
+			return
+		}
+		writeJSON(w, map[string]interface{}{"success": true, "order_id": "order-" + req.ClientOrderID})
+	case strings.HasPrefix(path, "/webhook/"):
+		f.webhooks = append(f.webhooks, r.URL.Query())
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestMaintenancePageIsReportedAsUnavailable(t *testing.T) {
+	fake := newFakeCoinbase()
+	fake.maintenancePage = "<!DOCTYPE html><html><head><title>Coinbase maintenance</title></head><body>" +
+		strings.Repeat("<p>We are performing scheduled maintenance and will be back shortly.</p>", 10) + "</body></html>"
+	c := newTestClient(t, fake)
+
+	_, err := c.GetAccounts()
+	if !errors.Is(err, ErrUpstreamNonJSON) {
+		t.Fatalf("GetAccounts = %v, want ErrUpstreamNonJSON", err)
+	}
+	message := err.Error()
+	for _, want := range []string{"coinbase appears to be unavailable", "status 503", "<title>Coinbase maintenance</title>", "...(truncated)"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("error %q does not mention %q", message, want)
+		}
+	}
+	if strings.Contains(message, "invalid character") || strings.Contains(message, "</html>") {
+		t.Errorf("error %q has a JSON parse error or the whole page", message)
+	}
+}
+
+func TestIsNonJSONResponse(t *testing.T) {
+	tests := []struct {
+		contentType, body string
+		nonJSON           bool
+	}{
+		{"text/html; charset=utf-8", "Service Unavailable", true},
+		{"application/json", "  <html><body>502 Bad Gateway</body></html>", true}, // Mislabelled HTML
+		{"application/json", `{"accounts": []}`, false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got := isNonJSONResponse(tt.contentType, []byte(tt.body)); got != tt.nonJSON {
+			t.Errorf("isNonJSONResponse(%q, %q) = %v, want %v", tt.contentType, tt.body, got, tt.nonJSON)
+		}
+	}
+}
+
+func TestRequestCountersUnderConcurrency(t *testing.T) {
+	const workers, requests = 8, 25
+	c := newTestClient(t, newFakeCoinbase())
+
+	done := make(chan struct{})
+	snapshots := make(chan struct{})
+	go func() {
+		defer close(snapshots)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				stats := c.GetPerformanceStats()
+				_ = stats["total_requests"].(int64)
+				_ = stats["requests_by_endpoint"].(map[string]int64)
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for j := 0; j < requests; j++ {
+				endpoint := "/accounts"
+				if j%2 == 1 {
+					endpoint = fmt.Sprintf("/orders/historical/%d-%d", worker, j)
+				}
+				if _, err := c.makeRequest(context.Background(), "GET", endpoint, nil); err != nil {
+					t.Errorf("makeRequest(%s): %v", endpoint, err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(done)
+	<-snapshots
+
+	stats := c.GetPerformanceStats()
+	if total := stats["total_requests"].(int64); total != workers*requests {
+		t.Errorf("total_requests = %d, want %d", total, workers*requests)
+	}
+	want := map[string]int64{
+		"GET /accounts":               workers * (requests + 1) / 2,
+		"GET /orders/historical/{id}": workers * (requests / 2),
+	}
+	if counts := stats["requests_by_endpoint"].(map[string]int64); !reflect.DeepEqual(counts, want) {
+		t.Errorf("requests_by_endpoint = %v, want %v", counts, want)
+	}
+}
+
+func TestDebugRequestDumpRedactsSecrets(t *testing.T) {
+	fake := newFakeCoinbase()
+	var authorization string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		fake.ServeHTTP(w, r)
+	}))
+	var logged bytes.Buffer
+	c.logger = log.New(&logged, "", 0)
+	c.apiKey = "organizations/abc/apiKeys/0123456789abcdef"
+	c.SetDebug(true)
+
+	if _, err := c.makeRequest(context.Background(), "GET", "/accounts", nil); err != nil {
+		t.Fatalf("makeRequest: %v", err)
+	}
+	jwt := strings.TrimPrefix(authorization, "Bearer ")
+	if jwt == "" || jwt == authorization {
+		t.Fatalf("Authorization header = %q, want a bearer token", authorization)
+	}
+
+	output := logged.String()
+	if strings.Contains(output, jwt) || strings.Contains(output, c.apiKey) {
+		t.Errorf("debug log exposes the token or API key:\n%s", output)
+	}
+	// Enough is kept to correlate the request
+	if !strings.Contains(output, "Bearer <redacted>..."+jwt[len(jwt)-8:]) || !strings.Contains(output, "kid orga****cdef") {
+		t.Errorf("debug log has no redacted token or key:\n%s", output)
+	}
+}
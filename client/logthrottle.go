@@ -0,0 +1,83 @@
+package client
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// defaultLogRepeatWindow is how long identical consecutive messages are collapsed (LOG_REPEAT_WINDOW)
+const defaultLogRepeatWindow = time.Hour
+
+// LogRepeatWindow returns the LOG_REPEAT_WINDOW setting: how long a repeated error is collapsed before it is
+// logged again with its repeat count
+func LogRepeatWindow() time.Duration {
+	return getEnvDuration("LOG_REPEAT_WINDOW", defaultLogRepeatWindow)
+}
+
+// throttledMessage is the last message logged for a key and how often it came back since
+type throttledMessage struct {
+	message string
+	since   time.Time
+	repeats int
+}
+
+// LogThrottle collapses identical consecutive log messages so a sustained failure (e.g. a Coinbase outage
+// hit on every poll) doesn't flood the logs. Messages are grouped by key: the first one is logged, identical
+// ones within the window are only counted, and the count is logged as "repeated N times" when the message
+// changes, the window ends or the key is cleared.
+type LogThrottle struct {
+	logger *log.Logger
+	window time.Duration
+
+	mutex    sync.Mutex
+	messages map[string]*throttledMessage
+}
+
+// NewLogThrottle creates a log throttle writing to logger and collapsing repeats for window
+func NewLogThrottle(logger *log.Logger, window time.Duration) *LogThrottle {
+	return &LogThrottle{
+		logger:   logger,
+		window:   window,
+		messages: make(map[string]*throttledMessage),
+	}
+}
+
+// Printf logs a message under key unless it repeats the last message of that key within the window
+func (t *LogThrottle) Printf(key, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	now := time.Now()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	last := t.messages[key]
+	if last != nil && last.message == message && now.Sub(last.since) < t.window {
+		last.repeats++
+		return
+	}
+
+	t.flush(last)
+	t.messages[key] = &throttledMessage{message: message, since: now}
+	t.logger.Output(2, message) // Report the caller's file and line
+}
+
+// Clear ends the repeats of key, typically once the failing operation succeeds again, so the next failure
+// is logged even if it's identical to the last one
+func (t *LogThrottle) Clear(key string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.flush(t.messages[key])
+	delete(t.messages, key)
+}
+
+// flush logs how often a collapsed message repeated, if it did. The caller must hold mutex and be called
+// directly by Printf or Clear, so the file and line are those of their caller.
+func (t *LogThrottle) flush(last *throttledMessage) {
+	if last == nil || last.repeats == 0 {
+		return
+	}
+	t.logger.Output(3, fmt.Sprintf("%s (repeated %d times)", last.message, last.repeats))
+}
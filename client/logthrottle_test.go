@@ -0,0 +1,61 @@
+package client
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// loggedLines returns the lines written to buffer, without the empty last one
+func loggedLines(buffer *bytes.Buffer) []string {
+	return strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+}
+
+func TestLogThrottleCollapsesRepeats(t *testing.T) {
+	var buffer bytes.Buffer
+	throttle := NewLogThrottle(log.New(&buffer, "", 0), time.Hour)
+
+	for i := 0; i < 5; i++ {
+		throttle.Printf("signal-check", "Signal check failed: %s", "timeout")
+	}
+	throttle.Printf("spread", "Spread sample failed") // Other keys don't end the repeats
+	throttle.Printf("signal-check", "Signal check failed: %s", "HTTP 503")
+	throttle.Printf("signal-check", "Signal check failed: %s", "HTTP 503")
+	throttle.Clear("signal-check")
+	throttle.Printf("signal-check", "Signal check failed: %s", "HTTP 503")
+
+	want := []string{
+		"Signal check failed: timeout",
+		"Spread sample failed",
+		"Signal check failed: timeout (repeated 4 times)",
+		"Signal check failed: HTTP 503",
+		"Signal check failed: HTTP 503 (repeated 1 times)",
+		"Signal check failed: HTTP 503",
+	}
+	lines := loggedLines(&buffer)
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("logged:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLogThrottleLogsAgainAfterTheWindow(t *testing.T) {
+	var buffer bytes.Buffer
+	throttle := NewLogThrottle(log.New(&buffer, "", 0), 20*time.Millisecond)
+
+	throttle.Printf("signal-check", "Signal check failed")
+	throttle.Printf("signal-check", "Signal check failed")
+	time.Sleep(30 * time.Millisecond)
+	throttle.Printf("signal-check", "Signal check failed")
+	throttle.Clear("signal-check") // Nothing repeated since: nothing to flush
+
+	want := []string{
+		"Signal check failed",
+		"Signal check failed (repeated 1 times)",
+		"Signal check failed",
+	}
+	if lines := loggedLines(&buffer); strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("logged:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/time/rate"
+)
+
+// ClientManager holds one CoinbaseClient per configured trading pair
+type ClientManager struct {
+	clients map[string]*CoinbaseClient
+	pairs   []string // Configured order, the first pair is the default
+}
+
+// NewClientManager creates a Coinbase client for each trading pair
+func NewClientManager(tradingPairs []string, webhookURL string, webhookMaxRetries int, webhookTimeout int) (*ClientManager, error) {
+	if len(tradingPairs) == 0 {
+		return nil, fmt.Errorf("at least one trading pair is required")
+	}
+
+	manager := &ClientManager{
+		clients: make(map[string]*CoinbaseClient, len(tradingPairs)),
+	}
+
+	// Coinbase rate limits per API key, so all pair clients share one limiter
+	var sharedLimiter *rate.Limiter
+
+	for _, pair := range tradingPairs {
+		pair = strings.ToUpper(strings.TrimSpace(pair))
+		if _, exists := manager.clients[pair]; exists {
+			continue // Ignore duplicate pairs
+		}
+
+		client, err := NewCoinbaseClient(pair, webhookURL, webhookMaxRetries, webhookTimeout)
+		if err != nil {
+			manager.Close()
+			return nil, fmt.Errorf("failed to create client for %s: %w", pair, err)
+		}
+
+		if sharedLimiter == nil {
+			sharedLimiter = client.rateLimiter
+		}
+		client.rateLimiter = sharedLimiter
+
+		manager.clients[pair] = client
+		manager.pairs = append(manager.pairs, pair)
+	}
+
+	return manager, nil
+}
+
+// Get returns the client for a trading pair, or the default client when pair is empty
+func (m *ClientManager) Get(pair string) (*CoinbaseClient, error) {
+	if pair == "" {
+		return m.Default(), nil
+	}
+
+	client, exists := m.clients[strings.ToUpper(pair)]
+	if !exists {
+		return nil, fmt.Errorf("trading pair %s is not configured (available: %s)", pair, strings.Join(m.pairs, ", "))
+	}
+	return client, nil
+}
+
+// Default returns the client for the first configured trading pair
+func (m *ClientManager) Default() *CoinbaseClient {
+	return m.clients[m.pairs[0]]
+}
+
+// Pairs returns the configured trading pairs in order
+func (m *ClientManager) Pairs() []string {
+	result := make([]string, len(m.pairs))
+	copy(result, m.pairs)
+	return result
+}
+
+// Clients returns all clients in the configured pair order
+func (m *ClientManager) Clients() []*CoinbaseClient {
+	result := make([]*CoinbaseClient, 0, len(m.pairs))
+	for _, pair := range m.pairs {
+		result = append(result, m.clients[pair])
+	}
+	return result
+}
+
+// Drain stops every client from accepting new trades, then waits for the trades in flight on all of them,
+// or for ctx to end
+func (m *ClientManager) Drain(ctx context.Context) error {
+	for _, client := range m.clients {
+		client.stopTrades()
+	}
+	for _, pair := range m.pairs {
+		if err := m.clients[pair].waitTrades(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes every client in configured order, even if some fail, and returns their errors joined
+func (m *ClientManager) Close() error {
+	var errs []error
+	for _, pair := range m.pairs {
+		if err := m.clients[pair].Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s client: %w", pair, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,116 @@
+package client
+
+import "time"
+
+// defaultMarketCacheTTL is how long market state and product stats are reused before being fetched again
+const defaultMarketCacheTTL = 5 * time.Second
+
+// cachedMarketState is a market state snapshot for one order book depth
+type cachedMarketState struct {
+	state     *MarketState
+	fetchedAt time.Time
+}
+
+// GetMarketState retrieves comprehensive market state information, reusing a snapshot of the same depth
+// fetched less than MARKET_CACHE_TTL ago. The fetch runs outside the cache lock so one slow order book
+// request doesn't hold up the other depths and pairs' cache hits.
+func (c *CoinbaseClient) GetMarketState(limit int) (*MarketState, error) {
+	return c.marketState(limit, false)
+}
+
+// GetFreshMarketState fetches the market state even when a cached snapshot is still valid, and refreshes
+// that snapshot without dropping the other cached entries
+func (c *CoinbaseClient) GetFreshMarketState(limit int) (*MarketState, error) {
+	return c.marketState(limit, true)
+}
+
+func (c *CoinbaseClient) marketState(limit int, fresh bool) (*MarketState, error) {
+	c.marketCacheMutex.Lock()
+	if cached, ok := c.marketStateCache[limit]; ok && !fresh && time.Since(cached.fetchedAt) < c.marketCacheTTL {
+		state := copyMarketState(cached.state)
+		c.marketCacheMutex.Unlock()
+		if c.debug {
+			c.logger.Printf("Market state cache hit for %s (limit %d)", c.tradingPair, limit)
+		}
+		return state, nil
+	}
+	generation := c.marketCacheGeneration
+	c.marketCacheMutex.Unlock()
+
+	state, err := c.fetchMarketState(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	c.marketCacheMutex.Lock()
+	defer c.marketCacheMutex.Unlock()
+	// An order placed during the fetch invalidated the cache: return this state but don't keep it
+	if generation == c.marketCacheGeneration {
+		if c.marketStateCache == nil {
+			c.marketStateCache = make(map[int]cachedMarketState)
+		}
+		c.marketStateCache[limit] = cachedMarketState{state: state, fetchedAt: time.Now()}
+	}
+
+	return copyMarketState(state), nil
+}
+
+// copyMarketState returns a copy of state that shares nothing with it, order book entries included,
+// so callers can't modify a cached snapshot
+func copyMarketState(state *MarketState) *MarketState {
+	copied := *state
+	copied.OrderBook.Bids = append([]OrderBookEntry(nil), state.OrderBook.Bids...)
+	copied.OrderBook.Asks = append([]OrderBookEntry(nil), state.OrderBook.Asks...)
+	return &copied
+}
+
+// GetProductStats retrieves price, 24h open/high/low, volume and percent change for the configured trading pair,
+// reusing the stats fetched less than MARKET_CACHE_TTL ago
+func (c *CoinbaseClient) GetProductStats() (*ProductStats, error) {
+	return c.productStats(false)
+}
+
+// GetFreshProductStats fetches the product stats even when the cached ones are still valid, and refreshes them
+// without dropping the cached market state
+func (c *CoinbaseClient) GetFreshProductStats() (*ProductStats, error) {
+	return c.productStats(true)
+}
+
+func (c *CoinbaseClient) productStats(fresh bool) (*ProductStats, error) {
+	c.marketCacheMutex.Lock()
+	if c.productStatsCache != nil && !fresh && time.Since(c.productStatsFetchedAt) < c.marketCacheTTL {
+		stats := *c.productStatsCache
+		c.marketCacheMutex.Unlock()
+		if c.debug {
+			c.logger.Printf("Product stats cache hit for %s", c.tradingPair)
+		}
+		return &stats, nil
+	}
+	generation := c.marketCacheGeneration
+	c.marketCacheMutex.Unlock()
+
+	stats, err := c.fetchProductStats()
+	if err != nil {
+		return nil, err
+	}
+
+	c.marketCacheMutex.Lock()
+	defer c.marketCacheMutex.Unlock()
+	if generation == c.marketCacheGeneration {
+		c.productStatsCache = stats
+		c.productStatsFetchedAt = time.Now()
+	}
+
+	cached := *stats
+	return &cached, nil
+}
+
+// InvalidateMarketCache drops the cached market state and product stats so the next call fetches them again
+func (c *CoinbaseClient) InvalidateMarketCache() {
+	c.marketCacheMutex.Lock()
+	defer c.marketCacheMutex.Unlock()
+
+	c.marketStateCache = nil
+	c.productStatsCache = nil
+	c.marketCacheGeneration++
+}
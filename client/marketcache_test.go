@@ -0,0 +1,160 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetMarketStateCache(t *testing.T) {
+	fake := newFakeCoinbase()
+	c := newTestClient(t, fake)
+	c.marketCacheTTL = time.Minute
+
+	first, err := c.GetMarketState(10)
+	if err != nil {
+		t.Fatalf("GetMarketState: %v", err)
+	}
+	if first.BestBid != "50000" || first.BestAsk != "50010" {
+		t.Fatalf("best bid/ask = %s/%s, want 50000/50010", first.BestBid, first.BestAsk)
+	}
+
+	// A caller modifying its copy must not reach the cached snapshot
+	first.OrderBook.Bids[0].Price = "1"
+	first.OrderBook.Asks = append(first.OrderBook.Asks[:0], OrderBookEntry{Price: "2"})
+
+	second, err := c.GetMarketState(10)
+	if err != nil {
+		t.Fatalf("GetMarketState: %v", err)
+	}
+	if calls := fake.called("GET /product_book"); calls != 1 {
+		t.Errorf("order book fetched %d times, want 1 (second call is a hit)", calls)
+	}
+	if second.OrderBook.Bids[0].Price != "50000" || second.OrderBook.Asks[0].Price != "50010" {
+		t.Errorf("cached order book = %v, modified through an earlier result", second.OrderBook)
+	}
+
+	// Another depth is its own entry
+	if _, err := c.GetMarketState(50); err != nil {
+		t.Fatalf("GetMarketState: %v", err)
+	}
+	if calls := fake.called("GET /product_book"); calls != 2 {
+		t.Errorf("order book fetched %d times, want 2 (other depth is a miss)", calls)
+	}
+
+	// Placing an order drops the cache
+	c.InvalidateMarketCache()
+	if _, err := c.GetMarketState(10); err != nil {
+		t.Fatalf("GetMarketState: %v", err)
+	}
+	if calls := fake.called("GET /product_book"); calls != 3 {
+		t.Errorf("order book fetched %d times, want 3 (miss after invalidation)", calls)
+	}
+}
+
+func TestGetMarketStateDoesNotCacheAcrossInvalidation(t *testing.T) {
+	fake := newFakeCoinbase()
+	var c *CoinbaseClient
+	invalidated := false
+	c = newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// An order placed while the first order book request is in flight
+		if strings.HasSuffix(r.URL.Path, "/product_book") && !invalidated {
+			invalidated = true
+			c.InvalidateMarketCache()
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	c.marketCacheTTL = time.Minute
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetMarketState(10); err != nil {
+			t.Fatalf("GetMarketState: %v", err)
+		}
+	}
+	if calls := fake.called("GET /product_book"); calls != 2 {
+		t.Errorf("order book fetched %d times, want 2 (state fetched across an invalidation isn't cached)", calls)
+	}
+	if _, err := c.GetMarketState(10); err != nil {
+		t.Fatalf("GetMarketState: %v", err)
+	}
+	if calls := fake.called("GET /product_book"); calls != 2 {
+		t.Errorf("order book fetched %d times, want 2 (next fetch is cached)", calls)
+	}
+}
+
+func TestFreshFetchKeepsTheOtherCacheEntries(t *testing.T) {
+	fake := newFakeCoinbase()
+	c := newTestClient(t, fake)
+	c.marketCacheTTL = time.Minute
+
+	for _, limit := range []int{10, 50} {
+		if _, err := c.GetMarketState(limit); err != nil {
+			t.Fatalf("GetMarketState: %v", err)
+		}
+	}
+	if _, err := c.GetProductStats(); err != nil {
+		t.Fatalf("GetProductStats: %v", err)
+	}
+	books := fake.called("GET /product_book")
+
+	// A fresh read goes to Coinbase even though its entry is still valid...
+	if _, err := c.GetFreshMarketState(10); err != nil {
+		t.Fatalf("GetFreshMarketState: %v", err)
+	}
+	if calls := fake.called("GET /product_book"); calls != books+1 {
+		t.Errorf("order book fetched %d times, want %d (fresh read skips the cache)", calls, books+1)
+	}
+	products := fake.called("GET /products/BTC-USDC") // The market state reads the product too
+
+	// ...without dropping what other requests share
+	if _, err := c.GetMarketState(50); err != nil {
+		t.Fatalf("GetMarketState: %v", err)
+	}
+	if _, err := c.GetProductStats(); err != nil {
+		t.Fatalf("GetProductStats: %v", err)
+	}
+	if calls := fake.called("GET /product_book"); calls != books+1 {
+		t.Errorf("order book fetched %d times, want %d (other depth still cached)", calls, books+1)
+	}
+	if calls := fake.called("GET /products/BTC-USDC"); calls != products {
+		t.Errorf("product fetched %d times, want %d (product stats still cached)", calls, products)
+	}
+
+	// Fresh product stats leave the market state cached
+	if _, err := c.GetFreshProductStats(); err != nil {
+		t.Fatalf("GetFreshProductStats: %v", err)
+	}
+	if _, err := c.GetMarketState(10); err != nil {
+		t.Fatalf("GetMarketState: %v", err)
+	}
+	if calls := fake.called("GET /products/BTC-USDC"); calls <= products {
+		t.Errorf("product fetched %d times, want more than %d (fresh read skips the cache)", calls, products)
+	}
+	if calls := fake.called("GET /product_book"); calls != books+1 {
+		t.Errorf("order book fetched %d times, want %d (market state still cached)", calls, books+1)
+	}
+}
+
+func TestMarketStateWithAZeroBid(t *testing.T) {
+	for _, bid := range []string{"0", ""} {
+		fake := newFakeCoinbase()
+		fake.bid = bid
+		c := newTestClient(t, fake)
+
+		state, err := c.GetMarketState(1)
+		if err != nil {
+			t.Fatalf("bid %q: GetMarketState: %v", bid, err)
+		}
+		if state.Spread != "" || state.SpreadPercent != "" {
+			t.Errorf("bid %q: spread %q (%q%%), want none", bid, state.Spread, state.SpreadPercent)
+		}
+		if _, err := json.Marshal(state); err != nil {
+			t.Errorf("bid %q: market state doesn't serialize: %v", bid, err)
+		}
+		if history := c.GetSpreadHistory(); history.Count != 0 {
+			t.Errorf("bid %q: recorded %d spread samples, want none", bid, history.Count)
+		}
+	}
+}
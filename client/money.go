@@ -0,0 +1,48 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// moneyDecimals is the precision money amounts are serialized with: one satoshi, more than any quote currency uses
+const moneyDecimals = 8
+
+// Money is an amount of a currency (price, size, value, fee, balance or P&L) computed by the API. It is an
+// exact decimal, so amounts computed from Coinbase's decimal strings are not rounded through float64 on the way.
+//
+// Numeric serialization policy: money amounts serialize as decimal strings, like the amounts passed through
+// from Coinbase (orders, balances, candles, trades), so consumers can parse them without float rounding.
+// They are rounded to 8 decimals with trailing zeros dropped, always with a dot, whatever the locale.
+// Ratios, percentages, indicator values, scores and counts stay JSON numbers, as do point series meant for
+// plotting (chart candles, indicator and asset value series). Request fields of this type accept either form.
+type Money struct {
+	decimal.Decimal
+}
+
+// moneyFromFloat converts an amount only available as a float64 (indicator prices, asset value samples, spreads)
+func moneyFromFloat(amount float64) Money {
+	return Money{decimal.NewFromFloat(amount)}
+}
+
+// MarshalJSON encodes the amount as a decimal string, e.g. "45000.5"
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON accepts a decimal string or a JSON number
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var amount decimal.Decimal
+	if err := amount.UnmarshalJSON(data); err != nil {
+		return fmt.Errorf("invalid amount %s: %w", data, err)
+	}
+	m.Decimal = amount
+	return nil
+}
+
+// String formats the amount as it is serialized
+func (m Money) String() string {
+	return m.Round(moneyDecimals).String()
+}
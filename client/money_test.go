@@ -0,0 +1,107 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestMoneyMarshalJSON(t *testing.T) {
+	tests := []struct {
+		amount Money
+		want   string
+	}{
+		{Money{decimal.RequireFromString("45000.5")}, `"45000.5"`},
+		{Money{decimal.NewFromInt(100)}, `"100"`},
+		{Money{}, `"0"`},
+		{moneyFromFloat(0.1 + 0.2), `"0.3"`}, // Float noise of a float source doesn't reach the output
+		{Money{decimal.RequireFromString("-12.345678912")}, `"-12.34567891"`}, // Rounded to 8 decimals
+		{Money{decimal.RequireFromString("0.000000004")}, `"0"`},              // Below one satoshi
+		{Money{decimal.RequireFromString("123456789.12345678")}, `"123456789.12345678"`},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.amount)
+		if err != nil {
+			t.Fatalf("Marshal(%v): %v", tt.amount.Decimal, err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("Marshal(%v) = %s, want %s", tt.amount.Decimal, data, tt.want)
+		}
+	}
+}
+
+func TestMoneyKeepsDecimalPrecision(t *testing.T) {
+	// Ten fees of 0.1 add up to exactly 1, where float64 drifts to 0.9999999999999999
+	total := Money{}
+	for i := 0; i < 10; i++ {
+		total = Money{total.Add(decimal.RequireFromString("0.1"))}
+	}
+	if !total.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("sum of ten 0.1 amounts = %v, want exactly 1", total.Decimal)
+	}
+
+	// Amounts beyond float64's 15-16 significant digits round-trip unchanged
+	var amount Money
+	if err := json.Unmarshal([]byte(`"98765432.12345678"`), &amount); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if data, _ := json.Marshal(amount); string(data) != `"98765432.12345678"` {
+		t.Errorf("round trip = %s, want \"98765432.12345678\"", data)
+	}
+}
+
+func TestMoneyFieldsSerializeAsStrings(t *testing.T) {
+	data, err := json.Marshal(Summary{ProductID: "BTC-USDC", Price: moneyFromFloat(45000.5), RSI: 55.25, PortfolioUSD: moneyFromFloat(12345.67)})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	// Money amounts are strings, indicator values stay numbers
+	if fields["price"] != "45000.5" || fields["portfolio_usd"] != "12345.67" {
+		t.Errorf("price, portfolio_usd = %#v, %#v, want strings", fields["price"], fields["portfolio_usd"])
+	}
+	if fields["rsi"] != 55.25 {
+		t.Errorf("rsi = %#v, want the number 55.25", fields["rsi"])
+	}
+}
+
+func TestZeroMoneyFieldsAreOmitted(t *testing.T) {
+	// A no-op rebalance has no trade: price, value and fee are left out rather than sent as "0"
+	data, err := json.Marshal(RebalancePlan{TotalValue: moneyFromFloat(60000)})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	for _, name := range []string{"price", "value", "fee"} {
+		if value, ok := fields[name]; ok {
+			t.Errorf("%s = %#v, want it omitted", name, value)
+		}
+	}
+	if fields["total_value"] != "60000" {
+		t.Errorf("total_value = %#v, want \"60000\"", fields["total_value"])
+	}
+}
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	for _, input := range []string{`"45000.5"`, `45000.5`} {
+		var amount Money
+		if err := json.Unmarshal([]byte(input), &amount); err != nil {
+			t.Errorf("Unmarshal(%s): %v", input, err)
+		} else if !amount.Equal(decimal.RequireFromString("45000.5")) {
+			t.Errorf("Unmarshal(%s) = %v, want 45000.5", input, amount.Decimal)
+		}
+	}
+
+	var amount Money
+	if err := json.Unmarshal([]byte(`"45,000.5"`), &amount); err == nil {
+		t.Error("Unmarshal of a localized amount did not fail")
+	}
+}
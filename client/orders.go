@@ -3,13 +3,14 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // CoinbaseOrder represents the raw order response from Coinbase API
@@ -22,15 +23,35 @@ type CoinbaseOrder struct {
 	FilledSize         string `json:"filled_size"`
 	FilledValue        string `json:"filled_value"`
 	AverageFilledPrice string `json:"average_filled_price"`
+	TotalFees          string `json:"total_fees"`
 	OrderConfiguration struct {
 		LimitLimitGtc *struct {
 			BaseSize   string `json:"base_size"`
 			LimitPrice string `json:"limit_price"`
+			PostOnly   bool   `json:"post_only"`
 		} `json:"limit_limit_gtc,omitempty"`
+		LimitLimitIoc *struct {
+			BaseSize   string `json:"base_size"`
+			LimitPrice string `json:"limit_price"`
+		} `json:"limit_limit_ioc,omitempty"`
+		SorLimitIoc *struct {
+			BaseSize   string `json:"base_size"`
+			LimitPrice string `json:"limit_price"`
+		} `json:"sor_limit_ioc,omitempty"`
+		MarketMarketIoc *struct {
+			BaseSize  string `json:"base_size"`
+			QuoteSize string `json:"quote_size"`
+		} `json:"market_market_ioc,omitempty"`
+		StopLimitStopLimitGtc *struct {
+			BaseSize      string `json:"base_size"`
+			LimitPrice    string `json:"limit_price"`
+			StopPrice     string `json:"stop_price"`
+			StopDirection string `json:"stop_direction"`
+		} `json:"stop_limit_stop_limit_gtc,omitempty"`
 	} `json:"order_configuration"`
 }
 
-// CreateOrderRequest represents the request for creating orders
+// CoinbaseCreateOrderRequest is the Coinbase wire format of an order request, the only order request type
 type CoinbaseCreateOrderRequest struct {
 	ProductID          string `json:"product_id"`
 	Side               string `json:"side"`
@@ -39,14 +60,87 @@ type CoinbaseCreateOrderRequest struct {
 		LimitLimitGtc *struct {
 			BaseSize   string `json:"base_size"`
 			LimitPrice string `json:"limit_price"`
+			PostOnly   bool   `json:"post_only"`
 		} `json:"limit_limit_gtc,omitempty"`
 		LimitLimitIoc *struct {
 			BaseSize   string `json:"base_size"`
 			LimitPrice string `json:"limit_price"`
 		} `json:"limit_limit_ioc,omitempty"`
+		SorLimitIoc *struct {
+			BaseSize   string `json:"base_size"`
+			LimitPrice string `json:"limit_price"`
+		} `json:"sor_limit_ioc,omitempty"`
 	} `json:"order_configuration"`
 }
 
+// defaultOrderListLimit is the number of orders requested when no limit is given
+const defaultOrderListLimit = 100
+
+// OrderListOptions filters the orders returned by ListOrders
+type OrderListOptions struct {
+	Status      string // OPEN (default), FILLED, CANCELLED or ALL
+	Side        string // BUY, SELL or empty for both
+	AllProducts bool   // Return orders for every product instead of only the trading pair
+	Limit       int    // Maximum number of orders (0 uses the default of 100)
+}
+
+// OrderOptions holds optional flags applied when placing an order
+type OrderOptions struct {
+	// PostOnly rejects the order instead of letting it take liquidity (GTC only)
+	PostOnly bool
+	// ClientOrderID is sent to Coinbase, which dedupes on it; a UUID is generated when empty
+	ClientOrderID string
+	// ImmediateOrCancel places a sor_limit_ioc order: whatever doesn't fill at once is cancelled
+	ImmediateOrCancel bool
+	// ReduceOnly rejects a sell larger than the available base balance. Coinbase spot orders have no
+	// reduce_only field, so this is checked here before the order is submitted.
+	ReduceOnly bool
+}
+
+// ErrPostOnlyWouldCross is returned when Coinbase rejects a post-only order because it would match immediately
+var ErrPostOnlyWouldCross = errors.New("post-only order would cross the book")
+
+// ErrOrderNotionalExceeded is returned when an order's size*price exceeds MAX_ORDER_NOTIONAL_USD
+var ErrOrderNotionalExceeded = errors.New("order notional exceeds configured maximum")
+
+// ErrSpreadTooWide is returned when the bid/ask spread exceeds MAX_SPREAD_BPS at order time
+var ErrSpreadTooWide = errors.New("spread too wide to trade")
+
+// ErrOrderAlreadyFilled is returned when an order filled before it could be replaced
+var ErrOrderAlreadyFilled = errors.New("order already filled")
+
+// ErrOrderPartiallyFilled is returned when an order was cancelled for a replace after filling part of its size.
+// It is wrapped in a *PartialFillError telling how much filled.
+var ErrOrderPartiallyFilled = errors.New("order partially filled")
+
+// PartialFillError reports an order that filled part of its size before it was cancelled for a replace;
+// the unfilled remainder is cancelled and no replacement is placed
+type PartialFillError struct {
+	OrderID    string
+	FilledSize string
+}
+
+func (e *PartialFillError) Error() string {
+	return fmt.Sprintf("%v: %s filled %s before it was cancelled, no replacement placed", ErrOrderPartiallyFilled, e.OrderID, e.FilledSize)
+}
+
+func (e *PartialFillError) Unwrap() error {
+	return ErrOrderPartiallyFilled
+}
+
+// ErrOrderNotOpen is returned when an order is no longer open (cancelled, expired, failed)
+var ErrOrderNotOpen = errors.New("order is not open")
+
+// ErrInsufficientFunds is returned when Coinbase rejects an order because the balance can't cover it
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// ErrReduceOnlyExceedsBalance is returned when a reduce-only sell is larger than the available base balance
+var ErrReduceOnlyExceedsBalance = errors.New("reduce-only sell exceeds available balance")
+
+// ErrCancelRejected is returned when Coinbase accepts a cancel request but reports the order as not cancelled
+// (e.g. it already filled)
+var ErrCancelRejected = errors.New("cancel rejected")
+
 // CreateOrderResponse represents the response from creating an order
 type CreateOrderResponse struct {
 	OrderID string `json:"order_id"`
@@ -57,32 +151,116 @@ type OrdersResponse struct {
 	Orders []CoinbaseOrder `json:"orders"`
 }
 
+// BatchCancelResponse represents the response from the batch_cancel endpoint
+type BatchCancelResponse struct {
+	Results []struct {
+		Success       bool   `json:"success"`
+		FailureReason string `json:"failure_reason"`
+		OrderID       string `json:"order_id"`
+	} `json:"results"`
+}
+
+// parseDecimal parses a Coinbase numeric string, treating empty or invalid values as zero
+func parseDecimal(value string) decimal.Decimal {
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero
+	}
+	return d
+}
+
+// defaultBaseIncrement is the size increment used when the product's base_increment is unavailable
+var defaultBaseIncrement = decimal.New(1, -8)
+
+// floorToIncrement rounds value down to a whole multiple of increment.
+// Order sizes are always floored (never rounded up) so a computed size can't exceed available funds.
+func floorToIncrement(value, increment decimal.Decimal) decimal.Decimal {
+	if !increment.IsPositive() {
+		increment = defaultBaseIncrement
+	}
+	return value.Div(increment).Floor().Mul(increment)
+}
+
+// baseIncrement returns the trading pair's base size increment, falling back to 8 decimal places
+func (c *CoinbaseClient) baseIncrement() decimal.Decimal {
+	product, err := c.getProduct()
+	if err != nil {
+		if c.debug {
+			c.logger.Printf("Could not fetch base increment, using %s: %v", defaultBaseIncrement, err)
+		}
+		return defaultBaseIncrement
+	}
+
+	increment, err := decimal.NewFromString(product.BaseIncrement)
+	if err != nil || !increment.IsPositive() {
+		return defaultBaseIncrement
+	}
+	return increment
+}
+
+// defaultQuoteIncrement is the price increment used when the product's quote_increment is unavailable
+var defaultQuoteIncrement = decimal.New(1, -2)
+
+// quoteIncrement returns the trading pair's price increment, falling back to 2 decimal places
+func (c *CoinbaseClient) quoteIncrement() decimal.Decimal {
+	product, err := c.getProduct()
+	if err != nil {
+		if c.debug {
+			c.logger.Printf("Could not fetch quote increment, using %s: %v", defaultQuoteIncrement, err)
+		}
+		return defaultQuoteIncrement
+	}
+
+	increment, err := decimal.NewFromString(product.QuoteIncrement)
+	if err != nil || !increment.IsPositive() {
+		return defaultQuoteIncrement
+	}
+	return increment
+}
+
+// checkOrderNotional rejects orders whose notional (size * price) exceeds the configured cap
+func (c *CoinbaseClient) checkOrderNotional(side string, size, price decimal.Decimal) error {
+	if !c.maxOrderNotional.IsPositive() {
+		return nil
+	}
+
+	notional := size.Mul(price)
+	if notional.GreaterThan(c.maxOrderNotional) {
+		c.logger.Printf("[WARN] Rejected %s order: notional %s exceeds MAX_ORDER_NOTIONAL_USD %s",
+			side, notional.StringFixed(2), c.maxOrderNotional.StringFixed(2))
+		return fmt.Errorf("%w: %s order notional %s > %s", ErrOrderNotionalExceeded,
+			side, notional.StringFixed(2), c.maxOrderNotional.StringFixed(2))
+	}
+	return nil
+}
+
 // calculateCoinbaseFee calculates the total fee for a given trade amount
-func (c *CoinbaseClient) calculateCoinbaseFee(tradeAmount float64) float64 {
+func (c *CoinbaseClient) calculateCoinbaseFee(tradeAmount decimal.Decimal) decimal.Decimal {
 	// 0.50% spread per transaction
-	spreadFee := tradeAmount * 0.005
+	spreadFee := tradeAmount.Mul(decimal.RequireFromString("0.005"))
 
 	// Flat fee based on trade amount
-	var flatFee float64
+	var flatFee decimal.Decimal
 	switch {
-	case tradeAmount <= 10:
-		flatFee = 0.99
-	case tradeAmount <= 25:
-		flatFee = 1.49
-	case tradeAmount <= 50:
-		flatFee = 1.99
-	case tradeAmount <= 200:
-		flatFee = 2.99
+	case tradeAmount.LessThanOrEqual(decimal.NewFromInt(10)):
+		flatFee = decimal.RequireFromString("0.99")
+	case tradeAmount.LessThanOrEqual(decimal.NewFromInt(25)):
+		flatFee = decimal.RequireFromString("1.49")
+	case tradeAmount.LessThanOrEqual(decimal.NewFromInt(50)):
+		flatFee = decimal.RequireFromString("1.99")
+	case tradeAmount.LessThanOrEqual(decimal.NewFromInt(200)):
+		flatFee = decimal.RequireFromString("2.99")
 	default:
 		// Trades over $200 incur a 1.49% fee
-		flatFee = tradeAmount * 0.0149
+		flatFee = tradeAmount.Mul(decimal.RequireFromString("0.0149"))
 	}
 
-	return spreadFee + flatFee
+	return spreadFee.Add(flatFee)
 }
 
 // CalculateOrderSizeByPercentage calculates the order size based on a percentage of available balance
-// Includes actual Coinbase fees (0.50% spread + tiered flat fees) to ensure the order can be placed successfully
+// Includes actual Coinbase fees (0.50% spread + tiered flat fees) to ensure the order can be placed successfully.
+// The resulting size is floored to the product's base increment so it never rounds up past available funds.
 func (c *CoinbaseClient) CalculateOrderSizeByPercentage(side string, percentage float64, price string) (string, error) {
 	// Validate percentage
 	if percentage <= 0 || percentage > 100 {
@@ -94,7 +272,7 @@ func (c *CoinbaseClient) CalculateOrderSizeByPercentage(side string, percentage
 		return "", fmt.Errorf("failed to fetch accounts: %w", err)
 	}
 
-	var availableBalance float64
+	var availableBalance decimal.Decimal
 	var currency string
 
 	if side == "BUY" {
@@ -108,74 +286,128 @@ func (c *CoinbaseClient) CalculateOrderSizeByPercentage(side string, percentage
 	// Find the required currency account
 	for _, account := range accounts {
 		if account.Currency == currency {
-			availableBalance, _ = strconv.ParseFloat(account.AvailableBalance, 64)
+			availableBalance = parseDecimal(account.AvailableBalance)
 			break
 		}
 	}
 
-	if availableBalance <= 0 {
+	if !availableBalance.IsPositive() {
 		return "", fmt.Errorf("no available %s balance", currency)
 	}
 
+	priceDec, err := decimal.NewFromString(price)
+	if err != nil {
+		return "", fmt.Errorf("invalid price format: %w", err)
+	}
+	if !priceDec.IsPositive() {
+		return "", fmt.Errorf("price must be greater than 0")
+	}
+
 	// Calculate the base amount to use based on percentage
-	baseAmount := availableBalance * (percentage / 100.0)
+	baseAmount := availableBalance.Mul(decimal.NewFromFloat(percentage)).Div(decimal.NewFromInt(100))
 
-	var orderSize float64
+	var orderSize decimal.Decimal
 	if side == "BUY" {
-		// For BUY orders, we need to calculate the trade value first
-		priceFloat, err := strconv.ParseFloat(price, 64)
-		if err != nil {
-			return "", fmt.Errorf("invalid price format: %w", err)
-		}
-		if priceFloat <= 0 {
-			return "", fmt.Errorf("price must be greater than 0")
-		}
-
-		// Calculate the maximum BTC we can buy with the available amount
-		maxBTC := baseAmount / priceFloat
-		tradeValue := maxBTC * priceFloat
+		// For BUY orders, the base amount is already the trade value in quote currency
+		tradeValue := baseAmount
 
 		// Calculate the fee for this trade
 		fee := c.calculateCoinbaseFee(tradeValue)
 
 		// Adjust the trade value to account for fees
-		adjustedTradeValue := tradeValue - fee
-		orderSize = adjustedTradeValue / priceFloat
+		adjustedTradeValue := tradeValue.Sub(fee)
+		orderSize = adjustedTradeValue.Div(priceDec)
 
 		// Log calculation details in debug mode
-		if os.Getenv("LOG_LEVEL") == "DEBUG" {
-			c.logger.Printf("BUY calculation: %.2f%% requested, base amount: %.8f %s, trade value: %.2f, fee: %.2f, adjusted trade value: %.2f, order size: %.8f BTC",
-				percentage, availableBalance, currency, tradeValue, fee, adjustedTradeValue, orderSize)
+		if c.debug {
+			c.logger.Printf("BUY calculation: %.2f%% requested, base amount: %s %s, trade value: %s, fee: %s, adjusted trade value: %s, order size: %s BTC",
+				percentage, availableBalance, currency, tradeValue.StringFixed(2), fee.StringFixed(2), adjustedTradeValue.StringFixed(2), orderSize.StringFixed(8))
 		}
 	} else {
-		// For SELL orders, calculate the trade value and adjust for fees
-		priceFloat, err := strconv.ParseFloat(price, 64)
-		if err != nil {
-			return "", fmt.Errorf("invalid price format: %w", err)
-		}
-		if priceFloat <= 0 {
-			return "", fmt.Errorf("price must be greater than 0")
-		}
-
 		// Calculate the trade value
-		tradeValue := baseAmount * priceFloat
+		tradeValue := baseAmount.Mul(priceDec)
 
 		// Calculate the fee for this trade
 		fee := c.calculateCoinbaseFee(tradeValue)
 
 		// Adjust the BTC amount to account for fees
-		adjustedBTC := baseAmount - (fee / priceFloat)
+		adjustedBTC := baseAmount.Sub(fee.Div(priceDec))
 		orderSize = adjustedBTC
 
 		// Log calculation details in debug mode
-		if os.Getenv("LOG_LEVEL") == "DEBUG" {
-			c.logger.Printf("SELL calculation: %.2f%% requested, base amount: %.8f %s, trade value: %.2f, fee: %.2f, adjusted BTC: %.8f, order size: %.8f BTC",
-				percentage, availableBalance, currency, tradeValue, fee, adjustedBTC, orderSize)
+		if c.debug {
+			c.logger.Printf("SELL calculation: %.2f%% requested, base amount: %s %s, trade value: %s, fee: %s, adjusted BTC: %s, order size: %s BTC",
+				percentage, availableBalance, currency, tradeValue.StringFixed(2), fee.StringFixed(2), adjustedBTC.StringFixed(8), orderSize.StringFixed(8))
+		}
+	}
+
+	// Round down to the base increment, then format to 8 decimal places (standard for crypto)
+	increment := c.baseIncrement()
+	orderSize = floorToIncrement(orderSize, increment)
+	if !orderSize.IsPositive() {
+		return "", fmt.Errorf("calculated order size is below the base increment %s", increment)
+	}
+	if err := c.checkOrderNotional(side, orderSize, priceDec); err != nil {
+		return "", err
+	}
+	return orderSize.StringFixed(8), nil
+}
+
+// checkReduceOnly rejects a reduce-only sell with ErrReduceOnlyExceedsBalance when its size is larger than
+// the available base balance, so it can only shrink the position
+func (c *CoinbaseClient) checkReduceOnly(side, size string) error {
+	if side != "SELL" {
+		return fmt.Errorf("reduce_only applies to sell orders only")
+	}
+
+	base, _, err := c.pairCurrencies()
+	if err != nil {
+		return err
+	}
+	accounts, err := c.GetAccounts()
+	if err != nil {
+		return fmt.Errorf("failed to check reduce-only balance: %w", err)
+	}
+	available := decimal.Zero
+	for _, account := range accounts {
+		if account.Currency == base {
+			available = parseDecimal(account.AvailableBalance)
+			break
 		}
 	}
 
-	// Format to 8 decimal places (standard for crypto)
-	return fmt.Sprintf("%.8f", orderSize), nil
+	if parseDecimal(size).GreaterThan(available) {
+		return fmt.Errorf("%w: selling %s %s, available %s", ErrReduceOnlyExceedsBalance, size, base, available.String())
+	}
+	return nil
+}
+
+// checkSpread rejects an order with ErrSpreadTooWide when the current spread exceeds MAX_SPREAD_BPS (disabled when unset)
+func (c *CoinbaseClient) checkSpread(side string) error {
+	if c.maxSpreadBps <= 0 {
+		return nil
+	}
+
+	orderBook, err := c.GetOrderBook(1)
+	if err != nil {
+		return fmt.Errorf("failed to check spread: %w", err)
+	}
+	if len(orderBook.Bids) == 0 || len(orderBook.Asks) == 0 {
+		return fmt.Errorf("%w: order book has no bid or ask", ErrSpreadTooWide)
+	}
+
+	bid, _ := strconv.ParseFloat(orderBook.Bids[0].Price, 64)
+	ask, _ := strconv.ParseFloat(orderBook.Asks[0].Price, 64)
+	if bid <= 0 || ask <= 0 {
+		return fmt.Errorf("%w: invalid bid/ask %s/%s", ErrSpreadTooWide, orderBook.Bids[0].Price, orderBook.Asks[0].Price)
+	}
+
+	spreadBps := (ask - bid) / ((ask + bid) / 2) * 10000
+	if spreadBps > c.maxSpreadBps {
+		c.logger.Printf("[WARN] Rejected %s order: spread %.2f bps exceeds MAX_SPREAD_BPS %.2f", side, spreadBps, c.maxSpreadBps)
+		return fmt.Errorf("%w: spread %.2f bps > %.2f bps", ErrSpreadTooWide, spreadBps, c.maxSpreadBps)
+	}
+	return nil
 }
 
 func (c *CoinbaseClient) checkBalance(side, size, price string) error {
@@ -186,19 +418,16 @@ func (c *CoinbaseClient) checkBalance(side, size, price string) error {
 	}
 
 	// Calculate required amount
-	var requiredAmount float64
+	var requiredAmount decimal.Decimal
 	var requiredCurrency string
 
 	if side == "BUY" {
 		// For BUY orders, we need quote currency (e.g., USDC)
-		sizeFloat, _ := strconv.ParseFloat(size, 64)
-		priceFloat, _ := strconv.ParseFloat(price, 64)
-		requiredAmount = sizeFloat * priceFloat
+		requiredAmount = parseDecimal(size).Mul(parseDecimal(price))
 		requiredCurrency = strings.Split(c.tradingPair, "-")[1] // Quote currency
 	} else {
 		// For SELL orders, we need base currency (e.g., BTC)
-		sizeFloat, _ := strconv.ParseFloat(size, 64)
-		requiredAmount = sizeFloat
+		requiredAmount = parseDecimal(size)
 		requiredCurrency = strings.Split(c.tradingPair, "-")[0] // Base currency
 	}
 
@@ -216,23 +445,69 @@ func (c *CoinbaseClient) checkBalance(side, size, price string) error {
 		return nil
 	}
 
-	availableBalance, _ := strconv.ParseFloat(requiredAccount.AvailableBalance, 64)
-	if availableBalance < requiredAmount {
-		shortfall := requiredAmount - availableBalance
-		return fmt.Errorf("insufficient %s balance: need %.8f, have %.8f (shortfall: %.8f)",
-			requiredCurrency, requiredAmount, availableBalance, shortfall)
+	availableBalance := parseDecimal(requiredAccount.AvailableBalance)
+	if availableBalance.LessThan(requiredAmount) {
+		shortfall := requiredAmount.Sub(availableBalance)
+		return fmt.Errorf("insufficient %s balance: need %s, have %s (shortfall: %s)",
+			requiredCurrency, requiredAmount.StringFixed(8), availableBalance.StringFixed(8), shortfall.StringFixed(8))
 	}
 	return nil
 }
 
-// createOrder is a helper function to create market orders
-func (c *CoinbaseClient) createOrder(side, size string, price float64) (*Order, error) {
+// createOrder is a helper function to create market orders. With RETRY_SHRINK_ON_INSUFFICIENT, an order
+// Coinbase rejects for insufficient funds (usually fee rounding at the edge of the balance) is placed once
+// more with its size reduced by RETRY_SHRINK_PCT.
+func (c *CoinbaseClient) createOrder(side, size string, price float64, opts OrderOptions) (*Order, error) {
+	order, err := c.placeOrder(side, size, price, opts)
+	if !c.retryShrinkOnInsufficient || !errors.Is(err, ErrInsufficientFunds) {
+		return order, err
+	}
+
+	factor := decimal.NewFromInt(1).Sub(decimal.NewFromFloat(c.retryShrinkPct).Div(decimal.NewFromInt(100)))
+	shrunk := floorToIncrement(parseDecimal(size).Mul(factor), c.baseIncrement())
+	if !shrunk.IsPositive() {
+		return nil, err
+	}
+
+	// A fresh client order ID, the rejected one may be remembered by Coinbase
+	if opts.ClientOrderID != "" {
+		opts.ClientOrderID += "-shrink"
+	}
+	c.logger.Printf("[WARN] %s order rejected for insufficient funds, retrying once with size %s instead of %s (-%.2f%%)",
+		side, shrunk.String(), size, c.retryShrinkPct)
+	return c.placeOrder(side, shrunk.String(), price, opts)
+}
+
+// placeOrder places a single limit order (GTC, or IOC) and polls its status
+func (c *CoinbaseClient) placeOrder(side, size string, price float64, opts OrderOptions) (*Order, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	orderType := "LIMIT_GTC"
+	if opts.ImmediateOrCancel {
+		orderType = "LIMIT_IOC"
+	}
+
 	// Log order placement in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
-		c.logger.Printf("Placing %s GTC order: size=%s, price=%.8f", side, size, price)
+	if c.debug {
+		c.logger.Printf("Placing %s %s order: size=%s, price=%.8f, post_only=%t", side, orderType, size, price, opts.PostOnly)
+	}
+
+	// Reduce-only sells may never exceed the position
+	if opts.ReduceOnly {
+		if err := c.checkReduceOnly(side, size); err != nil {
+			return nil, err
+		}
+	}
+
+	// Enforce the notional safety cap before anything reaches Coinbase
+	if err := c.checkOrderNotional(side, parseDecimal(size), decimal.NewFromFloat(price)); err != nil {
+		return nil, err
+	}
+
+	// Refuse to trade into an abnormally wide (illiquid) book
+	if err := c.checkSpread(side); err != nil {
+		return nil, err
 	}
 
 	// Check balance if possible
@@ -240,8 +515,12 @@ func (c *CoinbaseClient) createOrder(side, size string, price float64) (*Order,
 		c.logger.Printf("Warning: Could not check balance: %v", err)
 	}
 
-	// Generate a unique client order ID
-	clientOrderID := uuid.New().String()
+	// Use the caller's idempotency key if provided so retries don't place duplicate orders,
+	// otherwise generate a unique client order ID
+	clientOrderID := opts.ClientOrderID
+	if clientOrderID == "" {
+		clientOrderID = uuid.New().String()
+	}
 
 	orderReq := CoinbaseCreateOrderRequest{
 		ProductID:     c.tradingPair,
@@ -250,13 +529,25 @@ func (c *CoinbaseClient) createOrder(side, size string, price float64) (*Order,
 	}
 
 	// Configure market order with GTC (Good Till Cancelled)
-	// Using GTC instead of IOC since the API rejects limit_limit_ioc
-	orderReq.OrderConfiguration.LimitLimitGtc = &struct {
-		BaseSize   string `json:"base_size"`
-		LimitPrice string `json:"limit_price"`
-	}{
-		BaseSize:   size,
-		LimitPrice: fmt.Sprintf("%.8f", price),
+	// Using GTC instead of IOC since the API rejects limit_limit_ioc; IOC goes through sor_limit_ioc
+	if opts.ImmediateOrCancel {
+		orderReq.OrderConfiguration.SorLimitIoc = &struct {
+			BaseSize   string `json:"base_size"`
+			LimitPrice string `json:"limit_price"`
+		}{
+			BaseSize:   size,
+			LimitPrice: fmt.Sprintf("%.8f", price),
+		}
+	} else {
+		orderReq.OrderConfiguration.LimitLimitGtc = &struct {
+			BaseSize   string `json:"base_size"`
+			LimitPrice string `json:"limit_price"`
+			PostOnly   bool   `json:"post_only"`
+		}{
+			BaseSize:   size,
+			LimitPrice: fmt.Sprintf("%.8f", price),
+			PostOnly:   opts.PostOnly,
+		}
 	}
 
 	respBody, err := c.makeRequest(ctx, "POST", "/orders", orderReq)
@@ -285,12 +576,22 @@ func (c *CoinbaseClient) createOrder(side, size string, price float64) (*Order,
 		if errorResp.ErrorResponse.PreviewFailureReason != "" {
 			errorMsg = fmt.Sprintf("%s (Preview: %s)", errorMsg, errorResp.ErrorResponse.PreviewFailureReason)
 		}
+		// Coinbase reports a short balance as INSUFFICIENT_FUND
+		if strings.Contains(errorResp.ErrorResponse.Error, "INSUFFICIENT_FUND") ||
+			strings.Contains(errorResp.ErrorResponse.PreviewFailureReason, "INSUFFICIENT_FUND") {
+			return nil, fmt.Errorf("%w: %s", ErrInsufficientFunds, errorMsg)
+		}
+		// Coinbase reports a crossing post-only order as INVALID_LIMIT_PRICE_POST_ONLY
+		if opts.PostOnly && (strings.Contains(errorResp.ErrorResponse.Error, "POST_ONLY") ||
+			strings.Contains(errorResp.ErrorResponse.PreviewFailureReason, "POST_ONLY")) {
+			return nil, fmt.Errorf("%w: %s", ErrPostOnlyWouldCross, errorMsg)
+		}
 		return nil, fmt.Errorf("order failed: %s", errorMsg)
 	}
 
 	var resp CreateOrderResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal create order response: %w", err)
+	if err := decodeJSON(respBody, &resp, "create order"); err != nil {
+		return nil, err
 	}
 
 	// Create order response
@@ -299,7 +600,7 @@ func (c *CoinbaseClient) createOrder(side, size string, price float64) (*Order,
 		ClientOrderID: clientOrderID,
 		ProductID:     c.tradingPair,
 		Side:          side,
-		Type:          "LIMIT_GTC", // Updated to reflect GTC order type
+		Type:          orderType,
 		Size:          size,
 		Price:         fmt.Sprintf("%.8f", price),
 		Status:        "PENDING",
@@ -307,18 +608,19 @@ func (c *CoinbaseClient) createOrder(side, size string, price float64) (*Order,
 	}
 
 	// Log successful order creation in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("Successfully created %s order: %s", side, order.ID)
 	}
 
-	// Small pause to allow Coinbase and market to process the GTC order
-	// This ensures we get accurate status when we check
-	time.Sleep(500 * time.Millisecond) // 500ms pause
+	// The order changes the book, so don't serve a market state cached before it
+	c.InvalidateMarketCache()
 
-	// For GTC orders, check the status to see if it was filled immediately
+	// Poll the order status until it reaches a terminal state or the poll budget elapses
 	// GTC orders may fill immediately if the limit price is met
-	orderStatus, err := c.GetOrderStatus(order.ID)
+	orderStatus, err := c.waitForOrderStatus(order.ID)
 	if err != nil {
+		// The order was placed but its state is unknown: it may well have filled, so don't report it as PENDING
+		order.Status = OrderStatusUnknown
 		c.logger.Printf("Warning: Could not check order status for %s: %v", order.ID, err)
 	} else {
 		// Update the order with the actual status
@@ -328,7 +630,7 @@ func (c *CoinbaseClient) createOrder(side, size string, price float64) (*Order,
 		order.AveragePrice = orderStatus.AverageFilledPrice
 
 		// Log the immediate result
-		if os.Getenv("LOG_LEVEL") == "DEBUG" {
+		if c.debug {
 			if orderStatus.Status == "FILLED" {
 				c.logger.Printf("✅ GTC order %s was FILLED: %s @ %s", order.ID, orderStatus.FilledSize, orderStatus.AverageFilledPrice)
 			} else if orderStatus.Status == "OPEN" {
@@ -337,34 +639,123 @@ func (c *CoinbaseClient) createOrder(side, size string, price float64) (*Order,
 				c.logger.Printf("⚠️ GTC order %s status: %s", order.ID, orderStatus.Status)
 			}
 		}
+
+		// Let the execution webhook know the bot actually traded
+		c.notifyExecution(order, orderStatus.TotalFees)
+	}
+
+	// An order resting on the book (or whose state is unknown) may fill later, keep checking it for fills
+	if !isTerminalOrderStatus(order.Status) {
+		c.watchOrderFills(order.ID, parseDecimal(order.FilledSize))
 	}
 
 	return order, nil
 }
 
+// OrderStatusUnknown marks a placed order whose status couldn't be read, as opposed to one Coinbase reports as pending
+const OrderStatusUnknown = "UNKNOWN"
+
+// isTerminalOrderStatus reports whether an order status can no longer change
+func isTerminalOrderStatus(status string) bool {
+	switch status {
+	case "FILLED", "CANCELLED", "CANCELED", "EXPIRED", "FAILED":
+		return true
+	default:
+		return false
+	}
+}
+
+// waitForOrderStatus polls an order's status until it is terminal or the configured timeout elapses.
+// It returns the last status read, so callers may still receive a non-terminal state such as OPEN.
+// When no poll succeeded, the status is retried up to ORDER_STATUS_RETRIES more times before giving up.
+func (c *CoinbaseClient) waitForOrderStatus(orderID string) (*CoinbaseOrder, error) {
+	deadline := time.Now().Add(c.orderStatusPollTimeout)
+
+	var lastStatus *CoinbaseOrder
+	var lastErr error
+	for {
+		// Pause to allow Coinbase and the market to process the order
+		time.Sleep(c.orderStatusPollInterval)
+
+		orderStatus, err := c.GetOrderStatus(orderID)
+		if err != nil {
+			lastErr = err
+		} else {
+			lastStatus, lastErr = orderStatus, nil
+			if isTerminalOrderStatus(orderStatus.Status) {
+				return orderStatus, nil
+			}
+		}
+
+		if !time.Now().Before(deadline) {
+			break
+		}
+	}
+
+	if lastStatus != nil {
+		return lastStatus, nil
+	}
+
+	for attempt := 1; attempt <= c.orderStatusRetries; attempt++ {
+		if c.debug {
+			c.logger.Printf("Retrying order status for %s (%d/%d) after: %v", orderID, attempt, c.orderStatusRetries, lastErr)
+		}
+		time.Sleep(c.orderStatusRetryInterval)
+
+		orderStatus, err := c.GetOrderStatus(orderID)
+		if err == nil {
+			return orderStatus, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
 // IsOrderSuccessful checks if an IOC order was successfully filled
 func (c *CoinbaseClient) IsOrderSuccessful(order *Order) bool {
 	return order.Status == "FILLED"
 }
 
+// IsPartialFill checks if an order was cancelled after executing only part of its size
+func (c *CoinbaseClient) IsPartialFill(order *Order) bool {
+	if order.Status != "CANCELLED" && order.Status != "CANCELED" && order.Status != "EXPIRED" {
+		return false
+	}
+	filledSize, err := strconv.ParseFloat(order.FilledSize, 64)
+	return err == nil && filledSize > 0
+}
+
 // GetOrderResult provides a human-readable result of the order execution
 func (c *CoinbaseClient) GetOrderResult(order *Order) string {
+	if c.IsPartialFill(order) {
+		return fmt.Sprintf("◐ Order %s was PARTIALLY FILLED: %s of %s @ %s (value: %s)",
+			order.ID, order.FilledSize, order.Size, order.AveragePrice, order.FilledValue)
+	}
+
 	switch order.Status {
 	case "FILLED":
 		return fmt.Sprintf("✅ Order %s was FILLED: %s @ %s", order.ID, order.FilledSize, order.AveragePrice)
-	case "CANCELED":
+	case "CANCELLED", "CANCELED":
 		return fmt.Sprintf("❌ Order %s was CANCELED (no liquidity at limit price)", order.ID)
 	case "PENDING":
 		return fmt.Sprintf("⏳ Order %s is still PENDING", order.ID)
+	case OrderStatusUnknown:
+		return fmt.Sprintf("❓ Order %s was placed but its status could not be checked", order.ID)
 	default:
 		return fmt.Sprintf("⚠️ Order %s status: %s", order.ID, order.Status)
 	}
 }
 
 // BuyBTC places a buy order for the configured trading pair
-func (c *CoinbaseClient) BuyBTC(size string, price float64) (*Order, error) {
+func (c *CoinbaseClient) BuyBTC(size string, price float64, opts OrderOptions) (*Order, error) {
+	done, err := c.beginTrade()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
 	// Create order
-	order, err := c.createOrder("BUY", size, price)
+	order, err := c.createOrder("BUY", size, price, opts)
 	if err != nil {
 		c.logger.Printf("Error creating BUY order: %v", err)
 		return nil, fmt.Errorf("failed to create BUY order: %w", err)
@@ -376,9 +767,15 @@ func (c *CoinbaseClient) BuyBTC(size string, price float64) (*Order, error) {
 }
 
 // SellBTC places a sell order for the configured trading pair
-func (c *CoinbaseClient) SellBTC(size string, price float64) (*Order, error) {
+func (c *CoinbaseClient) SellBTC(size string, price float64, opts OrderOptions) (*Order, error) {
+	done, err := c.beginTrade()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
 	// Create order
-	order, err := c.createOrder("SELL", size, price)
+	order, err := c.createOrder("SELL", size, price, opts)
 	if err != nil {
 		c.logger.Printf("Error creating SELL order: %v", err)
 		return nil, fmt.Errorf("failed to create SELL order: %w", err)
@@ -402,26 +799,50 @@ func (c *CoinbaseClient) GetOrderStatus(orderID string) (*CoinbaseOrder, error)
 	}
 
 	var order CoinbaseOrder
-	if err := json.Unmarshal(respBody, &order); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal order status response: %w", err)
+	if err := decodeJSON(respBody, &order, "order status"); err != nil {
+		return nil, err
 	}
+	c.observeOrderFill(&order)
 
 	return &order, nil
 }
 
-// GetOrders retrieves all orders
-func (c *CoinbaseClient) GetOrders() ([]Order, error) {
+// GetOrders retrieves orders for the trading pair filtered by status (OPEN, FILLED, CANCELLED or ALL)
+// and side (BUY, SELL or empty for both). An empty status defaults to OPEN.
+func (c *CoinbaseClient) GetOrders(status, side string) ([]Order, error) {
+	return c.ListOrders(OrderListOptions{Status: status, Side: side})
+}
+
+// ListOrders retrieves orders matching the given options
+func (c *CoinbaseClient) ListOrders(opts OrderListOptions) ([]Order, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// Log order fetching in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("Fetching orders...")
 	}
 
 	// Use the correct endpoint from Coinbase API documentation
-	// Filter for open orders only (active orders that can be canceled/modified)
-	endpoint := fmt.Sprintf("/orders/historical/batch?product_ids=%s&order_status=OPEN&limit=100", c.tradingPair)
+	// ALL omits the status filter so every order in the history is returned
+	status := opts.Status
+	if status == "" {
+		status = "OPEN"
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultOrderListLimit
+	}
+	endpoint := fmt.Sprintf("/orders/historical/batch?limit=%d", limit)
+	if !opts.AllProducts {
+		endpoint += "&product_ids=" + c.tradingPair
+	}
+	if status != "ALL" {
+		endpoint += "&order_status=" + status
+	}
+	if opts.Side != "" {
+		endpoint += "&order_side=" + opts.Side
+	}
 
 	respBody, err := c.makeRequest(ctx, "GET", endpoint, nil)
 	if err != nil {
@@ -430,27 +851,52 @@ func (c *CoinbaseClient) GetOrders() ([]Order, error) {
 	}
 
 	// Debug: Log the raw response (only in debug mode)
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("Raw orders response: %s", string(respBody))
 	}
 
 	var resp OrdersResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := decodeJSON(respBody, &resp, "orders"); err != nil {
 		c.logger.Printf("Failed to unmarshal orders response: %v", err)
-		return nil, fmt.Errorf("failed to unmarshal orders response: %w", err)
+		return nil, err
 	}
 
 	// Convert to our simplified structure
 	var orders []Order
 	for _, order := range resp.Orders {
+		c.observeOrderFill(&order)
+
 		// Extract order details based on configuration type
-		var size, price string
+		var size, price, stopPrice, limitPrice string
 		var orderType string
 
-		if order.OrderConfiguration.LimitLimitGtc != nil {
-			size = order.OrderConfiguration.LimitLimitGtc.BaseSize
-			price = order.OrderConfiguration.LimitLimitGtc.LimitPrice
-			orderType = "MARKET"
+		config := order.OrderConfiguration
+		switch {
+		case config.LimitLimitGtc != nil:
+			size = config.LimitLimitGtc.BaseSize
+			price = config.LimitLimitGtc.LimitPrice
+			orderType = "LIMIT_GTC"
+		case config.LimitLimitIoc != nil:
+			size = config.LimitLimitIoc.BaseSize
+			price = config.LimitLimitIoc.LimitPrice
+			orderType = "LIMIT_IOC"
+		case config.SorLimitIoc != nil:
+			size = config.SorLimitIoc.BaseSize
+			price = config.SorLimitIoc.LimitPrice
+			orderType = "LIMIT_IOC"
+		case config.MarketMarketIoc != nil:
+			// Market buys are usually sized in quote currency, market sells in base currency
+			size = config.MarketMarketIoc.BaseSize
+			if size == "" {
+				size = config.MarketMarketIoc.QuoteSize
+			}
+			orderType = "MARKET_IOC"
+		case config.StopLimitStopLimitGtc != nil:
+			size = config.StopLimitStopLimitGtc.BaseSize
+			limitPrice = config.StopLimitStopLimitGtc.LimitPrice
+			stopPrice = config.StopLimitStopLimitGtc.StopPrice
+			price = limitPrice
+			orderType = "STOP_LIMIT_GTC"
 		}
 
 		// Parse the created time
@@ -468,6 +914,8 @@ func (c *CoinbaseClient) GetOrders() ([]Order, error) {
 			Type:         orderType,
 			Size:         size,
 			Price:        price,
+			StopPrice:    stopPrice,
+			LimitPrice:   limitPrice,
 			Status:       order.Status,
 			CreatedAt:    createdAt,
 			FilledSize:   order.FilledSize,
@@ -477,7 +925,7 @@ func (c *CoinbaseClient) GetOrders() ([]Order, error) {
 	}
 
 	// Log successful order fetch in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("Successfully fetched %d orders", len(orders))
 	}
 	return orders, nil
@@ -489,7 +937,7 @@ func (c *CoinbaseClient) CancelOrder(orderID string) error {
 	defer cancel()
 
 	// Log order cancellation in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("Cancelling order: %s", orderID)
 	}
 
@@ -499,26 +947,190 @@ func (c *CoinbaseClient) CancelOrder(orderID string) error {
 		OrderIDs: []string{orderID},
 	}
 
-	_, err := c.makeRequest(ctx, "POST", "/orders/batch_cancel", cancelReq)
+	respBody, err := c.makeRequest(ctx, "POST", "/orders/batch_cancel", cancelReq)
 	if err != nil {
 		c.logger.Printf("Error cancelling order %s: %v", orderID, err)
 		return fmt.Errorf("failed to cancel order %s: %w", orderID, err)
 	}
 
+	// A 200 only means the batch was processed, each order reports its own outcome
+	var resp BatchCancelResponse
+	if err := decodeJSON(respBody, &resp, "cancel order"); err != nil {
+		return fmt.Errorf("failed to cancel order %s: %w", orderID, err)
+	}
+	cancelled := false
+	failureReason := "no result returned for the order"
+	for _, r := range resp.Results {
+		if r.OrderID != orderID {
+			continue
+		}
+		cancelled = r.Success
+		failureReason = r.FailureReason
+	}
+	if !cancelled {
+		c.logger.Printf("Coinbase refused to cancel order %s: %s", orderID, failureReason)
+		return fmt.Errorf("%w: order %s: %s", ErrCancelRejected, orderID, failureReason)
+	}
+
 	// Log successful cancellation in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("Successfully cancelled order: %s", orderID)
 	}
 	return nil
 }
 
-// GetCandles retrieves candle data for the configured trading pair
+// CancelAllOrders cancels every open order for the trading pair with a single batch_cancel request.
+// Orders the batch reports as failed (or omits) are retried one by one; those Coinbase still refuses to
+// cancel are reported as rejected, with the reason, apart from orders whose cancel request failed.
+func (c *CoinbaseClient) CancelAllOrders() (*CancelAllResult, error) {
+	orders, err := c.GetOrders("OPEN", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch orders: %w", err)
+	}
+
+	var orderIDs []string
+	for _, order := range orders {
+		if order.Status == "OPEN" || order.Status == "PENDING" {
+			orderIDs = append(orderIDs, order.ID)
+		}
+	}
+
+	result := &CancelAllResult{Cancelled: []string{}}
+	if len(orderIDs) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if c.debug {
+		c.logger.Printf("Cancelling %d orders in one batch", len(orderIDs))
+	}
+
+	cancelReq := struct {
+		OrderIDs []string `json:"order_ids"`
+	}{
+		OrderIDs: orderIDs,
+	}
+
+	succeeded := make(map[string]bool, len(orderIDs))
+	respBody, err := c.makeRequest(ctx, "POST", "/orders/batch_cancel", cancelReq)
+	if err != nil {
+		c.logger.Printf("[WARN] Batch cancel failed, falling back to individual cancels: %v", err)
+	} else {
+		var resp BatchCancelResponse
+		if err := decodeJSON(respBody, &resp, "batch cancel"); err != nil {
+			c.logger.Printf("[WARN] %v, falling back to individual cancels", err)
+		}
+		for _, r := range resp.Results {
+			if r.Success {
+				succeeded[r.OrderID] = true
+			} else if c.debug {
+				c.logger.Printf("Batch cancel failed for order %s: %s", r.OrderID, r.FailureReason)
+			}
+		}
+	}
+
+	for _, orderID := range orderIDs {
+		if succeeded[orderID] {
+			result.Cancelled = append(result.Cancelled, orderID)
+			continue
+		}
+		if err := c.CancelOrder(orderID); errors.Is(err, ErrCancelRejected) {
+			result.Rejected = append(result.Rejected, CancelRejection{OrderID: orderID, Reason: err.Error()})
+		} else if err != nil {
+			result.Failed = append(result.Failed, orderID)
+		} else {
+			result.Cancelled = append(result.Cancelled, orderID)
+		}
+	}
+
+	return result, nil
+}
+
+// ReplaceOrder cancels an open GTC order and places a new one on the same side with the given size and price.
+// An empty newSize keeps the original order size. If the original order filled before the cancel took effect,
+// ErrOrderAlreadyFilled is returned, or a *PartialFillError if only part of it did; no replacement is placed.
+func (c *CoinbaseClient) ReplaceOrder(orderID string, newSize string, newPrice float64) (*ReplaceOrderResult, error) {
+	done, err := c.beginTrade()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	existing, err := c.GetOrderStatus(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up order %s: %w", orderID, err)
+	}
+
+	if existing.ProductID != "" && existing.ProductID != c.tradingPair {
+		return nil, fmt.Errorf("order %s belongs to %s, not %s", orderID, existing.ProductID, c.tradingPair)
+	}
+	if existing.Status == "FILLED" {
+		return nil, fmt.Errorf("%w: %s", ErrOrderAlreadyFilled, orderID)
+	}
+	if isTerminalOrderStatus(existing.Status) {
+		return nil, fmt.Errorf("%w: %s is %s", ErrOrderNotOpen, orderID, existing.Status)
+	}
+
+	gtc := existing.OrderConfiguration.LimitLimitGtc
+	if gtc == nil {
+		return nil, fmt.Errorf("order %s is not a GTC limit order", orderID)
+	}
+	if newSize == "" {
+		newSize = gtc.BaseSize
+	}
+
+	if err := c.CancelOrder(orderID); err != nil {
+		return nil, err
+	}
+
+	// Confirm the cancel took effect before placing the replacement, so we never double up
+	cancelled, err := c.waitForOrderStatus(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm cancellation of order %s: %w", orderID, err)
+	}
+	if cancelled.Status == "FILLED" {
+		return nil, fmt.Errorf("%w: %s (filled %s)", ErrOrderAlreadyFilled, orderID, cancelled.FilledSize)
+	}
+	if filledSize, _ := strconv.ParseFloat(cancelled.FilledSize, 64); filledSize > 0 {
+		return nil, &PartialFillError{OrderID: orderID, FilledSize: cancelled.FilledSize}
+	}
+	if cancelled.Status != "CANCELLED" && cancelled.Status != "CANCELED" {
+		return nil, fmt.Errorf("order %s was not cancelled (status: %s)", orderID, cancelled.Status)
+	}
+
+	order, err := c.createOrder(existing.Side, newSize, newPrice, OrderOptions{PostOnly: gtc.PostOnly})
+	if err != nil {
+		return nil, fmt.Errorf("order %s was cancelled but the replacement failed: %w", orderID, err)
+	}
+
+	c.logger.Printf("Replaced order %s with %s: %s", orderID, order.ID, c.GetOrderResult(order))
+	return &ReplaceOrderResult{
+		OldOrderID: orderID,
+		NewOrderID: order.ID,
+		Order:      order,
+	}, nil
+}
+
+// GetCandles retrieves candle data for the configured trading pair. start and end are Unix seconds; an empty
+// end means now and an empty start means limit candles before end.
 func (c *CoinbaseClient) GetCandles(start, end, granularity string, limit int) ([]Candle, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	return c.getCandlesContext(context.Background(), start, end, granularity, limit)
+}
+
+// getCandlesContext is GetCandles bound to a parent context, so chunked range fetches can stop in-flight requests
+func (c *CoinbaseClient) getCandlesContext(parent context.Context, start, end, granularity string, limit int) ([]Candle, error) {
+	ctx, cancel := context.WithTimeout(parent, 10*time.Second)
 	defer cancel()
 
+	start, end, err := candleWindow(start, end, granularity, limit, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	// Log candle fetching in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("Fetching candles for %s: start=%s, end=%s, granularity=%s", c.tradingPair, start, end, granularity)
 	}
 
@@ -537,15 +1149,23 @@ func (c *CoinbaseClient) GetCandles(start, end, granularity string, limit int) (
 	}
 
 	var resp CandlesResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal candles response: %w", err)
+	if err := decodeJSON(respBody, &resp, "candles"); err != nil {
+		return nil, err
+	}
+
+	// Normalize to oldest-first so every consumer sees chronological data
+	sortCandlesAscending(resp.Candles)
+
+	candles, err := c.validateCandles(resp.Candles, granularity)
+	if err != nil {
+		return nil, err
 	}
 
 	// Log successful candle fetch in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
-		c.logger.Printf("Successfully fetched %d candles", len(resp.Candles))
+	if c.debug {
+		c.logger.Printf("Successfully fetched %d candles", len(candles))
 	}
-	return resp.Candles, nil
+	return candles, nil
 }
 
 // GetOrderBook retrieves the order book for the configured trading pair
@@ -554,7 +1174,7 @@ func (c *CoinbaseClient) GetOrderBook(limit int) (*OrderBook, error) {
 	defer cancel()
 
 	// Log order book fetching in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("Fetching order book for %s (limit %d)...", c.tradingPair, limit)
 	}
 
@@ -587,18 +1207,22 @@ func (c *CoinbaseClient) GetOrderBook(limit int) (*OrderBook, error) {
 		SpreadAbsolute string `json:"spread_absolute"`
 	}
 
-	if err := json.Unmarshal(respBody, &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal order book response: %w", err)
+	if err := decodeJSON(respBody, &response, "order book"); err != nil {
+		return nil, err
 	}
 
 	// Convert to our simplified structure
 	orderBook := &OrderBook{
-		Bids: response.Pricebook.Bids,
-		Asks: response.Pricebook.Asks,
+		Bids:           response.Pricebook.Bids,
+		Asks:           response.Pricebook.Asks,
+		Last:           response.Last,
+		MidMarket:      response.MidMarket,
+		SpreadBps:      response.SpreadBps,
+		SpreadAbsolute: response.SpreadAbsolute,
 	}
 
 	// Log successful order book fetch in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("Successfully fetched order book with %d bids and %d asks", len(orderBook.Bids), len(orderBook.Asks))
 	}
 	return orderBook, nil
@@ -606,69 +1230,101 @@ func (c *CoinbaseClient) GetOrderBook(limit int) (*OrderBook, error) {
 
 // GetSignal calculates technical indicators and checks for bearish signals
 func (c *CoinbaseClient) GetSignal() (*SignalResponse, error) {
-	return c.GetSignalWithCandles(300, "FIVE_MINUTE")
+	// Defaults to the fewest 5-minute candles the indicator periods need (220 with EMA200)
+	return c.GetSignalWithCandles(c.signalCandles, c.signalGranularity)
 }
 
 // GetSignalWithCandles allows customizing candle count and granularity for different use cases
 func (c *CoinbaseClient) GetSignalWithCandles(candleCount int, granularity string) (*SignalResponse, error) {
 	// Log signal fetching in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("Fetching signal data for %s (%d %s candles)...", c.tradingPair, candleCount, granularity)
 	}
 
-	// Get candles for technical analysis
-	candles, err := c.GetCandles("", "", granularity, candleCount)
+	indicators, err := c.signalIndicators(candleCount, granularity)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch candles: %w", err)
+		return nil, err
 	}
 
-	// Calculate technical indicators
-	indicators := calculateTechnicalIndicators(candles)
+	// Check for trend changes (not just bearish signals), confirmed against the higher timeframe if enabled
+	trendChange, currentTrend, triggers := c.detectTrendChange(indicators, c.confirmationBias(indicators))
 
-	// Check for trend changes (not just bearish signals)
-	trendChange, currentTrend, triggers := c.detectTrendChange(indicators)
+	// Price anomalies are reported alongside trend changes and alert on their own
+	anomaly, anomalyAlert := c.detectPriceAnomaly(indicators)
+	if anomaly {
+		triggers = append(triggers, "PRICE_ANOMALY")
+	}
 
 	response := &SignalResponse{
-		BearishSignal: currentTrend == "bearish",
-		Indicators:    indicators,
-		Triggers:      triggers,
-		Timestamp:     time.Now().Unix(),
+		BearishSignal:      currentTrend == "bearish",
+		Recommendation:     recommendationForTrend(currentTrend),
+		EffectiveThreshold: c.effectiveThreshold(indicators),
+		Indicators:         indicators,
+		Triggers:           triggers,
+		Timestamp:          time.Now().Unix(),
 	}
 
-	// Send webhook only if there's a significant trend change
-	if trendChange && c.webhookURL != "" {
+	// Send webhook only if there's a significant trend change or a new price anomaly
+	if (trendChange || anomalyAlert) && c.webhookURL != "" {
 		if err := c.SendWebhook(response); err != nil {
 			c.logger.Printf("Failed to send webhook: %v", err)
 		} else {
 			// Log webhook success in debug mode
-			if os.Getenv("LOG_LEVEL") == "DEBUG" {
+			if c.debug {
 				c.logger.Printf("Webhook notification sent for trend change: %s → %s", currentTrend, triggers)
 			}
 		}
 	}
 
 	// Log signal calculation completion in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
-		c.logger.Printf("Signal calculation complete: bearish=%v, triggers=%v", response.BearishSignal, triggers)
+	if c.debug {
+		c.logger.Printf("Signal calculation complete: bearish=%v, recommendation=%s, triggers=%v", response.BearishSignal, response.Recommendation, triggers)
 	}
 
 	return response, nil
 }
 
+// signalIndicators fetches the signal candles and calculates the technical indicators the detectors score
+func (c *CoinbaseClient) signalIndicators(candleCount int, granularity string) (TechnicalIndicators, error) {
+	// Get candles for technical analysis (only new candles are fetched once the cache is warm)
+	candles, err := c.cachedSignalCandles(candleCount, granularity)
+	if err != nil {
+		return TechnicalIndicators{}, fmt.Errorf("failed to fetch candles: %w", err)
+	}
+
+	// Decide on closed candles only; charts keep the forming candle
+	if c.closedCandlesOnly {
+		candles = dropFormingCandle(candles, granularity, time.Now())
+	}
+
+	// Fill missing intervals so indicators see contiguous data
+	if c.fillCandleGaps {
+		candles = fillCandleGaps(candles, granularity)
+	}
+
+	// Calculate technical indicators
+	indicators := calculateTechnicalIndicators(candles, c.indicatorPeriods, c.earlySignalExit)
+	if c.debug {
+		c.logger.Printf("Indicators computed (early exit: %v, partial: %v)", c.earlySignalExit, indicators.Partial)
+	}
+	return indicators, nil
+}
+
+// Background polling scores 12 hours of 5-minute candles (144 candles)
+const (
+	lightweightSignalSpan        = 12 * time.Hour
+	lightweightSignalGranularity = "FIVE_MINUTE"
+)
+
 // GetSignalLightweight is optimized for background polling - uses 5-minute candles with fewer data points
 func (c *CoinbaseClient) GetSignalLightweight() (*SignalResponse, error) {
-	// Use 5-minute candles for 12-hour trend change detection
-	// 144 candles = 12 hours of data (144 * 5 minutes = 720 minutes = 12 hours)
-	return c.GetSignalWithCandles(144, "FIVE_MINUTE")
+	return c.GetSignalWithCandles(candlesForSpan(lightweightSignalSpan, lightweightSignalGranularity), lightweightSignalGranularity)
 }
 
-// GetMarketState retrieves comprehensive market state information
-func (c *CoinbaseClient) GetMarketState(limit int) (*MarketState, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
+// fetchMarketState retrieves comprehensive market state information from Coinbase, bypassing the cache
+func (c *CoinbaseClient) fetchMarketState(limit int) (*MarketState, error) {
 	// Log market state fetching in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("Fetching market state for %s (limit %d)...", c.tradingPair, limit)
 	}
 
@@ -679,20 +1335,9 @@ func (c *CoinbaseClient) GetMarketState(limit int) (*MarketState, error) {
 	}
 
 	// Get product information for last price and volume
-	respBody, err := c.makeRequest(ctx, "GET", "/products/"+c.tradingPair, nil)
+	productInfo, err := c.getProduct()
 	if err != nil {
-		c.logger.Printf("Error fetching product info: %v", err)
-		return nil, fmt.Errorf("failed to fetch product info: %w", err)
-	}
-
-	var productInfo struct {
-		ProductID string `json:"product_id"`
-		LastPrice string `json:"last_price"`
-		Volume24h string `json:"volume_24h"`
-	}
-
-	if err := json.Unmarshal(respBody, &productInfo); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal product info: %w", err)
+		return nil, err
 	}
 
 	// Calculate best bid and ask
@@ -704,32 +1349,39 @@ func (c *CoinbaseClient) GetMarketState(limit int) (*MarketState, error) {
 		bestAsk = orderBook.Asks[0].Price
 	}
 
-	// Calculate spread
+	// Calculate spread (skipped when the bid is missing or zero, which would yield Inf/NaN)
 	var spread, spreadPercent string
-	if bestBid != "" && bestAsk != "" {
-		bidFloat, _ := strconv.ParseFloat(bestBid, 64)
-		askFloat, _ := strconv.ParseFloat(bestAsk, 64)
+	bidFloat, _ := strconv.ParseFloat(bestBid, 64)
+	askFloat, _ := strconv.ParseFloat(bestAsk, 64)
+	if bidFloat > 0 && askFloat > 0 {
 		spreadValue := askFloat - bidFloat
 		spreadPercentValue := (spreadValue / bidFloat) * 100
 
 		spread = fmt.Sprintf("%.8f", spreadValue)
 		spreadPercent = fmt.Sprintf("%.4f", spreadPercentValue)
+
+		// Record the sample for spread history
+		c.recordSpreadSample(spreadValue, spreadPercentValue)
 	}
 
 	marketState := &MarketState{
-		ProductID:     c.tradingPair,
-		BestBid:       bestBid,
-		BestAsk:       bestAsk,
-		Spread:        spread,
-		SpreadPercent: spreadPercent,
-		LastPrice:     productInfo.LastPrice,
-		Volume24h:     productInfo.Volume24h,
-		OrderBook:     *orderBook,
-		Timestamp:     time.Now().Unix(),
+		ProductID:      c.tradingPair,
+		BestBid:        bestBid,
+		BestAsk:        bestAsk,
+		Spread:         spread,
+		SpreadPercent:  spreadPercent,
+		LastPrice:      productInfo.Price,
+		MidMarket:      orderBook.MidMarket,
+		Last:           orderBook.Last,
+		SpreadBps:      orderBook.SpreadBps,
+		SpreadAbsolute: orderBook.SpreadAbsolute,
+		Volume24h:      productInfo.Volume24h,
+		OrderBook:      *orderBook,
+		Timestamp:      time.Now().Unix(),
 	}
 
 	// Log market state completion in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("Market state: Bid=%s, Ask=%s, Spread=%s (%s%%)",
 			marketState.BestBid, marketState.BestAsk, marketState.Spread, marketState.SpreadPercent)
 	}
@@ -737,74 +1389,140 @@ func (c *CoinbaseClient) GetMarketState(limit int) (*MarketState, error) {
 	return marketState, nil
 }
 
-// GetGraphData retrieves comprehensive data for charting
-func (c *CoinbaseClient) GetGraphData(period string) (*GraphData, error) {
-	// Determine time range and granularity based on period
-	var startTime, endTime time.Time
-	var granularity string
-	var candleLimit int
-
-	endTime = time.Now()
+// graphPeriodRange returns the time range and default candle granularity for a graph period
+func graphPeriodRange(period string) (time.Time, time.Time, string, error) {
+	endTime := time.Now()
 	switch period {
 	case "week":
-		startTime = endTime.AddDate(0, 0, -7)
-		granularity = "ONE_HOUR" // 1-hour candles for week view
-		candleLimit = 168        // 7 days * 24 hours
+		return endTime.AddDate(0, 0, -7), endTime, "ONE_HOUR", nil // 1-hour candles for week view
 	case "month":
-		startTime = endTime.AddDate(0, -1, 0)
-		granularity = "SIX_HOUR" // 6-hour candles for month view
-		candleLimit = 120        // ~30 days * 4 candles per day
+		return endTime.AddDate(0, -1, 0), endTime, "SIX_HOUR", nil // 6-hour candles for month view
 	default:
-		return nil, fmt.Errorf("invalid period: %s (use 'week' or 'month')", period)
+		return time.Time{}, time.Time{}, "", fmt.Errorf("invalid period: %s (use 'week' or 'month')", period)
+	}
+}
+
+// GetIndicatorSeries returns per-candle indicator series aligned with the candle timestamps for a graph period.
+// An empty granularity selects the period default. Leading zeros mean there is not enough data yet.
+func (c *CoinbaseClient) GetIndicatorSeries(period string, granularity string) (*IndicatorSeriesResponse, error) {
+	startTime, endTime, defaultGranularity, err := graphPeriodRange(period)
+	if err != nil {
+		return nil, err
+	}
+	if granularity == "" {
+		granularity = defaultGranularity
+	}
+
+	candles, err := c.GetCandlesRange(startTime, endTime, granularity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch candles: %w", err)
+	}
+	if c.fillCandleGaps {
+		candles = fillCandleGaps(candles, granularity)
+	}
+
+	timestamps := make([]int64, len(candles))
+	closes := make([]float64, len(candles))
+	for i, candle := range candles {
+		if t, err := parseCandleTime(candle.Start); err == nil {
+			timestamps[i] = t.Unix()
+		}
+		closes[i], _ = strconv.ParseFloat(candle.Close, 64)
+	}
+
+	return &IndicatorSeriesResponse{
+		ProductID:   c.tradingPair,
+		Period:      period,
+		Granularity: granularity,
+		Count:       len(candles),
+		Timestamps:  timestamps,
+		Close:       closes,
+		Indicators:  c.CalculateIndicatorsForGraph(candles),
+	}, nil
+}
+
+// GetGraphData retrieves comprehensive data for charting
+// An empty opts.Granularity selects the period default (ONE_HOUR for week, SIX_HOUR for month)
+func (c *CoinbaseClient) GetGraphData(period string, opts GraphOptions) (*GraphData, error) {
+	// Determine time range and granularity based on period
+	startTime, endTime, defaultGranularity, err := graphPeriodRange(period)
+	if err != nil {
+		return nil, err
+	}
+	granularity := opts.Granularity
+	if granularity == "" {
+		granularity = defaultGranularity
+	}
+
+	valueSource := opts.ValueSource
+	if valueSource == "" {
+		valueSource = ValueSourceAuto
+	}
+	if valueSource != ValueSourceAuto && valueSource != ValueSourceMemory && valueSource != ValueSourceComputed {
+		return nil, fmt.Errorf("invalid value source: %s (use 'auto', 'memory' or 'computed')", valueSource)
 	}
 
 	// Log graph data fetching in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("Fetching graph data for %s period (%s candles)...", period, granularity)
 	}
 
-	// Fetch candles
-	candles, err := c.GetCandles(
-		fmt.Sprintf("%d", startTime.Unix()),
-		fmt.Sprintf("%d", endTime.Unix()),
-		granularity,
-		candleLimit,
-	)
+	// Fetch candles (chunked when the range exceeds a single request)
+	candles, err := c.GetCandlesRange(startTime, endTime, granularity)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch candles: %w", err)
 	}
 
+	// Fill missing intervals so indicators and charts see contiguous data
+	if c.fillCandleGaps {
+		candles = fillCandleGaps(candles, granularity)
+	}
+
+	// Parts that fail are reported as warnings instead of failing the whole graph
+	var warnings []string
+
 	// Fetch trade history (optional - continue even if it fails)
 	trades, err := c.GetTradeHistory(startTime, endTime)
 	if err != nil {
+		warnings = append(warnings, "trade history unavailable")
 		// Log the error but continue with empty trades
-		if os.Getenv("LOG_LEVEL") == "DEBUG" {
+		if c.debug {
 			c.logger.Printf("Warning: Failed to fetch trade history: %v", err)
 		}
 		trades = []Trade{} // Use empty slice
 	}
 
-	// Calculate account values over time (use in-memory tracking)
-	accountValues := c.GetAssetValueHistoryForPeriod(startTime, endTime)
-	if len(accountValues) == 0 {
-		// Fallback to calculated values if no in-memory data
+	// Calculate account values over time (in-memory tracking unless computed values are forced)
+	var accountValues []AccountValue
+	usedSource := ValueSourceMemory
+	if valueSource != ValueSourceComputed {
+		accountValues = c.GetAssetValueHistoryForPeriod(startTime, endTime)
+	}
+	if len(accountValues) == 0 && valueSource != ValueSourceMemory {
+		// Reconstruct values from trades (fallback in auto mode, always in computed mode)
+		usedSource = ValueSourceComputed
 		accountValues, err = c.CalculateAccountValuesOverTime(candles, trades, startTime, endTime)
 		if err != nil {
+			warnings = append(warnings, "account values unavailable")
 			// Log the error but continue with empty account values
-			if os.Getenv("LOG_LEVEL") == "DEBUG" {
+			if c.debug {
 				c.logger.Printf("Warning: Failed to calculate account values: %v", err)
 			}
 			accountValues = []AccountValue{} // Use empty slice
 		}
-	} else {
-		// Log successful use of in-memory asset values
-		if os.Getenv("LOG_LEVEL") == "DEBUG" {
-			c.logger.Printf("Using %d in-memory asset value points", len(accountValues))
-		}
+	} else if c.debug {
+		// Log use of in-memory asset values
+		c.logger.Printf("Using %d in-memory asset value points", len(accountValues))
+	}
+	if accountValues == nil {
+		accountValues = []AccountValue{}
 	}
 
 	// Calculate technical indicators from candles
 	indicators := c.CalculateIndicatorsForGraph(candles)
+	if n := len(indicators.EMA26); n == 0 || indicators.EMA26[n-1] == nil {
+		warnings = append(warnings, "indicators unavailable (not enough candles)")
+	}
 
 	// Create summary from all available data
 	summary := c.CalculateGraphSummary(candles, trades, accountValues)
@@ -816,22 +1534,21 @@ func (c *CoinbaseClient) GetGraphData(period string) (*GraphData, error) {
 		Candles:       candles,
 		Trades:        trades,
 		AccountValues: accountValues,
+		ValueSource:   usedSource,
 		Indicators:    indicators,
 		Summary:       summary,
+		Warnings:      warnings,
 	}
 
 	// Log successful graph data fetch in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("Successfully generated graph data: %d candles with indicators", len(candles))
 		if len(candles) > 0 {
 			firstCandle := candles[0]
 			lastCandle := candles[len(candles)-1]
 
-			// Parse timestamps consistently (Unix timestamps)
-			firstTimeUnix, _ := strconv.ParseInt(firstCandle.Start, 10, 64)
-			lastTimeUnix, _ := strconv.ParseInt(lastCandle.Start, 10, 64)
-			firstTime := time.Unix(firstTimeUnix, 0)
-			lastTime := time.Unix(lastTimeUnix, 0)
+			firstTime, _ := parseCandleTime(firstCandle.Start)
+			lastTime, _ := parseCandleTime(lastCandle.Start)
 
 			c.logger.Printf("Time range: %s to %s",
 				firstTime.Format("2006-01-02 15:04:05"),
@@ -843,39 +1560,14 @@ func (c *CoinbaseClient) GetGraphData(period string) (*GraphData, error) {
 	return graphData, nil
 }
 
-// CalculateGraphSummary calculates summary statistics for the graph
-func (c *CoinbaseClient) CalculateGraphSummary(candles []Candle, trades []Trade, accountValues []AccountValue) struct {
-	TotalTrades    int     `json:"total_trades"`
-	BuyTrades      int     `json:"buy_trades"`
-	SellTrades     int     `json:"sell_trades"`
-	TotalVolume    float64 `json:"total_volume"`
-	TotalFees      float64 `json:"total_fees"`
-	StartingValue  float64 `json:"starting_value"`
-	EndingValue    float64 `json:"ending_value"`
-	ValueChange    float64 `json:"value_change"`
-	ValueChangePct float64 `json:"value_change_pct"`
-	BestPrice      float64 `json:"best_price"`
-	WorstPrice     float64 `json:"worst_price"`
-	AveragePrice   float64 `json:"average_price"`
-} {
-	summary := struct {
-		TotalTrades    int     `json:"total_trades"`
-		BuyTrades      int     `json:"buy_trades"`
-		SellTrades     int     `json:"sell_trades"`
-		TotalVolume    float64 `json:"total_volume"`
-		TotalFees      float64 `json:"total_fees"`
-		StartingValue  float64 `json:"starting_value"`
-		EndingValue    float64 `json:"ending_value"`
-		ValueChange    float64 `json:"value_change"`
-		ValueChangePct float64 `json:"value_change_pct"`
-		BestPrice      float64 `json:"best_price"`
-		WorstPrice     float64 `json:"worst_price"`
-		AveragePrice   float64 `json:"average_price"`
-	}{}
+// CalculateGraphSummary calculates summary statistics for the graph.
+// HasTrades, HasPriceData and HasValueData tell "zero" apart from "no data" for each group of fields.
+func (c *CoinbaseClient) CalculateGraphSummary(candles []Candle, trades []Trade, accountValues []AccountValue) GraphSummary {
+	var summary GraphSummary
 
 	// Trade statistics
 	summary.TotalTrades = len(trades)
-	var totalVolume, totalFees float64
+	totalVolume, totalFees := decimal.Zero, decimal.Zero
 	for _, trade := range trades {
 		if trade.Side == "BUY" {
 			summary.BuyTrades++
@@ -883,44 +1575,60 @@ func (c *CoinbaseClient) CalculateGraphSummary(candles []Candle, trades []Trade,
 			summary.SellTrades++
 		}
 
-		volume, _ := strconv.ParseFloat(trade.FilledValue, 64)
-		fee, _ := strconv.ParseFloat(trade.Fee, 64)
-		totalVolume += volume
-		totalFees += fee
+		totalVolume = totalVolume.Add(parseDecimal(trade.FilledValue))
+		totalFees = totalFees.Add(parseDecimal(trade.Fee))
 	}
-	summary.TotalVolume = totalVolume
-	summary.TotalFees = totalFees
+	summary.TotalVolume = Money{totalVolume}
+	summary.TotalFees = Money{totalFees}
 
-	// Price statistics from candles
-	var prices []float64
+	summary.HasTrades = len(trades) > 0
+
+	// Price statistics from candles (skipping unparseable or zero closes)
+	var prices []decimal.Decimal
 	for _, candle := range candles {
-		price, _ := strconv.ParseFloat(candle.Close, 64)
+		price, err := decimal.NewFromString(candle.Close)
+		if err != nil || !price.IsPositive() {
+			continue
+		}
 		prices = append(prices, price)
 	}
 
-	if len(prices) > 0 {
-		summary.BestPrice = prices[0]
-		summary.WorstPrice = prices[0]
-		var sum float64
-		for _, price := range prices {
-			if price > summary.BestPrice {
-				summary.BestPrice = price
-			}
-			if price < summary.WorstPrice {
-				summary.WorstPrice = price
+	summary.HasPriceData = len(prices) > 0
+	if summary.HasPriceData {
+		summary.BestPrice = Money{decimal.Max(prices[0], prices[1:]...)}
+		summary.WorstPrice = Money{decimal.Min(prices[0], prices[1:]...)}
+		summary.AveragePrice = Money{decimal.Avg(prices[0], prices[1:]...)}
+	}
+
+	// Realized and unrealized P&L, using the same FIFO cost basis as the trade stats
+	if summary.HasTrades {
+		match := matchTradesFIFO(trades)
+		realized, unrealized, feesPaid := decimal.Zero, decimal.Zero, decimal.Zero
+		for _, trip := range match.roundTrips {
+			realized = realized.Add(trip.RealizedPnL.Decimal)
+			feesPaid = feesPaid.Add(trip.Fees.Decimal)
+		}
+		if summary.HasPriceData {
+			markPrice := prices[len(prices)-1]
+			for _, lot := range match.openLots {
+				fees := lot.feePerUnit.Mul(lot.size)
+				unrealized = unrealized.Add(markPrice.Sub(lot.price).Mul(lot.size).Sub(fees))
+				feesPaid = feesPaid.Add(fees)
 			}
-			sum += price
 		}
-		summary.AveragePrice = sum / float64(len(prices))
+		summary.RealizedPnL = Money{realized}
+		summary.UnrealizedPnL = Money{unrealized}
+		summary.FeesPaid = Money{feesPaid}
 	}
 
 	// Account value statistics
-	if len(accountValues) > 0 {
-		summary.StartingValue = accountValues[0].TotalUSD
-		summary.EndingValue = accountValues[len(accountValues)-1].TotalUSD
-		summary.ValueChange = summary.EndingValue - summary.StartingValue
-		if summary.StartingValue > 0 {
-			summary.ValueChangePct = (summary.ValueChange / summary.StartingValue) * 100
+	summary.HasValueData = len(accountValues) > 0
+	if summary.HasValueData {
+		summary.StartingValue = moneyFromFloat(accountValues[0].TotalValue)
+		summary.EndingValue = moneyFromFloat(accountValues[len(accountValues)-1].TotalValue)
+		summary.ValueChange = Money{summary.EndingValue.Sub(summary.StartingValue.Decimal)}
+		if summary.StartingValue.IsPositive() {
+			summary.ValueChangePct = summary.ValueChange.Div(summary.StartingValue.Decimal).Mul(decimal.NewFromInt(100)).InexactFloat64()
 		}
 	}
 
@@ -933,7 +1641,7 @@ func (c *CoinbaseClient) GetTradeHistory(startTime, endTime time.Time) ([]Trade,
 	defer cancel()
 
 	// Log trade history fetching in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("Fetching trade history from %s to %s...",
 			startTime.Format("2006-01-02"), endTime.Format("2006-01-02"))
 	}
@@ -967,8 +1675,8 @@ func (c *CoinbaseClient) GetTradeHistory(startTime, endTime time.Time) ([]Trade,
 		} `json:"fills"`
 	}
 
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal trade history: %w", err)
+	if err := decodeJSON(respBody, &resp, "trade history"); err != nil {
+		return nil, err
 	}
 
 	var trades []Trade
@@ -991,7 +1699,7 @@ func (c *CoinbaseClient) GetTradeHistory(startTime, endTime time.Time) ([]Trade,
 	}
 
 	// Log successful trade history fetch in debug mode
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
+	if c.debug {
 		c.logger.Printf("Successfully fetched %d trades", len(trades))
 	}
 
@@ -1000,100 +1708,90 @@ func (c *CoinbaseClient) GetTradeHistory(startTime, endTime time.Time) ([]Trade,
 
 // CalculateAccountValuesOverTime calculates account values at each candle timestamp
 func (c *CoinbaseClient) CalculateAccountValuesOverTime(candles []Candle, trades []Trade, startTime, endTime time.Time) ([]AccountValue, error) {
-	// Get current account balances
-	accounts, err := c.GetAccounts()
+	// Get current base and quote balances
+	baseBalance, quoteBalance, err := c.pairBalances()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get current accounts: %w", err)
-	}
-
-	// Find BTC and USDC accounts
-	var btcAccount, usdcAccount *Account
-	for i := range accounts {
-		if accounts[i].Currency == "BTC" {
-			btcAccount = &accounts[i]
-		} else if accounts[i].Currency == "USDC" {
-			usdcAccount = &accounts[i]
-		}
+		return nil, err
 	}
 
-	if btcAccount == nil || usdcAccount == nil {
-		return nil, fmt.Errorf("missing BTC or USDC accounts")
-	}
+	// Candle closes value the portfolio in the quote currency; the current cross rate converts to VALUATION_CURRENCY
+	_, quote, _ := c.pairCurrencies()
+	rate, currency := c.valuationRate(quote)
+	rateDec := decimal.NewFromFloat(rate)
 
 	// Calculate account values at each candle timestamp
 	var accountValues []AccountValue
 
 	// Start with current balances and work backwards
-	currentBTC, _ := strconv.ParseFloat(btcAccount.AvailableBalance, 64)
-	currentUSDC, _ := strconv.ParseFloat(usdcAccount.AvailableBalance, 64)
+	currentBTC := decimal.NewFromFloat(baseBalance)
+	currentUSDC := decimal.NewFromFloat(quoteBalance)
 
 	// Process trades in reverse chronological order to calculate historical balances
 	tradeIndex := len(trades) - 1
 
 	for i := len(candles) - 1; i >= 0; i-- {
 		candle := candles[i]
-		// Parse the start time from the candle (Unix timestamp)
-		candleTimeUnix, err := strconv.ParseInt(candle.Start, 10, 64)
+		// Parse the start time from the candle
+		candleTime, err := parseCandleTime(candle.Start)
 		if err != nil {
 			// Skip invalid timestamps
 			continue
 		}
-		candleTime := time.Unix(candleTimeUnix, 0)
 
 		// Process trades that happened before this candle
 		for tradeIndex >= 0 && time.Unix(trades[tradeIndex].ExecutedAt, 0).After(candleTime) {
 			trade := trades[tradeIndex]
 
 			// Reverse the trade effect
-			size, _ := strconv.ParseFloat(trade.Size, 64)
-			price, _ := strconv.ParseFloat(trade.Price, 64)
-			fee, _ := strconv.ParseFloat(trade.Fee, 64)
+			size := parseDecimal(trade.Size)
+			notional := size.Mul(parseDecimal(trade.Price))
+			fee := parseDecimal(trade.Fee)
 
 			if trade.Side == "BUY" {
 				// Reverse buy: remove BTC, add back USDC
-				currentBTC -= size
-				currentUSDC += (size * price) + fee
+				currentBTC = currentBTC.Sub(size)
+				currentUSDC = currentUSDC.Add(notional).Add(fee)
 			} else {
 				// Reverse sell: add back BTC, remove USDC
-				currentBTC += size
-				currentUSDC -= (size * price) - fee
+				currentBTC = currentBTC.Add(size)
+				currentUSDC = currentUSDC.Sub(notional.Sub(fee))
 			}
 
 			tradeIndex--
 		}
 
-		// Calculate total USD value using candle price
-		price, _ := strconv.ParseFloat(candle.Close, 64)
-		totalUSD := currentUSDC + (currentBTC * price)
+		// Calculate total quote value using candle price
+		totalQuote := currentUSDC.Add(currentBTC.Mul(parseDecimal(candle.Close)))
 
 		accountValues = append([]AccountValue{{
-			Timestamp: candleTime.Unix(),
-			BTC:       currentBTC,
-			USDC:      currentUSDC,
-			TotalUSD:  totalUSD,
+			Timestamp:  candleTime.Unix(),
+			BTC:        currentBTC.InexactFloat64(),
+			USDC:       currentUSDC.InexactFloat64(),
+			TotalQuote: totalQuote.InexactFloat64(),
+			TotalUSD:   totalQuote.InexactFloat64(),
+			TotalValue: totalQuote.Mul(rateDec).InexactFloat64(),
+			Currency:   currency,
 		}}, accountValues...)
 	}
 
 	return accountValues, nil
 }
 
-// CalculateIndicatorsForGraph calculates technical indicators for each candle
-func (c *CoinbaseClient) CalculateIndicatorsForGraph(candles []Candle) struct {
-	EMA12  []float64 `json:"ema_12"`
-	EMA26  []float64 `json:"ema_26"`
-	RSI    []float64 `json:"rsi"`
-	MACD   []float64 `json:"macd"`
-	Signal []float64 `json:"signal"`
-} {
-	if len(candles) < 26 {
-		return struct {
-			EMA12  []float64 `json:"ema_12"`
-			EMA26  []float64 `json:"ema_26"`
-			RSI    []float64 `json:"rsi"`
-			MACD   []float64 `json:"macd"`
-			Signal []float64 `json:"signal"`
-		}{}
-	}
+// floatPtr returns a pointer to v, used for nullable series values
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+// CalculateIndicatorsForGraph calculates technical indicators for each candle. Every series is built in one
+// pass over the closes, carrying EMA and RSI state forward, and matches recomputing the indicator on each
+// prefix of the candles.
+func (c *CoinbaseClient) CalculateIndicatorsForGraph(candles []Candle) IndicatorSeries {
+	// Arrays always match the candle count, with nil (JSON null) entries during each indicator's warm-up period
+	ema12 := make([]*float64, len(candles))
+	ema26 := make([]*float64, len(candles))
+	rsi := make([]*float64, len(candles))
+	macd := make([]*float64, len(candles))
+	signal := make([]*float64, len(candles))
 
 	// Extract close prices
 	prices := make([]float64, len(candles))
@@ -1101,46 +1799,52 @@ func (c *CoinbaseClient) CalculateIndicatorsForGraph(candles []Candle) struct {
 		prices[i], _ = strconv.ParseFloat(candle.Close, 64)
 	}
 
-	// Calculate indicators for each point
-	ema12 := make([]float64, len(prices))
-	ema26 := make([]float64, len(prices))
-	rsi := make([]float64, len(prices))
-	macd := make([]float64, len(prices))
-	signal := make([]float64, len(prices))
+	periods := c.indicatorPeriods
 
-	// Calculate EMA12 and EMA26 for each point
-	for i := 0; i < len(prices); i++ {
-		if i >= 11 { // Need at least 12 points for EMA12
-			ema12[i] = calculateEMA(prices[:i+1], 12)
+	// Short and long EMAs (EMA12 and EMA26 by default)
+	emaShort := emaSeries(prices, periods.EMAShort)
+	emaLong := emaSeries(prices, periods.EMALong)
+	for i := range prices {
+		if i >= periods.EMAShort-1 { // Need at least EMAShort points
+			ema12[i] = floatPtr(emaShort[i])
 		}
-		if i >= 25 { // Need at least 26 points for EMA26
-			ema26[i] = calculateEMA(prices[:i+1], 26)
+		if i >= periods.EMALong-1 { // Need at least EMALong points
+			ema26[i] = floatPtr(emaLong[i])
 		}
 	}
 
-	// Calculate RSI for each point
-	for i := 0; i < len(prices); i++ {
-		if i >= 14 { // Need at least 15 points for RSI(14)
-			rsi[i] = calculateRSI(prices[:i+1], 14)
-		}
-	}
-
-	// Calculate MACD and Signal for each point
-	for i := 0; i < len(prices); i++ {
-		if i >= 25 { // Need at least 26 points for MACD
-			macdVal, signalVal := calculateMACD(prices[:i+1])
-			macd[i] = macdVal
-			signal[i] = signalVal
+	// RSI
+	rsiValues := rsiSeries(prices, periods.RSI)
+	for i := periods.RSI; i < len(prices); i++ { // Need at least RSI+1 points
+		rsi[i] = floatPtr(rsiValues[i])
+	}
+
+	// MACD shares the EMA state of the fast and slow lines; the signal line is an EMA of the MACD values
+	// from index MACDSlow onwards (as in calculateMACD) and stays nil until it has MACDSignal of them
+	emaFast := emaSeries(prices, periods.MACDFast)
+	emaSlow := emaSeries(prices, periods.MACDSlow)
+	signalMultiplier := 2.0 / float64(periods.MACDSignal+1)
+	var signalSum, signalEMA float64
+	for i := max(periods.MACDSlow-1, 0); i < len(prices); i++ { // Need at least MACDSlow points
+		macdVal := emaFast[i] - emaSlow[i]
+		macd[i] = floatPtr(macdVal)
+
+		if count := i - periods.MACDSlow + 1; count > 0 && periods.MACDSignal > 0 {
+			switch {
+			case count < periods.MACDSignal:
+				signalSum += macdVal
+			case count == periods.MACDSignal:
+				signalSum += macdVal
+				signalEMA = signalSum / float64(periods.MACDSignal)
+				signal[i] = floatPtr(signalEMA)
+			default:
+				signalEMA = (macdVal * signalMultiplier) + (signalEMA * (1 - signalMultiplier))
+				signal[i] = floatPtr(signalEMA)
+			}
 		}
 	}
 
-	return struct {
-		EMA12  []float64 `json:"ema_12"`
-		EMA26  []float64 `json:"ema_26"`
-		RSI    []float64 `json:"rsi"`
-		MACD   []float64 `json:"macd"`
-		Signal []float64 `json:"signal"`
-	}{
+	return IndicatorSeries{
 		EMA12:  ema12,
 		EMA26:  ema26,
 		RSI:    rsi,
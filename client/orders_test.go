@@ -0,0 +1,632 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// candlesFromCloses returns five-minute candles, oldest first, closing at the given prices
+func candlesFromCloses(closes []float64) []Candle {
+	return candlesStartingAt(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), closes)
+}
+
+// closedCandlesUntil returns five-minute candles closing at the given prices, the last one closed before now
+func closedCandlesUntil(now time.Time, closes []float64) []Candle {
+	last := now.Truncate(5 * time.Minute).Add(-5 * time.Minute)
+	return candlesStartingAt(last.Add(-time.Duration(len(closes)-1)*5*time.Minute), closes)
+}
+
+// candlesStartingAt returns five-minute candles from first, oldest first, closing at the given prices
+func candlesStartingAt(first time.Time, closes []float64) []Candle {
+	start := first.Unix()
+	candles := make([]Candle, len(closes))
+	for i, price := range closes {
+		closing := strconv.FormatFloat(price, 'f', -1, 64)
+		candles[i] = Candle{
+			Start:  strconv.FormatInt(start+int64(i)*300, 10),
+			Low:    strconv.FormatFloat(price*0.999, 'f', -1, 64),
+			High:   strconv.FormatFloat(price*1.001, 'f', -1, 64),
+			Open:   closing,
+			Close:  closing,
+			Volume: "1",
+		}
+	}
+	return candles
+}
+
+// wavyCloses returns n closes oscillating around a slow uptrend, so RSI sees both gains and losses
+func wavyCloses(n int) []float64 {
+	closes := make([]float64, n)
+	for i := range closes {
+		closes[i] = 40000 + float64(i)*5 + 300*math.Sin(float64(i)/4)
+	}
+	return closes
+}
+
+func TestCalculateIndicatorsForGraphWarmUpIsNull(t *testing.T) {
+	c := &CoinbaseClient{indicatorPeriods: defaultIndicatorPeriods()}
+	series := c.CalculateIndicatorsForGraph(candlesFromCloses(wavyCloses(100)))
+
+	// Leading nulls: EMA needs period points, RSI period+1, MACD the slow EMA, the signal MACDSignal MACD values
+	tests := []struct {
+		name   string
+		values []*float64
+		warmUp int
+	}{
+		{"ema_12", series.EMA12, 11},
+		{"ema_26", series.EMA26, 25},
+		{"rsi", series.RSI, 14},
+		{"macd", series.MACD, 25},
+		{"signal", series.Signal, 34},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.values) != 100 {
+				t.Fatalf("len = %d, want 100", len(tt.values))
+			}
+			for i := 0; i < tt.warmUp; i++ {
+				if tt.values[i] != nil {
+					t.Fatalf("[%d] = %v, want null during warm-up", i, *tt.values[i])
+				}
+			}
+			for i := tt.warmUp; i < len(tt.values); i++ {
+				if tt.values[i] == nil {
+					t.Fatalf("[%d] = null after the %d warm-up points", i, tt.warmUp)
+				}
+			}
+		})
+	}
+}
+
+// almostEqual compares floats computed along different paths, relative to their magnitude
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+func TestIndicatorSeriesMatchPrefixCalculations(t *testing.T) {
+	prices := wavyCloses(300)
+	periods := defaultIndicatorPeriods()
+
+	for _, period := range []int{periods.EMAShort, periods.EMALong, periods.EMATrend} {
+		series := emaSeries(prices, period)
+		for i := period - 1; i < len(prices); i++ {
+			if want := calculateEMA(prices[:i+1], period); !almostEqual(series[i], want) {
+				t.Fatalf("emaSeries(%d)[%d] = %v, want %v", period, i, series[i], want)
+			}
+		}
+	}
+
+	rsi := rsiSeries(prices, periods.RSI)
+	for i := periods.RSI; i < len(prices); i++ {
+		if want := calculateRSI(prices[:i+1], periods.RSI); !almostEqual(rsi[i], want) {
+			t.Fatalf("rsiSeries[%d] = %v, want %v", i, rsi[i], want)
+		}
+	}
+
+	c := &CoinbaseClient{indicatorPeriods: periods}
+	graph := c.CalculateIndicatorsForGraph(candlesFromCloses(prices))
+	for i := periods.MACDSlow - 1; i < len(prices); i++ {
+		wantMACD, wantSignal := calculateMACD(prices[:i+1], periods.MACDFast, periods.MACDSlow, periods.MACDSignal)
+		if !almostEqual(*graph.MACD[i], wantMACD) {
+			t.Fatalf("MACD[%d] = %v, want %v", i, *graph.MACD[i], wantMACD)
+		}
+		if graph.Signal[i] != nil && !almostEqual(*graph.Signal[i], wantSignal) {
+			t.Fatalf("Signal[%d] = %v, want %v", i, *graph.Signal[i], wantSignal)
+		}
+	}
+}
+
+func BenchmarkCalculateIndicatorsForGraph(b *testing.B) {
+	c := &CoinbaseClient{indicatorPeriods: defaultIndicatorPeriods()}
+	candles := candlesFromCloses(wavyCloses(5000)) // Thousands of candles, as in a chunked one-year graph
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.CalculateIndicatorsForGraph(candles)
+	}
+}
+
+func BenchmarkCalculateOrderSize(b *testing.B) {
+	c := newTestClient(b, newFakeCoinbase())
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.CalculateOrderSizeByPercentage("BUY", 50, "50000"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetSignal(b *testing.B) {
+	// The fake serves old candles, so every call is a full fetch: payload, parse and indicators
+	for _, bench := range []struct {
+		name  string
+		count int
+	}{
+		{"indicator minimum", defaultIndicatorPeriods().minSignalCandles()},
+		{"fixed 300", 300},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			fake := newFakeCoinbase()
+			fake.candles = candlesFromCloses(wavyCloses(bench.count))
+			c := newTestClient(b, fake)
+			c.closedCandlesOnly = false
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := c.GetSignalWithCandles(bench.count, "FIVE_MINUTE"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestCancelOrderReadsTheBatchResult(t *testing.T) {
+	fake := newFakeCoinbase()
+	fake.cancelFailures["filled-1"] = "UNKNOWN_CANCEL_ORDER"
+	c := newTestClient(t, fake)
+
+	if err := c.CancelOrder("open-1"); err != nil {
+		t.Errorf("CancelOrder(open-1) = %v, want success", err)
+	}
+	if err := c.CancelOrder("filled-1"); !errors.Is(err, ErrCancelRejected) || !strings.Contains(err.Error(), "UNKNOWN_CANCEL_ORDER") {
+		t.Errorf("CancelOrder(filled-1) = %v, want ErrCancelRejected with the failure reason", err)
+	}
+}
+
+func TestCancelAllOrdersWithMixedBatchResults(t *testing.T) {
+	fake := newFakeCoinbase()
+	fake.openOrders = []string{"open-1", "filled-1", "open-2", "flaky-1"}
+	fake.cancelFailures["filled-1"] = "UNKNOWN_CANCEL_ORDER"
+	fake.cancelFailures["flaky-1"] = "DUPLICATE_CANCEL_REQUEST"
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The individual retry of flaky-1 doesn't get an answer at all
+		body, _ := io.ReadAll(r.Body)
+		if strings.HasSuffix(r.URL.Path, "/orders/batch_cancel") && strings.Contains(string(body), `["flaky-1"]`) {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fake.ServeHTTP(w, r)
+	}))
+
+	result, err := c.CancelAllOrders()
+	if err != nil {
+		t.Fatalf("CancelAllOrders: %v", err)
+	}
+	if !reflect.DeepEqual(result.Cancelled, []string{"open-1", "open-2"}) {
+		t.Errorf("cancelled = %v, want [open-1 open-2]", result.Cancelled)
+	}
+	if len(result.Rejected) != 1 || result.Rejected[0].OrderID != "filled-1" || !strings.Contains(result.Rejected[0].Reason, "UNKNOWN_CANCEL_ORDER") {
+		t.Errorf("rejected = %+v, want filled-1 with its failure reason", result.Rejected)
+	}
+	if !reflect.DeepEqual(result.Failed, []string{"flaky-1"}) {
+		t.Errorf("failed = %v, want [flaky-1]", result.Failed)
+	}
+	// One batch for all, then one retry for each order the batch didn't cancel
+	if calls := fake.called("POST /orders/batch_cancel"); calls != 2 {
+		t.Errorf("batch_cancel reached Coinbase %d times, want 2 (the batch and the filled-1 retry)", calls)
+	}
+}
+
+// orderBaseSize returns the base size of a limit GTC or IOC order request
+func orderBaseSize(req CoinbaseCreateOrderRequest) string {
+	switch config := req.OrderConfiguration; {
+	case config.LimitLimitGtc != nil:
+		return config.LimitLimitGtc.BaseSize
+	case config.LimitLimitIoc != nil:
+		return config.LimitLimitIoc.BaseSize
+	case config.SorLimitIoc != nil:
+		return config.SorLimitIoc.BaseSize
+	}
+	return ""
+}
+
+func TestCreateOrderShrinksOnceOnInsufficientFunds(t *testing.T) {
+	insufficient := map[string]interface{}{
+		"success":        false,
+		"error_response": map[string]string{"error": "INSUFFICIENT_FUND", "message": "Insufficient balance in source account"},
+	}
+
+	tests := []struct {
+		name      string
+		retry     bool
+		rejects   int // Requests rejected before Coinbase accepts one
+		orders    int
+		wantSizes []string
+		wantErr   error
+	}{
+		{"shrinks then succeeds", true, 1, 2, []string{"0.1", "0.099"}, nil},
+		{"retries only once", true, 2, 2, []string{"0.1", "0.099"}, ErrInsufficientFunds},
+		{"disabled", false, 1, 1, []string{"0.1"}, ErrInsufficientFunds},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeCoinbase()
+			fake.createOrder = func(req CoinbaseCreateOrderRequest) interface{} {
+				if len(fake.orders) <= tt.rejects {
+					return insufficient
+				}
+				return map[string]interface{}{"success": true, "order_id": "order-" + req.ClientOrderID}
+			}
+			c := newTestClient(t, fake)
+			c.retryShrinkOnInsufficient = tt.retry
+			c.retryShrinkPct = 1
+
+			order, err := c.BuyBTC("0.1", 49000, OrderOptions{ClientOrderID: "dca-1"})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("BuyBTC = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("BuyBTC: %v", err)
+			} else if order.ID != "order-dca-1-shrink" {
+				t.Errorf("order ID = %s, want the retry's order-dca-1-shrink", order.ID)
+			}
+
+			if len(fake.orders) != tt.orders {
+				t.Fatalf("%d orders sent, want %d", len(fake.orders), tt.orders)
+			}
+			for i, want := range tt.wantSizes {
+				if got := orderBaseSize(fake.orders[i]); !parseDecimal(got).Equal(parseDecimal(want)) {
+					t.Errorf("order %d size = %s, want %s", i, got, want)
+				}
+			}
+			if tt.orders == 2 && fake.orders[1].ClientOrderID != "dca-1-shrink" {
+				t.Errorf("retry client order ID = %s, want dca-1-shrink", fake.orders[1].ClientOrderID)
+			}
+		})
+	}
+}
+
+func TestReduceOnlySellIsCheckedAgainstTheBalance(t *testing.T) {
+	fake := newFakeCoinbase()
+	fake.baseAvailable = "0.5"
+	c := newTestClient(t, fake)
+
+	if _, err := c.SellBTC("0.6", 50000, OrderOptions{ReduceOnly: true}); !errors.Is(err, ErrReduceOnlyExceedsBalance) {
+		t.Fatalf("over-size reduce-only sell = %v, want ErrReduceOnlyExceedsBalance", err)
+	}
+	if len(fake.orders) != 0 {
+		t.Fatalf("%d orders reached Coinbase, want the over-size sell rejected client-side", len(fake.orders))
+	}
+
+	if _, err := c.SellBTC("0.5", 50000, OrderOptions{ReduceOnly: true}); err != nil {
+		t.Fatalf("reduce-only sell of the whole balance: %v", err)
+	}
+	if len(fake.orders) != 1 {
+		t.Errorf("%d orders reached Coinbase, want 1", len(fake.orders))
+	}
+
+	if _, err := c.BuyBTC("0.1", 50000, OrderOptions{ReduceOnly: true}); err == nil || errors.Is(err, ErrReduceOnlyExceedsBalance) {
+		t.Errorf("reduce-only buy = %v, want it refused as sell-only", err)
+	}
+}
+
+func TestPostOnlyIsSentWithTheOrder(t *testing.T) {
+	fake := newFakeCoinbase()
+	c := newTestClient(t, fake)
+
+	if _, err := c.BuyBTC("0.1", 49000, OrderOptions{PostOnly: true}); err != nil {
+		t.Fatalf("post-only BuyBTC: %v", err)
+	}
+	if _, err := c.BuyBTC("0.1", 49000, OrderOptions{}); err != nil {
+		t.Fatalf("BuyBTC: %v", err)
+	}
+	if len(fake.orders) != 2 {
+		t.Fatalf("%d orders placed, want 2", len(fake.orders))
+	}
+	for i, want := range []bool{true, false} {
+		gtc := fake.orders[i].OrderConfiguration.LimitLimitGtc
+		if gtc == nil || gtc.PostOnly != want {
+			t.Errorf("order %d configuration = %+v, want a GTC limit with post_only %v", i, gtc, want)
+		}
+	}
+
+	// A crossing post-only order is rejected with its own error
+	fake.createOrder = func(req CoinbaseCreateOrderRequest) interface{} {
+		return map[string]interface{}{"success": false, "error_response": map[string]string{"error": "INVALID_LIMIT_PRICE_POST_ONLY"}}
+	}
+	if _, err := c.BuyBTC("0.1", 51000, OrderOptions{PostOnly: true}); !errors.Is(err, ErrPostOnlyWouldCross) {
+		t.Errorf("crossing post-only BuyBTC = %v, want ErrPostOnlyWouldCross", err)
+	}
+}
+
+func TestCalculateCoinbaseFeeIsExact(t *testing.T) {
+	c := &CoinbaseClient{}
+	tests := []struct{ amount, fee string }{
+		{"10", "1.04"},          // 0.05 spread + 0.99 flat
+		{"25", "1.615"},         // 0.125 spread + 1.49 flat
+		{"200", "3.99"},         // 1.00 spread + 2.99 flat
+		{"200.01", "3.980199"},  // 1.00005 spread + 1.49% of 200.01
+		{"1000.10", "19.90199"}, // 5.0005 spread + 1.49% of 1000.10
+	}
+	for _, tt := range tests {
+		if got := c.calculateCoinbaseFee(decimal.RequireFromString(tt.amount)); !got.Equal(decimal.RequireFromString(tt.fee)) {
+			t.Errorf("fee on %s = %s, want exactly %s", tt.amount, got, tt.fee)
+		}
+	}
+}
+
+func TestCalculateOrderSizeIsExact(t *testing.T) {
+	fake := newFakeCoinbase()
+	fake.quoteAvailable = "1000.10"
+	fake.baseAvailable = "0.3"
+	c := newTestClient(t, fake)
+
+	tests := []struct {
+		side       string
+		percentage float64
+		price      string
+		want       string
+	}{
+		// 10% of 1000.10 is 100.01, less a 3.49005 fee, over 30000: 0.0032173316... floored to 8 decimals
+		{"BUY", 10, "30000", "0.00321733"},
+		// 0.3 BTC is worth 15000.003, the 298.5000597 fee is 0.00597 BTC
+		{"SELL", 100, "50000.01", "0.29403000"},
+	}
+	for _, tt := range tests {
+		size, err := c.CalculateOrderSizeByPercentage(tt.side, tt.percentage, tt.price)
+		if err != nil {
+			t.Fatalf("%s %v%%: %v", tt.side, tt.percentage, err)
+		}
+		if size != tt.want {
+			t.Errorf("%s %v%% at %s = %s, want %s", tt.side, tt.percentage, tt.price, size, tt.want)
+		}
+	}
+}
+
+func TestFloorToIncrementNeverRoundsUp(t *testing.T) {
+	tests := []struct{ value, increment, want string }{
+		{"0.123456789", "0.00000001", "0.12345678"},
+		{"0.999999999", "0.00000001", "0.99999999"},
+		{"0.12345678", "0.00000001", "0.12345678"}, // Already on the increment
+		{"1.23456", "0.001", "1.234"},
+		{"0.123456789", "0", "0.12345678"}, // No increment: 8 decimal places
+	}
+	for _, tt := range tests {
+		got := floorToIncrement(decimal.RequireFromString(tt.value), decimal.RequireFromString(tt.increment))
+		if !got.Equal(decimal.RequireFromString(tt.want)) {
+			t.Errorf("floorToIncrement(%s, %s) = %s, want %s", tt.value, tt.increment, got, tt.want)
+		}
+	}
+}
+
+func TestClientOrderIDIsSentToCoinbase(t *testing.T) {
+	fake := newFakeCoinbase()
+	c := newTestClient(t, fake)
+
+	order, err := c.BuyBTC("0.1", 49000, OrderOptions{ClientOrderID: "7d0b6a52-1f0e-4c1a-9a57-3c2f4f1b9e10"})
+	if err != nil {
+		t.Fatalf("BuyBTC: %v", err)
+	}
+	if _, err := c.BuyBTC("0.1", 49000, OrderOptions{}); err != nil {
+		t.Fatalf("BuyBTC without a key: %v", err)
+	}
+	if fake.orders[0].ClientOrderID != "7d0b6a52-1f0e-4c1a-9a57-3c2f4f1b9e10" || order.ClientOrderID != fake.orders[0].ClientOrderID {
+		t.Errorf("client_order_id sent %q, reported %q, want the idempotency key", fake.orders[0].ClientOrderID, order.ClientOrderID)
+	}
+	// Without a key each order gets its own generated ID
+	if id := fake.orders[1].ClientOrderID; id == "" || id == fake.orders[0].ClientOrderID {
+		t.Errorf("generated client_order_id = %q", id)
+	}
+}
+
+func TestOrderStatusRetriesAFlakyEndpoint(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int
+		wantStatus string
+		wantLeft   int // Failures the fake still had to serve
+	}{
+		{"recovers on the last retry", 3, "FILLED", 0},
+		{"never answers", 100, OrderStatusUnknown, 96},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeCoinbase()
+			fake.statusFailures = tt.failures
+			c := newTestClient(t, fake)
+			// One poll, then three retries
+			c.orderStatusPollTimeout, c.orderStatusPollInterval = 0, time.Millisecond
+			c.orderStatusRetries, c.orderStatusRetryInterval = 3, time.Millisecond
+
+			order, err := c.BuyBTC("0.1", 50000, OrderOptions{})
+			if err != nil {
+				t.Fatalf("BuyBTC: %v, want the placed order whatever its status", err)
+			}
+			if order.Status != tt.wantStatus {
+				t.Errorf("order status = %s, want %s", order.Status, tt.wantStatus)
+			}
+			fake.mutex.Lock()
+			left := fake.statusFailures
+			fake.mutex.Unlock()
+			if left != tt.wantLeft {
+				t.Errorf("%d status requests made, want %d", tt.failures-left, tt.failures-tt.wantLeft)
+			}
+		})
+	}
+}
+
+func TestReplaceOrderTellsPartialFromFullFills(t *testing.T) {
+	tests := []struct {
+		name                string
+		afterCancel         CoinbaseOrder
+		wantErr             error
+		wantFilledSize      string
+		wantReplacementSent bool
+	}{
+		{"nothing filled", CoinbaseOrder{OrderID: "gtc-1", Status: "CANCELLED", FilledSize: "0"}, nil, "", true},
+		{"partially filled", CoinbaseOrder{OrderID: "gtc-1", Status: "CANCELLED", FilledSize: "0.04"}, ErrOrderPartiallyFilled, "0.04", false},
+		{"fully filled", CoinbaseOrder{OrderID: "gtc-1", Status: "FILLED", FilledSize: "0.1"}, ErrOrderAlreadyFilled, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var open CoinbaseOrder
+			json.Unmarshal([]byte(`{"order_id":"gtc-1","side":"BUY","status":"OPEN",
+				"order_configuration":{"limit_limit_gtc":{"base_size":"0.1","limit_price":"49000"}}}`), &open)
+			fake := newFakeCoinbase()
+			fake.orderStatuses["gtc-1"] = open
+			// The order reads as OPEN until Coinbase takes the cancel
+			c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fake.ServeHTTP(w, r)
+				if strings.HasSuffix(r.URL.Path, "/orders/batch_cancel") {
+					fake.mutex.Lock()
+					fake.orderStatuses["gtc-1"] = tt.afterCancel
+					fake.mutex.Unlock()
+				}
+			}))
+			c.orderStatusPollTimeout, c.orderStatusPollInterval = 0, time.Millisecond
+
+			_, err := c.ReplaceOrder("gtc-1", "", 48000)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReplaceOrder = %v, want %v", err, tt.wantErr)
+			}
+			var partialFill *PartialFillError
+			if got := errors.As(err, &partialFill); got != (tt.wantFilledSize != "") || (got && partialFill.FilledSize != tt.wantFilledSize) {
+				t.Errorf("partial fill error = %+v, want filled size %q", partialFill, tt.wantFilledSize)
+			}
+			if sent := len(fake.orders) > 0; sent != tt.wantReplacementSent {
+				t.Errorf("replacement placed = %v, want %v", sent, tt.wantReplacementSent)
+			}
+		})
+	}
+}
+
+func TestGraphSummaryWithoutData(t *testing.T) {
+	c := &CoinbaseClient{}
+	buy := Trade{ID: "1", Side: "BUY", Size: "0.1", Price: "50000", FilledValue: "5000", Fee: "5", ExecutedAt: 1}
+	unusable := []Candle{{Start: "1704067200", Close: ""}, {Start: "1704067500", Close: "0"}}
+
+	tests := []struct {
+		name                                  string
+		candles                               []Candle
+		trades                                []Trade
+		values                                []AccountValue
+		hasTrades, hasPriceData, hasValueData bool
+	}{
+		{"nothing", nil, nil, nil, false, false, false},
+		{"only unusable closes", unusable, nil, nil, false, false, false},
+		{"trades without candles", nil, []Trade{buy}, nil, true, false, false},
+		{"candles without trades", candlesFromCloses([]float64{100, 110}), nil, nil, false, true, false},
+		{"only account values", nil, nil, []AccountValue{{TotalValue: 0}, {TotalValue: 0}}, false, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := c.CalculateGraphSummary(tt.candles, tt.trades, tt.values)
+			if summary.HasTrades != tt.hasTrades || summary.HasPriceData != tt.hasPriceData || summary.HasValueData != tt.hasValueData {
+				t.Errorf("has trades/price/value data = %v/%v/%v, want %v/%v/%v", summary.HasTrades, summary.HasPriceData,
+					summary.HasValueData, tt.hasTrades, tt.hasPriceData, tt.hasValueData)
+			}
+			if !tt.hasPriceData && (!summary.BestPrice.IsZero() || !summary.WorstPrice.IsZero() || !summary.AveragePrice.IsZero()) {
+				t.Errorf("price stats without price data: %+v", summary)
+			}
+			if !tt.hasPriceData && !summary.UnrealizedPnL.IsZero() {
+				t.Errorf("unrealized P&L %v without a price to mark open buys", summary.UnrealizedPnL)
+			}
+			if summary.ValueChangePct != 0 {
+				t.Errorf("value change %v%% from a zero or missing starting value", summary.ValueChangePct)
+			}
+			if _, err := json.Marshal(summary); err != nil {
+				t.Errorf("summary doesn't serialize: %v", err)
+			}
+		})
+	}
+}
+
+func TestGraphSummaryWithMixedTrades(t *testing.T) {
+	c := &CoinbaseClient{}
+	// Two buys, then a sell of the first and half of the second, with a 0.1% fee on each trade
+	trades := []Trade{
+		{ID: "b1", Side: "BUY", FilledSize: "0.3", Price: "100.1", FilledValue: "30.03", Fee: "0.03003", ExecutedAt: 1},
+		{ID: "b2", Side: "BUY", FilledSize: "0.2", Price: "100.2", FilledValue: "20.04", Fee: "0.02004", ExecutedAt: 2},
+		{ID: "s1", Side: "SELL", FilledSize: "0.4", Price: "100.3", FilledValue: "40.12", Fee: "0.04012", ExecutedAt: 3},
+	}
+
+	summary := c.CalculateGraphSummary(candlesFromCloses([]float64{100.3, 100.4}), trades, nil)
+	if summary.BuyTrades != 2 || summary.SellTrades != 1 {
+		t.Errorf("%d buys and %d sells, want 2 and 1", summary.BuyTrades, summary.SellTrades)
+	}
+	// Round trips of 0.3 (-0.00012) and 0.1 (-0.01005); the 0.1 left is marked at 100.4 (+0.00998)
+	for _, amount := range []struct {
+		name string
+		got  Money
+		want string
+	}{
+		{"total_volume", summary.TotalVolume, "90.19"},
+		{"total_fees", summary.TotalFees, "0.09019"},
+		{"realized_pnl", summary.RealizedPnL, "-0.01017"},
+		{"unrealized_pnl", summary.UnrealizedPnL, "0.00998"},
+		{"fees_paid", summary.FeesPaid, "0.09019"},
+	} {
+		if amount.got.String() != amount.want {
+			t.Errorf("%s = %s, want %s", amount.name, amount.got, amount.want)
+		}
+	}
+
+	// The trade stats add up the same round trips
+	stats := calculateTradeStats(trades)
+	if stats.RoundTripCount != 2 || stats.Losses != 2 || stats.RealizedPnL.String() != "-0.01017" || stats.AverageLoss.String() != "-0.005085" {
+		t.Errorf("stats = %d round trips, %d losses, realized %s, average loss %s, want 2, 2, -0.01017, -0.005085",
+			stats.RoundTripCount, stats.Losses, stats.RealizedPnL, stats.AverageLoss)
+	}
+}
+
+func TestTradeStatsWithoutTrades(t *testing.T) {
+	stats := calculateTradeStats(nil)
+	if stats.TotalTrades != 0 || stats.RoundTripCount != 0 || stats.WinRate != 0 || !stats.RealizedPnL.IsZero() {
+		t.Errorf("stats without trades = %+v, want zeros", stats)
+	}
+	// An empty list, not null, so clients can iterate it
+	data, _ := json.Marshal(stats)
+	if !strings.Contains(string(data), `"round_trips":[]`) {
+		t.Errorf("stats = %s, want an empty round_trips list", data)
+	}
+}
+
+func TestSpreadGuard(t *testing.T) {
+	tests := []struct {
+		name         string
+		ask          string
+		maxSpreadBps float64
+		rejected     bool
+	}{
+		{"wide spread", "50500", 50, true}, // About 99.5 bps
+		{"tight spread", "50010", 50, false},
+		{"guard disabled", "50500", 0, false},
+		{"empty ask", "", 50, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeCoinbase()
+			fake.ask = tt.ask
+			c := newTestClient(t, fake)
+			c.maxSpreadBps = tt.maxSpreadBps
+
+			_, err := c.BuyBTC("0.01", 50000, OrderOptions{ImmediateOrCancel: true})
+			if tt.rejected {
+				if !errors.Is(err, ErrSpreadTooWide) {
+					t.Errorf("BuyBTC = %v, want ErrSpreadTooWide", err)
+				}
+				if len(fake.orders) != 0 {
+					t.Errorf("%d orders reached Coinbase despite the wide spread", len(fake.orders))
+				}
+			} else if err != nil {
+				t.Errorf("BuyBTC = %v, want it placed", err)
+			}
+		})
+	}
+}
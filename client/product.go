@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// CoinbaseProduct represents the raw product response from Coinbase API
+type CoinbaseProduct struct {
+	ProductID                string `json:"product_id"`
+	Price                    string `json:"price"`
+	LastPrice                string `json:"last_price"` // Legacy field, Price is preferred
+	Volume24h                string `json:"volume_24h"`
+	PricePercentageChange24h string `json:"price_percentage_change_24h"`
+	BaseIncrement            string `json:"base_increment"`
+	QuoteIncrement           string `json:"quote_increment"`
+}
+
+// getProduct retrieves the raw product information for the configured trading pair
+func (c *CoinbaseClient) getProduct() (*CoinbaseProduct, error) {
+	return c.getProductByID(c.tradingPair)
+}
+
+// getProductByID retrieves the raw product information for any product (e.g. a cross rate like USDC-EUR)
+func (c *CoinbaseClient) getProductByID(productID string) (*CoinbaseProduct, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	respBody, err := c.makeRequest(ctx, "GET", "/products/"+productID, nil)
+	if err != nil {
+		c.logger.Printf("Error fetching product info: %v", err)
+		return nil, fmt.Errorf("failed to fetch product info: %w", err)
+	}
+
+	var product CoinbaseProduct
+	if err := decodeJSON(respBody, &product, "product info"); err != nil {
+		return nil, err
+	}
+
+	if product.Price == "" {
+		product.Price = product.LastPrice
+	}
+
+	return &product, nil
+}
+
+// fetchProductStats retrieves price, 24h open/high/low, volume and percent change from Coinbase, bypassing the cache
+func (c *CoinbaseClient) fetchProductStats() (*ProductStats, error) {
+	// Log product stats fetching in debug mode
+	if c.debug {
+		c.logger.Printf("Fetching product stats for %s...", c.tradingPair)
+	}
+
+	product, err := c.getProduct()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &ProductStats{
+		ProductID:                product.ProductID,
+		Price:                    product.Price,
+		Volume24h:                product.Volume24h,
+		PricePercentageChange24h: product.PricePercentageChange24h,
+		Timestamp:                time.Now().Unix(),
+	}
+
+	// Derive 24h open/high/low from hourly candles (optional - continue even if it fails)
+	endTime := time.Now()
+	startTime := endTime.Add(-24 * time.Hour)
+	candles, err := c.GetCandles(
+		fmt.Sprintf("%d", startTime.Unix()),
+		fmt.Sprintf("%d", endTime.Unix()),
+		"ONE_HOUR",
+		candlesForSpan(24*time.Hour, "ONE_HOUR"),
+	)
+	if err != nil {
+		if c.debug {
+			c.logger.Printf("Warning: Failed to fetch candles for 24h stats: %v", err)
+		}
+		return stats, nil
+	}
+
+	if len(candles) > 0 {
+		stats.Open24h = candles[0].Open // Candles are oldest-first
+
+		var high, low float64
+		for i, candle := range candles {
+			candleHigh, _ := strconv.ParseFloat(candle.High, 64)
+			candleLow, _ := strconv.ParseFloat(candle.Low, 64)
+			if i == 0 || candleHigh > high {
+				high = candleHigh
+			}
+			if i == 0 || candleLow < low {
+				low = candleLow
+			}
+		}
+		stats.High24h = fmt.Sprintf("%.8f", high)
+		stats.Low24h = fmt.Sprintf("%.8f", low)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// defaultRateLimitSlowdownRemaining is the remaining request count below which the limiter is halved
+const defaultRateLimitSlowdownRemaining = 5
+
+// RateLimitStatus is the latest rate-limit information returned by Coinbase
+type RateLimitStatus struct {
+	Limit     int    `json:"limit"`
+	Remaining int    `json:"remaining"`
+	Reset     string `json:"reset,omitempty"` // As sent by Coinbase (seconds or a timestamp)
+	UpdatedAt int64  `json:"updated_at"`
+	Throttled bool   `json:"throttled"` // The limiter runs at half COINBASE_RPS because remaining is low
+}
+
+// recordRateLimitHeaders stores the x-ratelimit-* response headers, if present, and halves the shared limiter
+// while the remaining count is below RATE_LIMIT_SLOWDOWN_REMAINING, restoring COINBASE_RPS once it recovers
+func (c *CoinbaseClient) recordRateLimitHeaders(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-Ratelimit-Remaining"))
+	if err != nil {
+		return
+	}
+	limit, _ := strconv.Atoi(header.Get("X-Ratelimit-Limit"))
+
+	c.rateLimitMux.Lock()
+	defer c.rateLimitMux.Unlock()
+
+	throttle := c.rateLimitSlowdownRemaining > 0 && remaining < c.rateLimitSlowdownRemaining
+	if c.rateLimiter != nil && throttle != c.rateLimitStatus.Throttled {
+		if throttle {
+			c.rateLimiter.SetLimit(c.baseRPS / 2)
+			c.logger.Printf("[WARN] Coinbase rate limit low (%d/%d remaining), slowing to %.1f req/s", remaining, limit, float64(c.baseRPS/2))
+		} else {
+			c.rateLimiter.SetLimit(c.baseRPS)
+			c.logger.Printf("Coinbase rate limit recovered (%d/%d remaining), back to %.1f req/s", remaining, limit, float64(c.baseRPS))
+		}
+	}
+
+	c.rateLimitStatus = RateLimitStatus{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     header.Get("X-Ratelimit-Reset"),
+		UpdatedAt: time.Now().Unix(),
+		Throttled: throttle,
+	}
+
+	if c.debug {
+		c.logger.Printf("Rate limit: %d/%d remaining, reset %s", remaining, limit, c.rateLimitStatus.Reset)
+	}
+}
+
+// GetRateLimitStatus returns the latest rate-limit headers seen, or nil if Coinbase hasn't sent any
+func (c *CoinbaseClient) GetRateLimitStatus() *RateLimitStatus {
+	c.rateLimitMux.Lock()
+	defer c.rateLimitMux.Unlock()
+	if c.rateLimitStatus.UpdatedAt == 0 {
+		return nil
+	}
+	status := c.rateLimitStatus
+	return &status
+}
+
+// currentRPS returns the request rate the limiter currently allows
+func (c *CoinbaseClient) currentRPS() rate.Limit {
+	if c.rateLimiter == nil {
+		return 0
+	}
+	return c.rateLimiter.Limit()
+}
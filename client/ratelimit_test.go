@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimiterHoldsConcurrentCallsToTheRate(t *testing.T) {
+	const calls, rps = 6, 20
+	fake := newFakeCoinbase()
+	c := newTestClient(t, fake)
+	c.baseRPS = rps
+	c.rateLimiter = rate.NewLimiter(rps, 1)
+
+	started := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.makeRequest(context.Background(), "GET", "/accounts", nil); err != nil {
+				t.Errorf("makeRequest: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The first call uses the burst token, each following one waits 1/rps
+	if elapsed, min := time.Since(started), time.Duration(calls-1)*time.Second/rps; elapsed < min {
+		t.Errorf("%d calls at %d req/s took %v, want at least %v", calls, rps, elapsed, min)
+	}
+	if n := fake.called("GET /accounts"); n != calls {
+		t.Errorf("%d requests reached Coinbase, want %d", n, calls)
+	}
+}
+
+func TestRateLimiterWaitRespectsTheContext(t *testing.T) {
+	fake := newFakeCoinbase()
+	c := newTestClient(t, fake)
+	c.rateLimiter = rate.NewLimiter(1, 1)
+	if _, err := c.makeRequest(context.Background(), "GET", "/accounts", nil); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+
+	// The next token is a second away; a shorter deadline gives up instead of waiting for it
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.makeRequest(ctx, "GET", "/accounts", nil); err == nil {
+		t.Errorf("request past the deadline = %v, want a rate limiter wait error", err)
+	}
+	if n := fake.called("GET /accounts"); n != 1 {
+		t.Errorf("%d requests reached Coinbase, want only the first", n)
+	}
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// defaultRebalanceTolerancePct is how far (in percentage points of the portfolio) the base allocation
+// may drift from the target before a rebalance places an order
+const defaultRebalanceTolerancePct = 1.0
+
+// Rebalance computes the trade needed to move the base currency allocation to targetBasePct percent of the
+// portfolio and places it as an IOC limit order at the best bid/ask. Nothing is placed when the allocation is
+// already within tolerancePct (zero uses the default), or when dryRun or DRY_RUN is set.
+func (c *CoinbaseClient) Rebalance(targetBasePct, tolerancePct float64, dryRun bool) (*RebalanceResult, error) {
+	if targetBasePct < 0 || targetBasePct > 100 {
+		return nil, fmt.Errorf("target_base_pct must be between 0 and 100")
+	}
+	if tolerancePct <= 0 {
+		tolerancePct = defaultRebalanceTolerancePct
+	}
+	dryRun = dryRun || c.dryRun
+	if !dryRun {
+		done, err := c.beginTrade()
+		if err != nil {
+			return nil, err
+		}
+		defer done()
+	}
+
+	parts := strings.Split(c.tradingPair, "-")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid trading pair format: %s", c.tradingPair)
+	}
+
+	accounts, err := c.GetAccounts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch accounts: %w", err)
+	}
+	var baseBalance, quoteBalance decimal.Decimal
+	for _, account := range accounts {
+		switch account.Currency {
+		case parts[0]:
+			baseBalance = parseDecimal(account.AvailableBalance)
+		case parts[1]:
+			quoteBalance = parseDecimal(account.AvailableBalance)
+		}
+	}
+
+	// Value the portfolio at the mid price, trade at the side of the book we'd cross
+	orderBook, err := c.GetOrderBook(1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order book: %w", err)
+	}
+	if len(orderBook.Bids) == 0 || len(orderBook.Asks) == 0 {
+		return nil, fmt.Errorf("order book is empty, cannot price a rebalance")
+	}
+	bestBid := parseDecimal(orderBook.Bids[0].Price)
+	bestAsk := parseDecimal(orderBook.Asks[0].Price)
+	if !bestBid.IsPositive() || !bestAsk.IsPositive() {
+		return nil, fmt.Errorf("invalid best bid/ask: %s/%s", orderBook.Bids[0].Price, orderBook.Asks[0].Price)
+	}
+	midPrice := bestBid.Add(bestAsk).Div(decimal.NewFromInt(2))
+
+	baseValue := baseBalance.Mul(midPrice)
+	totalValue := baseValue.Add(quoteBalance)
+	if !totalValue.IsPositive() {
+		return nil, fmt.Errorf("no %s or %s balance to rebalance", parts[0], parts[1])
+	}
+
+	hundred := decimal.NewFromInt(100)
+	currentBasePct := baseValue.Div(totalValue).Mul(hundred)
+	targetValue := totalValue.Mul(decimal.NewFromFloat(targetBasePct)).Div(hundred)
+	diff := targetValue.Sub(baseValue)
+
+	plan := &RebalancePlan{
+		CurrentBasePct: currentBasePct.InexactFloat64(),
+		TargetBasePct:  targetBasePct,
+		TotalValue:     Money{totalValue},
+	}
+	result := &RebalanceResult{Plan: plan, DryRun: dryRun}
+
+	if diff.Abs().Div(totalValue).Mul(hundred).LessThanOrEqual(decimal.NewFromFloat(tolerancePct)) {
+		result.NoOp = true
+		result.Reason = fmt.Sprintf("allocation %.2f%% is within %.2f%% of the %.2f%% target",
+			plan.CurrentBasePct, tolerancePct, targetBasePct)
+		return result, nil
+	}
+
+	var price, size decimal.Decimal
+	if diff.IsPositive() {
+		// Buying: spend the difference, net of fees, without exceeding the available quote balance
+		plan.Side = "BUY"
+		price = bestAsk
+		tradeValue := decimal.Min(diff, quoteBalance)
+		fee := c.calculateCoinbaseFee(tradeValue)
+		plan.Fee = Money{fee}
+		size = tradeValue.Sub(fee).Div(price)
+	} else {
+		// Selling: fees come out of the proceeds, so the base amount is the difference itself
+		plan.Side = "SELL"
+		price = bestBid
+		size = decimal.Min(diff.Abs().Div(price), baseBalance)
+		plan.Fee = Money{c.calculateCoinbaseFee(size.Mul(price))}
+	}
+
+	increment := c.baseIncrement()
+	size = floorToIncrement(size, increment)
+	if !size.IsPositive() {
+		result.NoOp = true
+		result.Reason = fmt.Sprintf("required trade is below the base increment %s", increment)
+		return result, nil
+	}
+
+	plan.Size = size.StringFixed(8)
+	plan.Price = Money{price}
+	plan.Value = Money{size.Mul(price)}
+
+	if dryRun {
+		result.Reason = "dry run, no order placed"
+		c.logger.Printf("Rebalance (dry run): %s %s @ %s to move %s from %.2f%% to %.2f%%",
+			plan.Side, plan.Size, plan.Price, parts[0], plan.CurrentBasePct, targetBasePct)
+		return result, nil
+	}
+
+	order, err := c.createOrder(plan.Side, plan.Size, plan.Price.InexactFloat64(), OrderOptions{ImmediateOrCancel: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to place rebalance %s order: %w", plan.Side, err)
+	}
+	result.Executed = order
+
+	c.logger.Printf("Rebalance Order Result: %s", c.GetOrderResult(order))
+	return result, nil
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidIndicators is returned by SimulateSignal for indicator values no candle series could produce
+var ErrInvalidIndicators = errors.New("invalid indicators")
+
+// validateSimulatedIndicators checks hypothetical indicators are in range and fills in the triangle fields,
+// where an empty value means no pattern
+func validateSimulatedIndicators(indicators *TechnicalIndicators) error {
+	if indicators.TrianglePattern == "" {
+		indicators.TrianglePattern = "none"
+	}
+	if indicators.TriangleBreakout == "" {
+		indicators.TriangleBreakout = "none"
+	}
+
+	switch {
+	case indicators.CurrentPrice <= 0:
+		return fmt.Errorf("%w: current_price must be positive", ErrInvalidIndicators)
+	case indicators.EMA12 <= 0 || indicators.EMA26 <= 0:
+		return fmt.Errorf("%w: ema_12 and ema_26 must be positive", ErrInvalidIndicators)
+	case indicators.EMA200 < 0:
+		return fmt.Errorf("%w: ema_200 must not be negative", ErrInvalidIndicators)
+	case indicators.RSI < 0 || indicators.RSI > 100:
+		return fmt.Errorf("%w: rsi must be between 0 and 100", ErrInvalidIndicators)
+	case indicators.ADX < 0 || indicators.ADX > 100:
+		return fmt.Errorf("%w: adx must be between 0 and 100", ErrInvalidIndicators)
+	case indicators.Volatility < 0 || indicators.BaselineVolatility < 0:
+		return fmt.Errorf("%w: volatility and baseline_volatility must not be negative", ErrInvalidIndicators)
+	case indicators.TriangleStrength < 0 || indicators.TriangleStrength > 1:
+		return fmt.Errorf("%w: triangle_strength must be between 0 and 1", ErrInvalidIndicators)
+	}
+
+	switch indicators.TrianglePattern {
+	case "none", "ascending", "descending", "symmetrical":
+	default:
+		return fmt.Errorf("%w: triangle_pattern must be one of: none, ascending, descending, symmetrical", ErrInvalidIndicators)
+	}
+	switch indicators.TriangleBreakout {
+	case "none", "bullish", "bearish":
+	default:
+		return fmt.Errorf("%w: triangle_breakout must be one of: none, bullish, bearish", ErrInvalidIndicators)
+	}
+	return nil
+}
+
+// SimulateSignal scores hypothetical indicators with the configured weights and thresholds and returns the
+// classification the signal detectors would make. Nothing is fetched and no state, cooldown or webhook is
+// touched, so it can be used to try out scoring weights.
+func (c *CoinbaseClient) SimulateSignal(indicators TechnicalIndicators) (*SignalSimulation, error) {
+	if err := validateSimulatedIndicators(&indicators); err != nil {
+		return nil, err
+	}
+
+	trend := c.determineTrendState(indicators)
+	dipScore, dipTriggers := scoreImmediateDip(indicators, c.scoringWeights.Dip)
+	simulation := &SignalSimulation{
+		BearishScore:   c.calculateBearishScore(indicators),
+		BullishScore:   c.calculateBullishScore(indicators),
+		Threshold:      c.effectiveThreshold(indicators),
+		Trend:          trend,
+		Recommendation: recommendationForTrend(trend),
+		Triggers:       []string{},
+		DipScore:       dipScore,
+		DipThreshold:   c.scoringWeights.Dip.Threshold,
+		DipDetected:    dipScore >= c.scoringWeights.Dip.Threshold,
+		DipTriggers:    []string{},
+	}
+	if trend != "neutral" {
+		simulation.Triggers = append(simulation.Triggers, c.calculateTriggers(indicators, trend)...)
+	}
+	simulation.DipTriggers = append(simulation.DipTriggers, dipTriggers...)
+	return simulation, nil
+}
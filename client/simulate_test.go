@@ -0,0 +1,86 @@
+package client
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSimulateSignal(t *testing.T) {
+	c := &CoinbaseClient{indicatorPeriods: defaultIndicatorPeriods(), scoringWeights: defaultScoringWeights()}
+
+	tests := []struct {
+		name        string
+		indicators  TechnicalIndicators
+		trend       string
+		action      string
+		triggers    []string
+		dipDetected bool
+	}{
+		{
+			name: "sell-off below the trend EMA",
+			indicators: TechnicalIndicators{
+				CurrentPrice: 90, EMA12: 95, EMA26: 100, EMA200: 110,
+				MACD: -2, SignalLine: -1, RSI: 25, PriceDropPct12h: -6, ADX: 30,
+			},
+			trend:  "bearish",
+			action: "SELL",
+			triggers: []string{
+				"MACD_BEARISH_CROSSOVER", "EMA_BEARISH_CROSSOVER", "RSI_MOMENTUM_BREAKDOWN",
+				"PRICE_TREND_REVERSAL", "MAJOR_TREND_BREAKDOWN",
+			},
+			dipDetected: true,
+		},
+		{
+			name: "rally above the trend EMA",
+			indicators: TechnicalIndicators{
+				CurrentPrice: 110, EMA12: 105, EMA26: 100, EMA200: 90,
+				MACD: 2, SignalLine: 1, RSI: 75, PriceDropPct12h: 6, ADX: 30,
+			},
+			trend:  "bullish",
+			action: "BUY",
+			triggers: []string{
+				"MACD_BULLISH_CROSSOVER", "EMA_BULLISH_CROSSOVER", "RSI_MOMENTUM_BUILDUP",
+				"PRICE_TREND_REVERSAL", "MAJOR_TREND_BREAKOUT",
+			},
+		},
+		{
+			name: "flat market",
+			indicators: TechnicalIndicators{
+				CurrentPrice: 100, EMA12: 100, EMA26: 100, EMA200: 100, RSI: 50, ADX: 15,
+			},
+			trend:    "neutral",
+			action:   "HOLD",
+			triggers: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			simulation, err := c.SimulateSignal(tt.indicators)
+			if err != nil {
+				t.Fatalf("SimulateSignal: %v", err)
+			}
+			if simulation.Trend != tt.trend || simulation.Recommendation != tt.action {
+				t.Errorf("trend, recommendation = %s, %s, want %s, %s (bearish %.2f, bullish %.2f, threshold %.2f)",
+					simulation.Trend, simulation.Recommendation, tt.trend, tt.action,
+					simulation.BearishScore, simulation.BullishScore, simulation.Threshold)
+			}
+			if !reflect.DeepEqual(simulation.Triggers, tt.triggers) {
+				t.Errorf("triggers = %v, want %v", simulation.Triggers, tt.triggers)
+			}
+			if simulation.DipDetected != tt.dipDetected {
+				t.Errorf("dip detected = %v (score %.2f of %.2f, triggers %v), want %v",
+					simulation.DipDetected, simulation.DipScore, simulation.DipThreshold, simulation.DipTriggers, tt.dipDetected)
+			}
+		})
+	}
+
+	// Simulating touches no detector state
+	if !c.lastSignalTime.IsZero() || c.lastTrendState != "" {
+		t.Errorf("simulation changed the trend state: %q at %v", c.lastTrendState, c.lastSignalTime)
+	}
+
+	if _, err := c.SimulateSignal(TechnicalIndicators{CurrentPrice: 100, EMA12: 100, EMA26: 100, RSI: 120}); !errors.Is(err, ErrInvalidIndicators) {
+		t.Errorf("RSI 120 = %v, want ErrInvalidIndicators", err)
+	}
+}
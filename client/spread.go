@@ -0,0 +1,78 @@
+package client
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxSpreadSamples caps the in-memory spread history
+const maxSpreadSamples = 1000
+
+// recordSpreadSample adds a spread sample to the rolling history
+func (c *CoinbaseClient) recordSpreadSample(spread, spreadPercent float64) {
+	c.spreadHistoryMutex.Lock()
+	defer c.spreadHistoryMutex.Unlock()
+
+	// Keep only the most recent samples to prevent memory bloat
+	if len(c.spreadHistory) >= maxSpreadSamples {
+		c.spreadHistory = c.spreadHistory[1:]
+	}
+
+	c.spreadHistory = append(c.spreadHistory, SpreadSample{
+		Timestamp:     time.Now().Unix(),
+		Spread:        spread,
+		SpreadPercent: spreadPercent,
+	})
+}
+
+// SampleSpread fetches the top of the order book so the spread gets recorded
+func (c *CoinbaseClient) SampleSpread() error {
+	// Bypass the market state cache, a cached snapshot wouldn't record a new sample
+	if _, err := c.fetchMarketState(1); err != nil {
+		return fmt.Errorf("failed to sample spread: %w", err)
+	}
+	return nil
+}
+
+// GetSpreadHistory returns the recorded spread samples with min/max/avg statistics
+func (c *CoinbaseClient) GetSpreadHistory() SpreadHistory {
+	c.spreadHistoryMutex.RLock()
+	samples := make([]SpreadSample, len(c.spreadHistory))
+	copy(samples, c.spreadHistory)
+	c.spreadHistoryMutex.RUnlock()
+
+	history := SpreadHistory{
+		ProductID: c.tradingPair,
+		Samples:   samples,
+		Count:     len(samples),
+	}
+
+	if len(samples) == 0 {
+		return history
+	}
+
+	minSpread, maxSpread := samples[0].Spread, samples[0].Spread
+	history.MinSpreadPercent = samples[0].SpreadPercent
+	history.MaxSpreadPercent = samples[0].SpreadPercent
+
+	var spreadSum, spreadPercentSum float64
+	for _, sample := range samples {
+		minSpread = min(minSpread, sample.Spread)
+		maxSpread = max(maxSpread, sample.Spread)
+		if sample.SpreadPercent < history.MinSpreadPercent {
+			history.MinSpreadPercent = sample.SpreadPercent
+		}
+		if sample.SpreadPercent > history.MaxSpreadPercent {
+			history.MaxSpreadPercent = sample.SpreadPercent
+		}
+		spreadSum += sample.Spread
+		spreadPercentSum += sample.SpreadPercent
+	}
+
+	history.MinSpread = moneyFromFloat(minSpread)
+	history.MaxSpread = moneyFromFloat(maxSpread)
+	history.AvgSpread = moneyFromFloat(spreadSum / float64(len(samples)))
+	history.AvgSpreadPercent = spreadPercentSum / float64(len(samples))
+
+	return history
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// openLot is a (partially) unmatched buy waiting for a sell in the FIFO queue
+type openLot struct {
+	tradeID    string
+	size       decimal.Decimal
+	price      decimal.Decimal
+	feePerUnit decimal.Decimal
+	executedAt int64
+}
+
+// GetTradeStats pairs buys with later sells first-in-first-out over a graph period (week or month)
+// and returns realized P&L per round trip with aggregate win rate, averages and fees.
+// Buys still open at the end of the period are reported separately and excluded from realized stats.
+func (c *CoinbaseClient) GetTradeStats(period string) (*TradeStats, error) {
+	startTime, endTime, _, err := graphPeriodRange(period)
+	if err != nil {
+		return nil, err
+	}
+
+	trades, err := c.GetTradeHistory(startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trade history: %w", err)
+	}
+
+	stats := calculateTradeStats(trades)
+	stats.ProductID = c.tradingPair
+	stats.Period = period
+	return stats, nil
+}
+
+// fifoMatch is the outcome of matching sells against the oldest open buys
+type fifoMatch struct {
+	roundTrips    []RoundTrip
+	openLots      []openLot       // Buys (or their remainders) not sold yet
+	unmatchedSell decimal.Decimal // Sold size with no earlier buy in the trades
+	totalFees     decimal.Decimal // Fees of every trade, matched or not
+}
+
+// matchTradesFIFO pairs sells with the oldest open buys, in execution order
+func matchTradesFIFO(trades []Trade) fifoMatch {
+	sorted := make([]Trade, len(trades))
+	copy(sorted, trades)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ExecutedAt < sorted[j].ExecutedAt
+	})
+
+	roundTrips := []RoundTrip{}
+	var lots []openLot
+	totalFees := decimal.Zero
+	unmatchedSell := decimal.Zero
+
+	for _, trade := range sorted {
+		size := parseDecimal(trade.FilledSize)
+		price := parseDecimal(trade.Price)
+		fee := parseDecimal(trade.Fee)
+		totalFees = totalFees.Add(fee)
+		if !size.IsPositive() {
+			continue
+		}
+		feePerUnit := fee.Div(size)
+
+		if trade.Side == "BUY" {
+			lots = append(lots, openLot{
+				tradeID:    trade.ID,
+				size:       size,
+				price:      price,
+				feePerUnit: feePerUnit,
+				executedAt: trade.ExecutedAt,
+			})
+			continue
+		}
+
+		// SELL: consume the oldest open buys
+		remaining := size
+		for remaining.IsPositive() && len(lots) > 0 {
+			lot := &lots[0]
+			matched := decimal.Min(remaining, lot.size)
+
+			fees := lot.feePerUnit.Add(feePerUnit).Mul(matched)
+			pnl := price.Sub(lot.price).Mul(matched).Sub(fees)
+			cost := lot.price.Mul(matched)
+			var pnlPct float64
+			if cost.IsPositive() {
+				pnlPct = pnl.Div(cost).Mul(decimal.NewFromInt(100)).InexactFloat64()
+			}
+
+			roundTrips = append(roundTrips, RoundTrip{
+				BuyTradeID:     lot.tradeID,
+				SellTradeID:    trade.ID,
+				Size:           matched.StringFixed(8),
+				BuyPrice:       Money{lot.price},
+				SellPrice:      Money{price},
+				Fees:           Money{fees},
+				RealizedPnL:    Money{pnl},
+				RealizedPnLPct: pnlPct,
+				BuyTime:        lot.executedAt,
+				SellTime:       trade.ExecutedAt,
+				HoldingSeconds: trade.ExecutedAt - lot.executedAt,
+			})
+
+			lot.size = lot.size.Sub(matched)
+			remaining = remaining.Sub(matched)
+			if !lot.size.IsPositive() {
+				lots = lots[1:]
+			}
+		}
+		// Sells of coins bought before the period have no matching buy
+		unmatchedSell = unmatchedSell.Add(remaining)
+	}
+
+	return fifoMatch{roundTrips: roundTrips, openLots: lots, unmatchedSell: unmatchedSell, totalFees: totalFees}
+}
+
+// calculateTradeStats matches sells against the oldest open buys and aggregates the round trips
+func calculateTradeStats(trades []Trade) *TradeStats {
+	match := matchTradesFIFO(trades)
+	stats := &TradeStats{RoundTrips: match.roundTrips}
+
+	// Aggregate the realized round trips
+	totalPnL, winSum, lossSum := decimal.Zero, decimal.Zero, decimal.Zero
+	var holdingSum int64
+	for _, trip := range stats.RoundTrips {
+		totalPnL = totalPnL.Add(trip.RealizedPnL.Decimal)
+		holdingSum += trip.HoldingSeconds
+		if trip.RealizedPnL.IsPositive() {
+			stats.Wins++
+			winSum = winSum.Add(trip.RealizedPnL.Decimal)
+		} else {
+			stats.Losses++
+			lossSum = lossSum.Add(trip.RealizedPnL.Decimal)
+		}
+	}
+
+	stats.TotalTrades = len(trades)
+	stats.RoundTripCount = len(stats.RoundTrips)
+	stats.RealizedPnL = Money{totalPnL}
+	stats.TotalFees = Money{match.totalFees}
+	if stats.RoundTripCount > 0 {
+		stats.WinRate = float64(stats.Wins) / float64(stats.RoundTripCount) * 100
+		stats.AverageHoldingSeconds = holdingSum / int64(stats.RoundTripCount)
+	}
+	if stats.Wins > 0 {
+		stats.AverageWin = Money{winSum.Div(decimal.NewFromInt(int64(stats.Wins)))}
+	}
+	if stats.Losses > 0 {
+		stats.AverageLoss = Money{lossSum.Div(decimal.NewFromInt(int64(stats.Losses)))}
+	}
+
+	openSize := decimal.Zero
+	for _, lot := range match.openLots {
+		openSize = openSize.Add(lot.size)
+	}
+	stats.OpenBuys = len(match.openLots)
+	stats.OpenSize = openSize.StringFixed(8)
+	stats.UnmatchedSellSize = match.unmatchedSell.StringFixed(8)
+	stats.Timestamp = time.Now().Unix()
+
+	return stats
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// GetSummary builds a compact status snapshot (price, trend, key indicators, portfolio value and last signal)
+// in a single call, with a pre-formatted text version suitable for chat messages. Like GetDipStatus it only
+// reads: the trend state, cooldown and webhooks are left to GetSignal.
+func (c *CoinbaseClient) GetSummary() (*Summary, error) {
+	indicators, err := c.signalIndicators(c.signalCandles, c.signalGranularity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signal: %w", err)
+	}
+	lastTrend, lastSignalTime := c.trendSnapshot()
+
+	summary := &Summary{
+		ProductID:    c.tradingPair,
+		Price:        moneyFromFloat(indicators.CurrentPrice),
+		Trend:        c.determineTrendState(indicators),
+		RSI:          indicators.RSI,
+		MACD:         indicators.MACD,
+		MACDSignal:   indicators.SignalLine,
+		Change12hPct: indicators.PriceDropPct12h,
+		LastSignal:   lastTrend,
+		Timestamp:    time.Now().Unix(),
+	}
+	if !lastSignalTime.IsZero() {
+		summary.LastSignalTime = lastSignalTime.Unix()
+	}
+
+	// Prefer the most recent tracked asset value, fall back to current balances
+	_, quote, _ := c.pairCurrencies()
+	summary.QuoteCurrency = quote
+	if history := c.GetAssetValueHistory(); len(history) > 0 {
+		summary.PortfolioQuote = moneyFromFloat(history[len(history)-1].TotalQuote)
+	} else if value, err := c.portfolioValue(indicators.CurrentPrice); err == nil {
+		summary.PortfolioQuote = moneyFromFloat(value)
+	} else if c.debug {
+		c.logger.Printf("Could not compute portfolio value for summary: %v", err)
+	}
+
+	// Only report a USD value when the quote currency converts to it
+	if rate, err := c.conversionRate(quote, "USD"); err == nil {
+		summary.PortfolioUSD = Money{summary.PortfolioQuote.Mul(decimal.NewFromFloat(rate))}
+	} else if c.debug {
+		c.logger.Printf("Could not value the summary portfolio in USD: %v", err)
+	}
+
+	summary.Text = formatSummaryText(summary)
+	return summary, nil
+}
+
+// portfolioValue returns the combined base and quote balance valued in the quote currency
+func (c *CoinbaseClient) portfolioValue(price float64) (float64, error) {
+	accounts, err := c.GetAccounts()
+	if err != nil {
+		return 0, err
+	}
+
+	parts := strings.Split(c.tradingPair, "-")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid trading pair format: %s", c.tradingPair)
+	}
+
+	var total float64
+	for _, account := range accounts {
+		balance := parseDecimal(account.AvailableBalance).InexactFloat64()
+		switch account.Currency {
+		case parts[0]:
+			total += balance * price
+		case parts[1]:
+			total += balance
+		}
+	}
+	return total, nil
+}
+
+// formatSummaryText renders a summary as a short multi-line message
+func formatSummaryText(s *Summary) string {
+	macdState := "below"
+	if s.MACD > s.MACDSignal {
+		macdState = "above"
+	}
+
+	lastSignal := "none yet"
+	if s.LastSignal != "" {
+		lastSignal = s.LastSignal
+		if s.LastSignalTime > 0 {
+			lastSignal += " since " + time.Unix(s.LastSignalTime, 0).UTC().Format("2006-01-02 15:04 UTC")
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s (%+.2f%% 12h)\n", s.ProductID, formatAmount(s.Price.InexactFloat64(), s.QuoteCurrency), s.Change12hPct)
+	fmt.Fprintf(&b, "Trend: %s\n", s.Trend)
+	fmt.Fprintf(&b, "RSI: %.1f | MACD %s signal (%.2f vs %.2f)\n", s.RSI, macdState, s.MACD, s.MACDSignal)
+	fmt.Fprintf(&b, "Portfolio: %s\n", formatAmount(s.PortfolioQuote.InexactFloat64(), s.QuoteCurrency))
+	fmt.Fprintf(&b, "Last signal: %s", lastSignal)
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSummaryPortfolioInTheQuoteCurrency(t *testing.T) {
+	fake := newFakeCoinbase()
+	fake.candles = candlesFromCloses(risingCloses(300))
+	c := newTestClient(t, fake)
+	c.closedCandlesOnly = false
+
+	// 1 BTC at the last close plus 10000 USDC, which converts to USD at par
+	summary, err := c.GetSummary()
+	if err != nil {
+		t.Fatalf("GetSummary: %v", err)
+	}
+	want := Money{summary.Price.Add(moneyFromFloat(10000).Decimal)}.String()
+	if summary.QuoteCurrency != "USDC" || summary.PortfolioQuote.String() != want || summary.PortfolioUSD.String() != want {
+		t.Errorf("portfolio = %s %s (USD %s), want %s USDC and the same in USD",
+			summary.PortfolioQuote, summary.QuoteCurrency, summary.PortfolioUSD, want)
+	}
+	if !strings.Contains(summary.Text, "Portfolio: $") {
+		t.Errorf("text = %q, want the portfolio in dollars", summary.Text)
+	}
+
+	// A EUR portfolio without a EUR-USD rate is shown in euros and has no USD value
+	eur := &Summary{ProductID: "BTC-EUR", Price: moneyFromFloat(45000), PortfolioQuote: moneyFromFloat(5000), QuoteCurrency: "EUR"}
+	text := formatSummaryText(eur)
+	if !strings.Contains(text, "BTC-EUR: €45000.00") || !strings.Contains(text, "Portfolio: €5000.00") || strings.Contains(text, "$") {
+		t.Errorf("text = %q, want the price and portfolio in euros", text)
+	}
+	data, err := json.Marshal(eur)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if s := string(data); strings.Contains(s, "portfolio_usd") || !strings.Contains(s, `"portfolio_quote":"5000"`) {
+		t.Errorf("summary = %s, want portfolio_quote and no portfolio_usd", s)
+	}
+}
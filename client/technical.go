@@ -2,11 +2,105 @@ package client
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"strconv"
 	"sync"
 )
 
+// IndicatorPeriods holds the lookback periods used by the indicator calculators.
+// The TechnicalIndicators JSON fields keep their ema_12/ema_26/ema_200 names regardless of the periods used.
+type IndicatorPeriods struct {
+	EMAShort   int `json:"ema_short"`
+	EMALong    int `json:"ema_long"`
+	EMATrend   int `json:"ema_trend"`
+	MACDFast   int `json:"macd_fast"`
+	MACDSlow   int `json:"macd_slow"`
+	MACDSignal int `json:"macd_signal"`
+	RSI        int `json:"rsi"`
+	ADX        int `json:"adx"`
+}
+
+// defaultIndicatorPeriods returns the classic EMA 12/26/200, MACD 12/26/9 and 14-period RSI/ADX settings
+func defaultIndicatorPeriods() IndicatorPeriods {
+	return IndicatorPeriods{
+		EMAShort:   12,
+		EMALong:    26,
+		EMATrend:   200,
+		MACDFast:   12,
+		MACDSlow:   26,
+		MACDSignal: 9,
+		RSI:        14,
+		ADX:        14,
+	}
+}
+
+// loadIndicatorPeriods reads indicator periods from the environment, keeping defaults for unset or invalid values
+func loadIndicatorPeriods() (IndicatorPeriods, error) {
+	defaults := defaultIndicatorPeriods()
+	periods := IndicatorPeriods{
+		EMAShort:   getEnvInt("EMA_SHORT", defaults.EMAShort),
+		EMALong:    getEnvInt("EMA_LONG", defaults.EMALong),
+		EMATrend:   getEnvInt("EMA_TREND", defaults.EMATrend),
+		MACDFast:   getEnvInt("MACD_FAST", defaults.MACDFast),
+		MACDSlow:   getEnvInt("MACD_SLOW", defaults.MACDSlow),
+		MACDSignal: getEnvInt("MACD_SIGNAL", defaults.MACDSignal),
+		RSI:        getEnvInt("RSI_PERIOD", defaults.RSI),
+		ADX:        getEnvInt("ADX_PERIOD", defaults.ADX),
+	}
+	return periods, periods.validate()
+}
+
+// hasTrendEMA reports whether the trend EMA (EMA200 by default) was calculated. It is zero when there were
+// fewer candles than its period, as in the 144-candle lightweight poll, or when early exit skipped it; price
+// comparisons against it are then meaningless and must not score or trigger.
+func (indicators TechnicalIndicators) hasTrendEMA() bool {
+	return indicators.EMA200 > 0
+}
+
+// priceDropPeriod is the lookback for PriceDropPct12h: 12 hours of 5-minute candles (144 * 5 minutes = 720 minutes)
+const priceDropPeriod = 144
+
+// minIndicatorCandles is the fewest candles calculateTechnicalIndicators accepts
+const minIndicatorCandles = 50
+
+// signalCandleBuffer is added on top of the longest lookback so the EMAs get a few candles to settle
+const signalCandleBuffer = 20
+
+// minSignalCandles returns how many candles GetSignal needs to feed every indicator: the longest lookback
+// (EMA trend, MACD, RSI, ADX, price drop, anomaly z-score) plus a small buffer, capped at one request
+func (p IndicatorPeriods) minSignalCandles() int {
+	needed := minIndicatorCandles
+	for _, n := range []int{
+		p.EMATrend,
+		p.MACDSlow + p.MACDSignal,
+		p.RSI + 1,
+		p.ADX + 1,
+		priceDropPeriod + 1,
+		p.EMALong + anomalyResidualWindow + 1,
+	} {
+		needed = max(needed, n)
+	}
+	return min(needed+signalCandleBuffer, maxCandlesPerRequest)
+}
+
+// validate checks that short periods are below long ones and the trend EMA fits in one candle request
+func (p IndicatorPeriods) validate() error {
+	if p.EMAShort >= p.EMALong {
+		return fmt.Errorf("EMA_SHORT (%d) must be less than EMA_LONG (%d)", p.EMAShort, p.EMALong)
+	}
+	if p.EMALong >= p.EMATrend {
+		return fmt.Errorf("EMA_LONG (%d) must be less than EMA_TREND (%d)", p.EMALong, p.EMATrend)
+	}
+	if p.EMATrend > maxCandlesPerRequest {
+		return fmt.Errorf("EMA_TREND (%d) exceeds the %d candles available per request", p.EMATrend, maxCandlesPerRequest)
+	}
+	if p.MACDFast >= p.MACDSlow {
+		return fmt.Errorf("MACD_FAST (%d) must be less than MACD_SLOW (%d)", p.MACDFast, p.MACDSlow)
+	}
+	return nil
+}
+
 // calculateEMA calculates Exponential Moving Average with optimized performance
 func calculateEMA(prices []float64, period int) float64 {
 	if len(prices) < period {
@@ -31,32 +125,132 @@ func calculateEMA(prices []float64, period int) float64 {
 	return ema
 }
 
+// emaSeries returns calculateEMA(prices[:i+1], period) for every i in a single pass, carrying the EMA
+// forward instead of recomputing it per window; entries before index period-1 are zero
+func emaSeries(prices []float64, period int) []float64 {
+	series := make([]float64, len(prices))
+	if period <= 0 || len(prices) < period {
+		return series
+	}
+
+	multiplier := 2.0 / float64(period+1)
+
+	var sum float64
+	for i := 0; i < period; i++ {
+		sum += prices[i]
+	}
+	ema := sum / float64(period)
+	series[period-1] = ema
+
+	for i := period; i < len(prices); i++ {
+		ema = (prices[i] * multiplier) + (ema * (1 - multiplier))
+		series[i] = ema
+	}
+
+	return series
+}
+
+// volatilityDecay is the EWMA decay factor for squared returns (RiskMetrics' 0.94, an 11-candle half-life)
+const volatilityDecay = 0.94
+
+// calculateEWMAVolatility returns the exponentially weighted volatility of log returns and the equally weighted
+// volatility of the same returns over the window, both per candle in percent. Both use the zero-mean estimator
+// (root mean square of the returns) so their ratio only reflects how recent returns compare to the window.
+// Returns zeros when there are fewer than two returns.
+func calculateEWMAVolatility(prices []float64, decay float64) (float64, float64) {
+	returns := make([]float64, 0, len(prices))
+	for i := 1; i < len(prices); i++ {
+		if prices[i-1] > 0 && prices[i] > 0 {
+			returns = append(returns, math.Log(prices[i]/prices[i-1]))
+		}
+	}
+	if len(returns) < 2 {
+		return 0, 0
+	}
+
+	// Seed with the first squared return; with a few hundred candles its weight has long decayed away
+	variance := returns[0] * returns[0]
+	var baselineVariance float64
+	for i, r := range returns {
+		if i > 0 {
+			variance = decay*variance + (1-decay)*r*r
+		}
+		baselineVariance += r * r
+	}
+	baselineVariance /= float64(len(returns))
+
+	return math.Sqrt(variance) * 100, math.Sqrt(baselineVariance) * 100
+}
+
+// anomalyResidualWindow is the number of recent price-minus-EMA residuals used to scale the anomaly z-score
+const anomalyResidualWindow = 50
+
+// calculatePriceZScore returns the distance of the latest price from its EMA, in standard deviations
+// of the previous residuals (price - EMA) over window candles. Returns 0 when there is not enough data.
+func calculatePriceZScore(prices []float64, period, window int) float64 {
+	if period <= 0 || window < 2 || len(prices) < period+window+1 {
+		return 0
+	}
+
+	// Walk the EMA forward (same SMA seed as calculateEMA) and keep each residual
+	multiplier := 2.0 / float64(period+1)
+	var sum float64
+	for i := 0; i < period; i++ {
+		sum += prices[i]
+	}
+	ema := sum / float64(period)
+	residuals := make([]float64, 0, len(prices)-period)
+	for i := period; i < len(prices); i++ {
+		ema = (prices[i] * multiplier) + (ema * (1 - multiplier))
+		residuals = append(residuals, prices[i]-ema)
+	}
+
+	// Standard deviation of the residuals before the latest one
+	latest := residuals[len(residuals)-1]
+	recent := residuals[len(residuals)-1-window : len(residuals)-1]
+	var mean float64
+	for _, r := range recent {
+		mean += r
+	}
+	mean /= float64(len(recent))
+	var variance float64
+	for _, r := range recent {
+		variance += (r - mean) * (r - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(recent)))
+	if stdDev == 0 {
+		return 0
+	}
+
+	return latest / stdDev
+}
+
 // calculateMACD calculates MACD and Signal line with optimized performance
-func calculateMACD(prices []float64) (float64, float64) {
-	if len(prices) < 26 {
+func calculateMACD(prices []float64, fast, slow, signal int) (float64, float64) {
+	if len(prices) < slow {
 		return 0, 0
 	}
 
-	// Calculate EMA12 and EMA26 for the entire dataset (more efficient)
-	ema12 := calculateEMA(prices, 12)
-	ema26 := calculateEMA(prices, 26)
-	macd := ema12 - ema26
+	// Calculate fast and slow EMAs for the entire dataset (more efficient)
+	emaFast := calculateEMA(prices, fast)
+	emaSlow := calculateEMA(prices, slow)
+	macd := emaFast - emaSlow
 
-	// For signal line, we only need MACD values from position 26 onwards
+	// For signal line, we only need MACD values from the slow period onwards
 	// Calculate MACD values more efficiently by reusing EMA calculations
-	macdValues := make([]float64, 0, len(prices)-26)
+	macdValues := make([]float64, 0, len(prices)-slow)
 
 	// Use sliding window approach for better performance
-	for i := 26; i < len(prices); i++ {
-		// Calculate EMA12 and EMA26 for the window ending at position i
+	for i := slow; i < len(prices); i++ {
+		// Calculate fast and slow EMAs for the window ending at position i
 		windowPrices := prices[:i+1]
-		windowEMA12 := calculateEMA(windowPrices, 12)
-		windowEMA26 := calculateEMA(windowPrices, 26)
-		macdValues = append(macdValues, windowEMA12-windowEMA26)
+		windowEMAFast := calculateEMA(windowPrices, fast)
+		windowEMASlow := calculateEMA(windowPrices, slow)
+		macdValues = append(macdValues, windowEMAFast-windowEMASlow)
 	}
 
-	// Calculate signal line as EMA9 of MACD values
-	signalLine := calculateEMA(macdValues, 9)
+	// Calculate signal line as an EMA of MACD values
+	signalLine := calculateEMA(macdValues, signal)
 	return macd, signalLine
 }
 
@@ -114,6 +308,59 @@ func calculateRSI(prices []float64, period int) float64 {
 	return rsi
 }
 
+// rsiSeries returns calculateRSI(prices[:i+1], period) for every i in a single pass; entries before
+// index period are zero. Like calculateRSI, a window with no losses pins every value to 100.
+func rsiSeries(prices []float64, period int) []float64 {
+	series := make([]float64, len(prices))
+	if period <= 0 || len(prices) < period+1 {
+		return series
+	}
+
+	var gains, losses float64
+	for i := 1; i <= period; i++ {
+		change := prices[i] - prices[i-1]
+		if change > 0 {
+			gains += change
+		} else {
+			losses += math.Abs(change)
+		}
+	}
+
+	if losses == 0 {
+		for i := period; i < len(prices); i++ {
+			series[i] = 100
+		}
+		return series
+	}
+
+	avgGain := gains / float64(period)
+	avgLoss := losses / float64(period)
+	multiplier := 1.0 / float64(period)
+
+	for i := period; i < len(prices); i++ {
+		if i > period {
+			change := prices[i] - prices[i-1]
+			var gain, loss float64
+			if change > 0 {
+				gain = change
+			} else {
+				loss = math.Abs(change)
+			}
+			avgGain = (avgGain * (1 - multiplier)) + (gain * multiplier)
+			avgLoss = (avgLoss * (1 - multiplier)) + (loss * multiplier)
+		}
+
+		if avgLoss == 0 {
+			series[i] = 100
+			continue
+		}
+		rs := avgGain / avgLoss
+		series[i] = 100 - (100 / (1 + rs))
+	}
+
+	return series
+}
+
 // calculateADX calculates Average Directional Index
 func calculateADX(highs, lows []float64, period int) float64 {
 	if len(highs) < period+1 || len(lows) < period+1 {
@@ -188,34 +435,43 @@ func detectVolumeSpike(volumes []float64) (bool, float64, float64) {
 	return volumeSpike, averageVolume, lastVolume
 }
 
-// detectTrianglePattern analyzes price action to identify triangle patterns
-func detectTrianglePattern(highs, lows []float64) (string, float64, []float64, []float64) {
+// triangleLines are the triangle trend lines, fitted with candle positions as x so they can be projected to any candle
+type triangleLines struct {
+	highSlope, highIntercept float64
+	lowSlope, lowIntercept   float64
+}
+
+// detectTrianglePattern analyzes price action to identify triangle patterns. It also returns the fitted trend
+// lines so the breakout is checked against the same lines the pattern was classified with.
+func detectTrianglePattern(highs, lows []float64) (string, float64, []float64, []float64, triangleLines) {
 	if len(highs) < 10 || len(lows) < 10 {
-		return "none", 0.0, nil, nil
+		return "none", 0.0, nil, nil, triangleLines{}
 	}
 
 	// Find significant highs and lows (peaks and troughs)
-	highPoints := findPeaks(highs, 3) // At least 3 high points
-	lowPoints := findTroughs(lows, 3) // At least 3 low points
+	highPoints, highPositions := findPeaks(highs, 3) // At least 3 high points
+	lowPoints, lowPositions := findTroughs(lows, 3)  // At least 3 low points
 
 	if len(highPoints) < 3 || len(lowPoints) < 3 {
-		return "none", 0.0, nil, nil
+		return "none", 0.0, nil, nil, triangleLines{}
 	}
 
-	// Calculate trend lines
-	highSlope, highIntercept := calculateTrendLine(highPoints)
-	lowSlope, lowIntercept := calculateTrendLine(lowPoints)
+	// Calculate trend lines at the candle positions of the points
+	var lines triangleLines
+	lines.highSlope, lines.highIntercept = calculateTrendLineAt(highPositions, highPoints)
+	lines.lowSlope, lines.lowIntercept = calculateTrendLineAt(lowPositions, lowPoints)
 
 	// Determine triangle type based on trend line slopes
-	triangleType := classifyTriangle(highSlope, highIntercept, lowSlope, lowIntercept)
-	strength := calculateTriangleStrength(highPoints, lowPoints, highSlope, highIntercept, lowSlope, lowIntercept)
+	triangleType := classifyTriangle(lines.highSlope, lines.highIntercept, lines.lowSlope, lines.lowIntercept)
+	strength := calculateTriangleStrength(highPositions, highPoints, lowPositions, lowPoints, lines)
 
-	return triangleType, strength, highPoints, lowPoints
+	return triangleType, strength, highPoints, lowPoints, lines
 }
 
-// findPeaks finds significant high points in the price data
-func findPeaks(prices []float64, minPoints int) []float64 {
+// findPeaks finds significant high points in the price data, along with their positions in prices
+func findPeaks(prices []float64, minPoints int) ([]float64, []int) {
 	var peaks []float64
+	var indices []int
 	window := 3 // Look for peaks in a 3-point window
 
 	for i := window; i < len(prices)-window; i++ {
@@ -231,20 +487,22 @@ func findPeaks(prices []float64, minPoints int) []float64 {
 		}
 		if isPeak {
 			peaks = append(peaks, prices[i])
+			indices = append(indices, i)
 		}
 	}
 
 	// If we don't have enough peaks, return the highest points
 	if len(peaks) < minPoints {
-		peaks = findHighestPoints(prices, minPoints)
+		peaks, indices = findHighestPoints(prices, minPoints)
 	}
 
-	return peaks
+	return peaks, indices
 }
 
-// findTroughs finds significant low points in the price data
-func findTroughs(prices []float64, minPoints int) []float64 {
+// findTroughs finds significant low points in the price data, along with their positions in prices
+func findTroughs(prices []float64, minPoints int) ([]float64, []int) {
 	var troughs []float64
+	var indices []int
 	window := 3 // Look for troughs in a 3-point window
 
 	for i := window; i < len(prices)-window; i++ {
@@ -260,64 +518,79 @@ func findTroughs(prices []float64, minPoints int) []float64 {
 		}
 		if isTrough {
 			troughs = append(troughs, prices[i])
+			indices = append(indices, i)
 		}
 	}
 
 	// If we don't have enough troughs, return the lowest points
 	if len(troughs) < minPoints {
-		troughs = findLowestPoints(prices, minPoints)
+		troughs, indices = findLowestPoints(prices, minPoints)
 	}
 
-	return troughs
+	return troughs, indices
 }
 
-// findHighestPoints finds the highest points in the price data
-func findHighestPoints(prices []float64, count int) []float64 {
+// findHighestPoints finds the highest points in the price data, along with their positions in prices
+func findHighestPoints(prices []float64, count int) ([]float64, []int) {
 	if len(prices) < count {
-		return prices
+		count = len(prices)
 	}
 
-	// Sort prices in descending order and take the top 'count'
-	sorted := make([]float64, len(prices))
-	copy(sorted, prices)
+	// Sort positions by price in descending order and take the top 'count'
+	indices := make([]int, len(prices))
+	for i := range indices {
+		indices[i] = i
+	}
 
 	// Simple bubble sort for small datasets
-	for i := 0; i < len(sorted)-1; i++ {
-		for j := 0; j < len(sorted)-i-1; j++ {
-			if sorted[j] < sorted[j+1] {
-				sorted[j], sorted[j+1] = sorted[j+1], sorted[j]
+	for i := 0; i < len(indices)-1; i++ {
+		for j := 0; j < len(indices)-i-1; j++ {
+			if prices[indices[j]] < prices[indices[j+1]] {
+				indices[j], indices[j+1] = indices[j+1], indices[j]
 			}
 		}
 	}
 
-	return sorted[:count]
+	indices = indices[:count]
+	points := make([]float64, count)
+	for i, idx := range indices {
+		points[i] = prices[idx]
+	}
+	return points, indices
 }
 
-// findLowestPoints finds the lowest points in the price data
-func findLowestPoints(prices []float64, count int) []float64 {
+// findLowestPoints finds the lowest points in the price data, along with their positions in prices
+func findLowestPoints(prices []float64, count int) ([]float64, []int) {
 	if len(prices) < count {
-		return prices
+		count = len(prices)
 	}
 
-	// Sort prices in ascending order and take the bottom 'count'
-	sorted := make([]float64, len(prices))
-	copy(sorted, prices)
+	// Sort positions by price in ascending order and take the bottom 'count'
+	indices := make([]int, len(prices))
+	for i := range indices {
+		indices[i] = i
+	}
 
 	// Simple bubble sort for small datasets
-	for i := 0; i < len(sorted)-1; i++ {
-		for j := 0; j < len(sorted)-i-1; j++ {
-			if sorted[j] > sorted[j+1] {
-				sorted[j], sorted[j+1] = sorted[j+1], sorted[j]
+	for i := 0; i < len(indices)-1; i++ {
+		for j := 0; j < len(indices)-i-1; j++ {
+			if prices[indices[j]] > prices[indices[j+1]] {
+				indices[j], indices[j+1] = indices[j+1], indices[j]
 			}
 		}
 	}
 
-	return sorted[:count]
+	indices = indices[:count]
+	points := make([]float64, count)
+	for i, idx := range indices {
+		points[i] = prices[idx]
+	}
+	return points, indices
 }
 
-// calculateTrendLine calculates the slope and intercept of a trend line
-func calculateTrendLine(points []float64) (float64, float64) {
-	if len(points) < 2 {
+// calculateTrendLineAt calculates the slope and intercept of a trend line through points at the given positions
+func calculateTrendLineAt(positions []int, points []float64) (float64, float64) {
+	if len(points) < 2 || len(positions) != len(points) {
 		return 0, 0
 	}
 
@@ -326,7 +599,7 @@ func calculateTrendLine(points []float64) (float64, float64) {
 	var sumX, sumY, sumXY, sumX2 float64
 
 	for i, y := range points {
-		x := float64(i)
+		x := float64(positions[i])
 		sumX += x
 		sumY += y
 		sumXY += x * y
@@ -344,7 +617,7 @@ func calculateTrendLine(points []float64) (float64, float64) {
 	return slope, intercept
 }
 
-// classifyTriangle determines the type of triangle based on trend line slopes
+// classifyTriangle determines the type of triangle based on trend line slopes, in price per candle
 func classifyTriangle(highSlope, highIntercept, lowSlope, lowIntercept float64) string {
 
 	// Tolerance for slope comparison
@@ -369,14 +642,14 @@ func classifyTriangle(highSlope, highIntercept, lowSlope, lowIntercept float64)
 }
 
 // calculateTriangleStrength calculates the confidence in the triangle pattern
-func calculateTriangleStrength(highPoints, lowPoints []float64, highSlope, highIntercept, lowSlope, lowIntercept float64) float64 {
+func calculateTriangleStrength(highPositions []int, highPoints []float64, lowPositions []int, lowPoints []float64, lines triangleLines) float64 {
 	if len(highPoints) < 3 || len(lowPoints) < 3 {
 		return 0.0
 	}
 
 	// Calculate R-squared for trend lines (how well they fit)
-	highR2 := calculateRSquared(highPoints, highSlope, highIntercept)
-	lowR2 := calculateRSquared(lowPoints, lowSlope, lowIntercept)
+	highR2 := calculateRSquared(highPositions, highPoints, lines.highSlope, lines.highIntercept)
+	lowR2 := calculateRSquared(lowPositions, lowPoints, lines.lowSlope, lines.lowIntercept)
 
 	// Average R-squared as strength indicator
 	strength := (highR2 + lowR2) / 2.0
@@ -385,15 +658,15 @@ func calculateTriangleStrength(highPoints, lowPoints []float64, highSlope, highI
 	return math.Min(strength, 1.0)
 }
 
-// calculateRSquared calculates the R-squared value for a trend line
-func calculateRSquared(points []float64, slope, intercept float64) float64 {
-	if len(points) < 2 {
+// calculateRSquared calculates the R-squared value for a trend line through points at the given positions
+func calculateRSquared(positions []int, points []float64, slope, intercept float64) float64 {
+	if len(points) < 2 || len(positions) != len(points) {
 		return 0.0
 	}
 
 	var sumY, sumY2, sumResiduals float64
 	for i, y := range points {
-		x := float64(i)
+		x := float64(positions[i])
 		predicted := slope*x + intercept
 		residual := y - predicted
 
@@ -416,16 +689,16 @@ func calculateRSquared(points []float64, slope, intercept float64) float64 {
 	return math.Max(0.0, r2)
 }
 
-// detectTriangleBreakout detects if price has broken out of the triangle pattern
-func detectTriangleBreakout(currentPrice float64, triangleType string, highSlope, highIntercept, lowSlope, lowIntercept float64) string {
-	if triangleType == "none" {
+// detectTriangleBreakout detects if price has broken out of the triangle pattern, projecting the trend lines
+// to latestIndex (the position of the latest candle)
+func detectTriangleBreakout(currentPrice float64, triangleType string, lines triangleLines, latestIndex int) string {
+	if triangleType == "" || triangleType == "none" {
 		return "none"
 	}
 
-	// Calculate current trend line values (assuming we're at the latest point)
-	latestIndex := 144.0 // Assuming 144 data points for 12-hour analysis
-	currentHighLevel := highSlope*latestIndex + highIntercept
-	currentLowLevel := lowSlope*latestIndex + lowIntercept
+	// Calculate current trend line values at the latest point
+	currentHighLevel := lines.highSlope*float64(latestIndex) + lines.highIntercept
+	currentLowLevel := lines.lowSlope*float64(latestIndex) + lines.lowIntercept
 
 	// Detect breakout based on triangle type
 	switch triangleType {
@@ -452,17 +725,12 @@ func detectTriangleBreakout(currentPrice float64, triangleType string, highSlope
 	return "none"
 }
 
-// calculateTechnicalIndicatorsParallel calculates all technical indicators in parallel with early termination
-func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators {
-	if len(candles) < 50 { // Reduced minimum for lightweight mode
-		return TechnicalIndicators{}
-	}
-
-	// Extract prices and volumes
-	prices := make([]float64, len(candles))
-	highs := make([]float64, len(candles))
-	lows := make([]float64, len(candles))
-	volumes := make([]float64, len(candles))
+// candleSeries extracts the close, high, low and volume series from candles
+func candleSeries(candles []Candle) (prices, highs, lows, volumes []float64) {
+	prices = make([]float64, len(candles))
+	highs = make([]float64, len(candles))
+	lows = make([]float64, len(candles))
+	volumes = make([]float64, len(candles))
 
 	for i, candle := range candles {
 		close, _ := strconv.ParseFloat(candle.Close, 64)
@@ -475,6 +743,53 @@ func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators
 		lows[i] = low
 		volumes[i] = volume
 	}
+	return prices, highs, lows, volumes
+}
+
+// calculateTechnicalIndicatorsSequential calculates every indicator in turn, without goroutines, and always
+// returns a fully populated struct, so it is the one to use when results must be deterministic.
+func calculateTechnicalIndicatorsSequential(candles []Candle, periods IndicatorPeriods) TechnicalIndicators {
+	if len(candles) < minIndicatorCandles {
+		return TechnicalIndicators{}
+	}
+
+	prices, highs, lows, volumes := candleSeries(candles)
+	indicators := TechnicalIndicators{
+		CurrentPrice:     prices[len(prices)-1],
+		TriangleBreakout: "none",
+	}
+
+	indicators.MACD, indicators.SignalLine = calculateMACD(prices, periods.MACDFast, periods.MACDSlow, periods.MACDSignal)
+	indicators.EMA12 = calculateEMA(prices, periods.EMAShort)
+	indicators.EMA26 = calculateEMA(prices, periods.EMALong)
+	indicators.EMA200 = calculateEMA(prices, periods.EMATrend)
+	indicators.RSI = calculateRSI(prices, periods.RSI)
+	indicators.ADX = calculateADX(highs, lows, periods.ADX)
+	indicators.PriceDropPct12h = calculatePriceDropPct(prices, priceDropPeriod)
+	indicators.PriceZScore = calculatePriceZScore(prices, periods.EMALong, anomalyResidualWindow)
+	indicators.Volatility, indicators.BaselineVolatility = calculateEWMAVolatility(prices, volatilityDecay)
+	indicators.VolumeSpike, indicators.AverageVolume, indicators.LastVolume = detectVolumeSpike(volumes)
+	var lines triangleLines
+	indicators.TrianglePattern, indicators.TriangleStrength, indicators.TriangleHighs, indicators.TriangleLows, lines = detectTrianglePattern(highs, lows)
+	indicators.TriangleBreakout = detectTriangleBreakout(indicators.CurrentPrice, indicators.TrianglePattern, lines, len(highs)-1)
+
+	return indicators
+}
+
+// earlyExitIndicators are the results checkBearishSignals needs before an early exit can be taken
+var earlyExitIndicators = []string{"macd", "signalLine", "ema12", "ema26", "rsi"}
+
+// calculateTechnicalIndicatorsParallel calculates all technical indicators in parallel and, by default, waits for
+// every result so scoring never reads zeros for indicators that were still being computed. With earlyExit the
+// remaining calculations are cancelled as soon as the key indicators show a bearish signal, and the result is
+// marked Partial (EMA200, ADX, volume and triangle fields may be zero).
+func calculateTechnicalIndicatorsParallel(candles []Candle, periods IndicatorPeriods, earlyExit bool) TechnicalIndicators {
+	if len(candles) < minIndicatorCandles { // Reduced minimum for lightweight mode
+		return TechnicalIndicators{}
+	}
+
+	// Extract prices and volumes
+	prices, highs, lows, volumes := candleSeries(candles)
 
 	// Create context for cancellation
 	ctx, cancel := context.WithCancel(context.Background())
@@ -485,13 +800,12 @@ func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators
 		name  string
 		value interface{}
 	}
-	resultChan := make(chan indicatorResult, 12) // Buffer for all indicators
+	resultChan := make(chan indicatorResult, 20) // Buffer for all indicators
 
-	// Create channels for early signal detection
-	signalChan := make(chan bool, 1)
+	// Channel for the assembled result
 	indicatorsChan := make(chan TechnicalIndicators, 1)
 
-	// Calculate indicators in parallel with early termination
+	// Calculate indicators in parallel
 	var wg sync.WaitGroup
 
 	// MACD and Signal Line (high priority - often triggers first)
@@ -502,7 +816,7 @@ func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators
 		case <-ctx.Done():
 			return
 		default:
-			macd, signalLine := calculateMACD(prices)
+			macd, signalLine := calculateMACD(prices, periods.MACDFast, periods.MACDSlow, periods.MACDSignal)
 			select {
 			case <-ctx.Done():
 				return
@@ -516,7 +830,7 @@ func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators
 		}
 	}()
 
-	// EMA12 (high priority)
+	// Short EMA, EMA12 by default (high priority)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -524,7 +838,7 @@ func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators
 		case <-ctx.Done():
 			return
 		default:
-			ema12 := calculateEMA(prices, 12)
+			ema12 := calculateEMA(prices, periods.EMAShort)
 			select {
 			case <-ctx.Done():
 				return
@@ -533,7 +847,7 @@ func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators
 		}
 	}()
 
-	// EMA26 (high priority)
+	// Long EMA, EMA26 by default (high priority)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -541,7 +855,7 @@ func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators
 		case <-ctx.Done():
 			return
 		default:
-			ema26 := calculateEMA(prices, 26)
+			ema26 := calculateEMA(prices, periods.EMALong)
 			select {
 			case <-ctx.Done():
 				return
@@ -550,7 +864,7 @@ func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators
 		}
 	}()
 
-	// EMA200 (lower priority - takes longer)
+	// Trend EMA, EMA200 by default (lower priority - takes longer)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -558,7 +872,7 @@ func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators
 		case <-ctx.Done():
 			return
 		default:
-			ema200 := calculateEMA(prices, 200)
+			ema200 := calculateEMA(prices, periods.EMATrend)
 			select {
 			case <-ctx.Done():
 				return
@@ -575,7 +889,7 @@ func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators
 		case <-ctx.Done():
 			return
 		default:
-			rsi := calculateRSI(prices, 14)
+			rsi := calculateRSI(prices, periods.RSI)
 			select {
 			case <-ctx.Done():
 				return
@@ -592,7 +906,7 @@ func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators
 		case <-ctx.Done():
 			return
 		default:
-			adx := calculateADX(highs, lows, 14)
+			adx := calculateADX(highs, lows, periods.ADX)
 			select {
 			case <-ctx.Done():
 				return
@@ -609,7 +923,6 @@ func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators
 		case <-ctx.Done():
 			return
 		default:
-			priceDropPeriod := 144 // 12 hours for 5-minute candles (144 * 5 minutes = 720 minutes = 12 hours)
 			priceDropPct4h := calculatePriceDropPct(prices, priceDropPeriod)
 			select {
 			case <-ctx.Done():
@@ -619,6 +932,45 @@ func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators
 		}
 	}()
 
+	// Price z-score against the long EMA (anomaly detection)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			zScore := calculatePriceZScore(prices, periods.EMALong, anomalyResidualWindow)
+			select {
+			case <-ctx.Done():
+				return
+			case resultChan <- indicatorResult{"priceZScore", zScore}:
+			}
+		}
+	}()
+
+	// EWMA volatility against the whole-window baseline (adaptive thresholds)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			volatility, baseline := calculateEWMAVolatility(prices, volatilityDecay)
+			select {
+			case <-ctx.Done():
+				return
+			case resultChan <- indicatorResult{"volatility", volatility}:
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case resultChan <- indicatorResult{"baselineVolatility", baseline}:
+			}
+		}
+	}()
+
 	// Volume Spike Detection (medium priority)
 	wg.Add(1)
 	go func() {
@@ -654,7 +1006,8 @@ func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators
 		case <-ctx.Done():
 			return
 		default:
-			triangleType, strength, highPoints, lowPoints := detectTrianglePattern(highs, lows)
+			triangleType, strength, highPoints, lowPoints, lines := detectTrianglePattern(highs, lows)
+			breakout := detectTriangleBreakout(prices[len(prices)-1], triangleType, lines, len(highs)-1)
 			select {
 			case <-ctx.Done():
 				return
@@ -675,16 +1028,22 @@ func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators
 				return
 			case resultChan <- indicatorResult{"triangleLows", lowPoints}:
 			}
+			select {
+			case <-ctx.Done():
+				return
+			case resultChan <- indicatorResult{"triangleBreakout", breakout}:
+			}
 		}
 	}()
 
 	// Stream processor that checks for signals as they arrive
 	go func() {
 		indicators := TechnicalIndicators{
-			CurrentPrice: prices[len(prices)-1],
+			CurrentPrice:     prices[len(prices)-1],
+			TrianglePattern:  "none",
+			TriangleBreakout: "none",
 		}
-		completedIndicators := 0
-		totalIndicators := 9 // Updated to include triangle analysis
+		received := make(map[string]bool)
 
 		for result := range resultChan {
 			// Store the result
@@ -705,6 +1064,12 @@ func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators
 				indicators.ADX = result.value.(float64)
 			case "priceDropPct12h":
 				indicators.PriceDropPct12h = result.value.(float64)
+			case "priceZScore":
+				indicators.PriceZScore = result.value.(float64)
+			case "volatility":
+				indicators.Volatility = result.value.(float64)
+			case "baselineVolatility":
+				indicators.BaselineVolatility = result.value.(float64)
 			case "volumeSpike":
 				indicators.VolumeSpike = result.value.(bool)
 			case "averageVolume":
@@ -719,68 +1084,51 @@ func calculateTechnicalIndicatorsParallel(candles []Candle) TechnicalIndicators
 				indicators.TriangleHighs = result.value.([]float64)
 			case "triangleLows":
 				indicators.TriangleLows = result.value.([]float64)
+			case "triangleBreakout":
+				indicators.TriangleBreakout = result.value.(string)
 			}
 
-			// Check if we have enough indicators to detect a signal
-			if completedIndicators < totalIndicators {
-				completedIndicators++
-			}
-
-			// Calculate triangle breakout if we have triangle data
-			if indicators.TrianglePattern != "" && len(indicators.TriangleHighs) > 0 && len(indicators.TriangleLows) > 0 {
-				// Calculate trend lines for breakout detection
-				highSlope, highIntercept := calculateTrendLine(indicators.TriangleHighs)
-				lowSlope, lowIntercept := calculateTrendLine(indicators.TriangleLows)
-				indicators.TriangleBreakout = detectTriangleBreakout(indicators.CurrentPrice, indicators.TrianglePattern, highSlope, highIntercept, lowSlope, lowIntercept)
-			}
-
-			// Check for early signal detection (after we have key indicators)
-			if completedIndicators >= 4 { // Check after we have MACD, EMA12, EMA26, RSI
-				bearishSignal, _ := checkBearishSignals(indicators)
-				if bearishSignal {
-					// Signal detected! Cancel other calculations and send result
+			// Early exit: once the key indicators are in, a bearish signal cancels the remaining calculations
+			received[result.name] = true
+			if earlyExit && haveAll(received, earlyExitIndicators) {
+				if bearishSignal, _ := checkBearishSignals(indicators); bearishSignal {
 					cancel()
-					select {
-					case signalChan <- true:
-					default:
-					}
-					select {
-					case indicatorsChan <- indicators:
-					default:
-					}
+					indicators.Partial = true
+					indicatorsChan <- indicators
 					return
 				}
 			}
 		}
 
 		// All calculations completed, send final result
-		select {
-		case signalChan <- false:
-		default:
-		}
-		select {
-		case indicatorsChan <- indicators:
-		default:
-		}
+		indicatorsChan <- indicators
 	}()
 
-	// Wait for all calculations to complete or early termination
+	// Wait for all calculations to complete
 	go func() {
 		wg.Wait()
 		close(resultChan)
 	}()
 
-	// Wait for result
-	select {
-	case <-signalChan:
-		return <-indicatorsChan
+	return <-indicatorsChan
+}
+
+// haveAll reports whether every name is present in received
+func haveAll(received map[string]bool, names []string) bool {
+	for _, name := range names {
+		if !received[name] {
+			return false
+		}
 	}
+	return true
 }
 
-// calculateTechnicalIndicators calculates all technical indicators from candle data
-func calculateTechnicalIndicators(candles []Candle) TechnicalIndicators {
+// calculateTechnicalIndicators calculates all technical indicators from candle data.
+// earlyExit (EARLY_SIGNAL_EXIT) trades complete indicators for latency, see calculateTechnicalIndicatorsParallel;
+// callers that need the same full result every time use calculateTechnicalIndicatorsSequential.
+func calculateTechnicalIndicators(candles []Candle, periods IndicatorPeriods, earlyExit bool) TechnicalIndicators {
 	// Use parallel calculation for better performance
-	return calculateTechnicalIndicatorsParallel(candles)
+	return calculateTechnicalIndicatorsParallel(candles, periods, earlyExit)
 }
 
 // checkBearishSignals checks if any bearish trend change signals are triggered
@@ -808,7 +1156,7 @@ func checkBearishSignals(indicators TechnicalIndicators) (bool, []string) {
 	}
 
 	// Price breaks below EMA200 with momentum (major trend change)
-	if indicators.CurrentPrice < indicators.EMA200 && indicators.RSI < 45 {
+	if indicators.hasTrendEMA() && indicators.CurrentPrice < indicators.EMA200 && indicators.RSI < 45 {
 		triggers = append(triggers, "MAJOR_TREND_BREAKDOWN")
 	}
 
@@ -828,7 +1176,7 @@ func checkBearishSignals(indicators TechnicalIndicators) (bool, []string) {
 	if indicators.RSI < 45 {
 		bearishCount++
 	}
-	if indicators.CurrentPrice < indicators.EMA200 {
+	if indicators.hasTrendEMA() && indicators.CurrentPrice < indicators.EMA200 {
 		bearishCount++
 	}
 
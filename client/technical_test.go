@@ -0,0 +1,249 @@
+package client
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// decliningCloses returns n closes drifting up then falling steadily over the last quarter, enough for
+// MACD, EMA and RSI to turn bearish
+func decliningCloses(n int) []float64 {
+	closes := wavyCloses(n)
+	top := closes[n*3/4]
+	for i := n * 3 / 4; i < n; i++ {
+		closes[i] = top * (1 - 0.002*float64(i-n*3/4))
+	}
+	return closes
+}
+
+func TestParallelIndicatorsMatchSequential(t *testing.T) {
+	periods := defaultIndicatorPeriods()
+
+	for name, closes := range map[string][]float64{"wavy": wavyCloses(300), "declining": decliningCloses(300)} {
+		t.Run(name, func(t *testing.T) {
+			candles := candlesFromCloses(closes)
+			sequential := calculateTechnicalIndicatorsSequential(candles, periods)
+			if sequential.EMA200 == 0 || sequential.ADX == 0 {
+				t.Fatalf("sequential result not fully populated: %+v", sequential)
+			}
+
+			// Run it repeatedly: the parallel path must not depend on goroutine scheduling without early exit
+			for i := 0; i < 20; i++ {
+				if parallel := calculateTechnicalIndicatorsParallel(candles, periods, false); !reflect.DeepEqual(parallel, sequential) {
+					t.Fatalf("run %d: parallel\n%+v\nwant sequential\n%+v", i, parallel, sequential)
+				}
+			}
+		})
+	}
+}
+
+func TestEarlyExitIndicatorsMatchSequential(t *testing.T) {
+	periods := defaultIndicatorPeriods()
+	candles := candlesFromCloses(decliningCloses(300))
+	sequential := calculateTechnicalIndicatorsSequential(candles, periods)
+	if triggered, _ := checkBearishSignals(sequential); !triggered {
+		t.Fatal("test data does not show a bearish signal, early exit would not be taken")
+	}
+
+	// Whatever else was cancelled, the indicators the early exit decided on are complete and identical
+	for i := 0; i < 20; i++ {
+		partial := calculateTechnicalIndicatorsParallel(candles, periods, true)
+		if partial.MACD != sequential.MACD || partial.SignalLine != sequential.SignalLine ||
+			partial.EMA12 != sequential.EMA12 || partial.EMA26 != sequential.EMA26 || partial.RSI != sequential.RSI {
+			t.Fatalf("run %d: early-exit indicators %+v differ from sequential %+v", i, partial, sequential)
+		}
+		if !partial.Partial && !reflect.DeepEqual(partial, sequential) {
+			t.Fatalf("run %d: complete early-exit result differs from sequential", i)
+		}
+	}
+}
+
+func TestTrendEMARulesSkippedWithoutEMA200(t *testing.T) {
+	c := &CoinbaseClient{indicatorPeriods: defaultIndicatorPeriods(), scoringWeights: defaultScoringWeights()}
+	ema200Triggers := []string{"BELOW_EMA200_WITH_MOMENTUM", "MAJOR_TREND_BREAKDOWN", "MAJOR_TREND_BREAKOUT"}
+
+	// The 144 five-minute candles of the lightweight poll are too few for EMA200
+	for name, closes := range map[string][]float64{"declining": decliningCloses(144), "rising": risingCloses(144)} {
+		t.Run(name, func(t *testing.T) {
+			indicators := calculateTechnicalIndicatorsSequential(candlesFromCloses(closes), c.indicatorPeriods)
+			if indicators.hasTrendEMA() {
+				t.Fatalf("EMA200 = %v from 144 candles, want it unavailable", indicators.EMA200)
+			}
+
+			_, dipTriggers := scoreImmediateDip(indicators, c.scoringWeights.Dip)
+			_, bearishTriggers := checkBearishSignals(indicators)
+			fired := append(append(append(dipTriggers, bearishTriggers...),
+				c.calculateTriggers(indicators, "bearish")...), c.calculateTriggers(indicators, "bullish")...)
+			for _, trigger := range ema200Triggers {
+				if contains(fired, trigger) {
+					t.Errorf("%s fired without EMA200 (price %.2f, RSI %.2f)", trigger, indicators.CurrentPrice, indicators.RSI)
+				}
+			}
+
+			// The same indicators with a trend EMA far from the price do fire, so only the missing EMA held them back
+			withTrend := indicators
+			if name == "declining" {
+				withTrend.EMA200 = indicators.CurrentPrice * 2
+				if !contains(c.calculateTriggers(withTrend, "bearish"), "MAJOR_TREND_BREAKDOWN") {
+					t.Errorf("MAJOR_TREND_BREAKDOWN not fired below EMA200 (RSI %.2f)", indicators.RSI)
+				}
+			} else {
+				withTrend.EMA200 = indicators.CurrentPrice / 2
+				if !contains(c.calculateTriggers(withTrend, "bullish"), "MAJOR_TREND_BREAKOUT") {
+					t.Errorf("MAJOR_TREND_BREAKOUT not fired above EMA200 (RSI %.2f)", indicators.RSI)
+				}
+			}
+		})
+	}
+}
+
+// regimeCloses returns closes whose log returns alternate in sign, with size first for the first n returns
+// and size last for the following m
+func regimeCloses(n int, first float64, m int, last float64) []float64 {
+	closes := []float64{50000}
+	for i := 0; i < n+m; i++ {
+		size := first
+		if i >= n {
+			size = last
+		}
+		if i%2 == 1 {
+			size = -size
+		}
+		closes = append(closes, closes[i]*math.Exp(size))
+	}
+	return closes
+}
+
+func TestEWMAVolatilityMatchesItsBaselineInASteadyMarket(t *testing.T) {
+	// A steady drift: the same return every candle, so recent and window volatility are the same
+	drifting := []float64{50000}
+	for i := 0; i < 300; i++ {
+		drifting = append(drifting, drifting[i]*1.001)
+	}
+	for name, closes := range map[string][]float64{"drifting": drifting, "alternating": regimeCloses(300, 0.002, 0, 0)} {
+		t.Run(name, func(t *testing.T) {
+			volatility, baseline := calculateEWMAVolatility(closes, volatilityDecay)
+			if volatility <= 0 || !almostEqual(volatility/baseline, 1) {
+				t.Errorf("volatility %v / baseline %v = %v, want 1", volatility, baseline, volatility/baseline)
+			}
+		})
+	}
+}
+
+func TestAdaptiveThresholdFollowsTheVolatilityRegime(t *testing.T) {
+	c := &CoinbaseClient{adaptiveThresholds: true, adaptiveMinScale: defaultAdaptiveMinScale, adaptiveMaxScale: defaultAdaptiveMaxScale}
+	steady := calculateTechnicalIndicatorsSequential(candlesFromCloses(regimeCloses(300, 0.002, 0, 0)), defaultIndicatorPeriods())
+	calm := calculateTechnicalIndicatorsSequential(candlesFromCloses(regimeCloses(270, 0.004, 30, 0.001)), defaultIndicatorPeriods())
+	volatile := calculateTechnicalIndicatorsSequential(candlesFromCloses(regimeCloses(270, 0.001, 30, 0.004)), defaultIndicatorPeriods())
+
+	if got := c.effectiveThreshold(steady); !almostEqual(got, trendScoreThreshold) {
+		t.Errorf("steady market threshold = %v, want the fixed %v", got, trendScoreThreshold)
+	}
+	if got, want := c.effectiveThreshold(calm), trendScoreThreshold*defaultAdaptiveMinScale; !almostEqual(got, want) {
+		t.Errorf("calm market threshold = %v (volatility %v, baseline %v), want the lower bound %v",
+			got, calm.Volatility, calm.BaselineVolatility, want)
+	}
+	if got, want := c.effectiveThreshold(volatile), trendScoreThreshold*defaultAdaptiveMaxScale; !almostEqual(got, want) {
+		t.Errorf("volatile market threshold = %v (volatility %v, baseline %v), want the upper bound %v",
+			got, volatile.Volatility, volatile.BaselineVolatility, want)
+	}
+
+	// Without ADAPTIVE_THRESHOLDS the regime is ignored
+	c.adaptiveThresholds = false
+	if got := c.effectiveThreshold(volatile); got != trendScoreThreshold {
+		t.Errorf("threshold with adaptive thresholds off = %v, want %v", got, trendScoreThreshold)
+	}
+}
+
+func TestTriangleIsClassifiedAndBrokenOnCandlePositions(t *testing.T) {
+	// Peaks falling 0.2 per candle, ten candles apart; troughs rising only 0.0005 per candle
+	highs, lows := make([]float64, 40), make([]float64, 40)
+	for i := range highs {
+		highs[i], lows[i] = 104, 96
+	}
+	highs[5], highs[15], highs[25] = 110, 108, 106
+	lows[4], lows[14], lows[24] = 90, 90.005, 90.01
+
+	pattern, strength, highPoints, lowPoints, lines := detectTrianglePattern(highs, lows)
+	if pattern != "descending" || strength < 0.99 || len(highPoints) != 3 || len(lowPoints) != 3 {
+		t.Fatalf("pattern = %s (strength %v, %d highs, %d lows), want a clean descending triangle",
+			pattern, strength, len(highPoints), len(lowPoints))
+	}
+
+	// Fitted on consecutive indices, the same troughs rise 0.005 per point and it would read as symmetrical
+	highSlope, highIntercept := calculateTrendLineAt([]int{0, 1, 2}, highPoints)
+	lowSlope, lowIntercept := calculateTrendLineAt([]int{0, 1, 2}, lowPoints)
+	if consecutive := classifyTriangle(highSlope, highIntercept, lowSlope, lowIntercept); consecutive != "symmetrical" {
+		t.Fatalf("consecutive-index classification = %s, the test data no longer shows the difference", consecutive)
+	}
+
+	// The breakout projects those same lines to the latest candle: the low line is about 90.0175 there
+	latest := len(highs) - 1
+	for price, want := range map[float64]string{89.99: "bearish", 95: "none", 104: "bullish"} {
+		if got := detectTriangleBreakout(price, pattern, lines, latest); got != want {
+			t.Errorf("breakout at %v = %s, want %s", price, got, want)
+		}
+	}
+}
+
+func TestSignalReportsADetectedTriangle(t *testing.T) {
+	// The descending triangle above over the last 40 of minIndicatorCandles, closing below its low line
+	closes := make([]float64, minIndicatorCandles)
+	for i := range closes {
+		closes[i] = 100
+	}
+	closes[len(closes)-1] = 89.99
+	candles := candlesFromCloses(closes)
+	for i := range candles {
+		candles[i].High, candles[i].Low = "104", "96"
+	}
+	offset := len(candles) - 40
+	candles[offset+5].High, candles[offset+15].High, candles[offset+25].High = "110", "108", "106"
+	candles[offset+4].Low, candles[offset+14].Low, candles[offset+24].Low = "90", "90.005", "90.01"
+	candles[len(candles)-1].Low = "89.99"
+
+	fake := newFakeCoinbase()
+	fake.candles = candles
+	c := newTestClient(t, fake)
+	c.closedCandlesOnly = false
+	signal, err := c.GetSignalWithCandles(len(candles), "FIVE_MINUTE")
+	if err != nil {
+		t.Fatalf("GetSignalWithCandles: %v", err)
+	}
+
+	// Check the JSON /signal serves, not just the struct
+	body, err := json.Marshal(signal)
+	if err != nil {
+		t.Fatalf("failed to marshal the signal: %v", err)
+	}
+	var response struct {
+		Indicators map[string]interface{} `json:"indicators"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatalf("failed to decode the signal: %v", err)
+	}
+	if got := response.Indicators["triangle_pattern"]; got != "descending" {
+		t.Errorf("triangle_pattern = %v, want descending", got)
+	}
+	if got := response.Indicators["triangle_breakout"]; got != "bearish" {
+		t.Errorf("triangle_breakout = %v, want bearish", got)
+	}
+	if got, ok := response.Indicators["triangle_strength"].(float64); !ok || got < 0.99 {
+		t.Errorf("triangle_strength = %v, want the clean fit close to 1", response.Indicators["triangle_strength"])
+	}
+	if highs, ok := response.Indicators["triangle_highs"].([]interface{}); !ok || len(highs) != 3 || highs[0] != 110.0 {
+		t.Errorf("triangle_highs = %v, want the three peaks from 110", response.Indicators["triangle_highs"])
+	}
+}
+
+// contains reports whether triggers includes trigger
+func contains(triggers []string, trigger string) bool {
+	for _, t := range triggers {
+		if t == trigger {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// trendStateFileMutex serializes read-modify-write of the trend state file, which all pair clients share
+var trendStateFileMutex sync.Mutex
+
+// persistedTrendState is the per-pair trend detector state saved to TREND_STATE_FILE
+type persistedTrendState struct {
+	Trend          string    `json:"trend"`
+	LastSignalTime time.Time `json:"last_signal_time"`
+}
+
+// readTrendStates reads the trend state file; a missing file yields an empty map
+func readTrendStates(path string) (map[string]persistedTrendState, error) {
+	states := make(map[string]persistedTrendState)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return states, nil
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("invalid trend state file %s: %w", path, err)
+	}
+	return states, nil
+}
+
+// loadTrendState restores the last trend state and signal time for this pair so a restart
+// doesn't re-emit a trend the webhook receiver already knows about
+func (c *CoinbaseClient) loadTrendState() error {
+	if c.trendStateFile == "" {
+		return nil
+	}
+
+	c.trendMutex.Lock()
+	defer c.trendMutex.Unlock()
+	trendStateFileMutex.Lock()
+	defer trendStateFileMutex.Unlock()
+
+	states, err := readTrendStates(c.trendStateFile)
+	if err != nil {
+		return err
+	}
+	if state, ok := states[c.tradingPair]; ok {
+		c.lastTrendState = state.Trend
+		c.lastSignalTime = state.LastSignalTime
+		c.logger.Printf("Restored %s trend state: %s (last signal %s)", c.tradingPair, state.Trend, state.LastSignalTime.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// trendSnapshot returns the last signalled trend and when it was signalled (zero before the first signal)
+func (c *CoinbaseClient) trendSnapshot() (string, time.Time) {
+	c.trendMutex.Lock()
+	defer c.trendMutex.Unlock()
+	return c.lastTrendState, c.lastSignalTime
+}
+
+// saveTrendState writes the current trend state for this pair, keeping other pairs' entries.
+// Failures are logged, not returned, so persistence problems never block signalling. Callers must hold trendMutex.
+func (c *CoinbaseClient) saveTrendState() {
+	if c.trendStateFile == "" {
+		return
+	}
+
+	trendStateFileMutex.Lock()
+	defer trendStateFileMutex.Unlock()
+
+	states, err := readTrendStates(c.trendStateFile)
+	if err != nil {
+		c.logger.Printf("[WARN] Could not read trend state file, overwriting: %v", err)
+		states = make(map[string]persistedTrendState)
+	}
+	states[c.tradingPair] = persistedTrendState{
+		Trend:          c.lastTrendState,
+		LastSignalTime: c.lastSignalTime,
+	}
+
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		c.logger.Printf("[WARN] Could not encode trend state: %v", err)
+		return
+	}
+
+	if err := writeFileAtomic(c.trendStateFile, data); err != nil {
+		c.errorLog.Printf("trend-state", "[WARN] Could not save trend state: %v", err)
+		return
+	}
+	c.errorLog.Clear("trend-state")
+}
+
+// writeFileAtomic writes to a temp file next to path and renames it, so a crash never leaves a truncated file behind
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
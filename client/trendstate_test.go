@@ -0,0 +1,32 @@
+package client
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestReloadedTrendStateSuppressesDuplicate(t *testing.T) {
+	t.Setenv("TREND_STATE_FILE", filepath.Join(t.TempDir(), "trend-state.json"))
+
+	first := newTestClient(t, newFakeCoinbase())
+	indicators := calculateTechnicalIndicatorsSequential(candlesFromCloses(risingCloses(300)), first.indicatorPeriods)
+	if changed, trend, _ := first.detectTrendChange(indicators, ""); !changed || trend != "bullish" {
+		t.Fatalf("first detection = %v %s, want a bullish change", changed, trend)
+	}
+
+	// A restarted client reloads the bullish state and doesn't signal it again
+	restarted := newTestClient(t, newFakeCoinbase())
+	if trend, lastSignal := restarted.trendSnapshot(); trend != "bullish" || lastSignal.IsZero() {
+		t.Fatalf("reloaded state = %s at %v, want bullish with its signal time", trend, lastSignal)
+	}
+	if changed, trend, _ := restarted.detectTrendChange(indicators, ""); changed {
+		t.Errorf("restarted client signalled %s again", trend)
+	}
+
+	// Without the file the restarted client would have signalled it
+	t.Setenv("TREND_STATE_FILE", "")
+	fresh := newTestClient(t, newFakeCoinbase())
+	if changed, _, _ := fresh.detectTrendChange(indicators, ""); !changed {
+		t.Error("client without persisted state did not signal the trend")
+	}
+}
@@ -22,7 +22,7 @@ type Order struct {
 	Price         string    `json:"price,omitempty"`
 	StopPrice     string    `json:"stop_price,omitempty"`
 	LimitPrice    string    `json:"limit_price,omitempty"`
-	Status        string    `json:"status"`
+	Status        string    `json:"status"` // UNKNOWN when the status couldn't be read after placement
 	CreatedAt     time.Time `json:"created_at"`
 	FilledSize    string    `json:"filled_size"`
 	FilledValue   string    `json:"filled_value"`
@@ -32,19 +32,84 @@ type Order struct {
 // TradingRequest represents a trading request for market orders
 type TradingRequest struct {
 	Size       string  `json:"size"`
-	Price      float64 `json:"price"`
+	Price      Money   `json:"price"`
 	Percentage float64 `json:"percentage,omitempty"`
+	PostOnly   bool    `json:"post_only,omitempty"`
+	ReduceOnly bool    `json:"reduce_only,omitempty"` // Sell only: reject if size exceeds the available base balance
 }
 
-// CreateOrderRequest represents the request body for creating orders
-type CreateOrderRequest struct {
-	ProductID  string `json:"product_id"`
-	Side       string `json:"side"`
-	Type       string `json:"type"`
-	Size       string `json:"size,omitempty"`
-	Price      string `json:"price,omitempty"`
-	StopPrice  string `json:"stop_price,omitempty"`
-	LimitPrice string `json:"limit_price,omitempty"`
+// ReplaceOrderRequest represents a request to replace an open order with a new size and price
+type ReplaceOrderRequest struct {
+	Size  string `json:"size,omitempty"`
+	Price Money  `json:"price"`
+}
+
+// ReplaceOrderResult contains the cancelled order ID and the order that replaced it
+type ReplaceOrderResult struct {
+	OldOrderID string `json:"old_order_id"`
+	NewOrderID string `json:"new_order_id"`
+	Order      *Order `json:"order"`
+}
+
+// RebalanceRequest represents a request to move the base currency allocation to a target percentage.
+// TargetBasePct is a pointer so a missing target is rejected rather than read as 0 (sell all base).
+type RebalanceRequest struct {
+	TargetBasePct *float64 `json:"target_base_pct"`
+	TolerancePct  float64  `json:"tolerance_pct,omitempty"`
+	DryRun        bool     `json:"dry_run,omitempty"`
+}
+
+// RebalancePlan describes the trade computed to reach the target allocation
+type RebalancePlan struct {
+	Side           string  `json:"side,omitempty"`
+	Size           string  `json:"size,omitempty"`
+	Price          Money   `json:"price,omitzero"`
+	Value          Money   `json:"value,omitzero"`
+	Fee            Money   `json:"fee,omitzero"`
+	CurrentBasePct float64 `json:"current_base_pct"`
+	TargetBasePct  float64 `json:"target_base_pct"`
+	TotalValue     Money   `json:"total_value"`
+}
+
+// RebalanceResult contains the planned trade and, unless it was a dry run or no-op, the executed order
+type RebalanceResult struct {
+	Plan     *RebalancePlan `json:"planned"`
+	Executed *Order         `json:"executed,omitempty"`
+	DryRun   bool           `json:"dry_run"`
+	NoOp     bool           `json:"no_op"`
+	Reason   string         `json:"reason,omitempty"`
+}
+
+// ClosePositionRequest represents the optional body of a close-position request
+type ClosePositionRequest struct {
+	SlippagePct float64 `json:"slippage_pct,omitempty"` // Sell this far below the best bid (0 uses the default of 0.5%)
+	DryRun      bool    `json:"dry_run,omitempty"`
+}
+
+// ClosePositionResult contains the cancelled orders and the sell planned, or placed, to go flat
+type ClosePositionResult struct {
+	Cancellation   *CancelAllResult `json:"cancellation,omitempty"` // Not set on dry runs, nothing is cancelled
+	CancelledCount int              `json:"cancelled_count"`
+	Size           string           `json:"size,omitempty"`
+	Price          Money            `json:"price,omitzero"`
+	Value          Money            `json:"value,omitzero"`
+	SellOrder      *Order           `json:"sell_order,omitempty"`
+	DryRun         bool             `json:"dry_run"`
+	NoOp           bool             `json:"no_op"`
+	Reason         string           `json:"reason,omitempty"`
+}
+
+// CancelAllResult lists the orders cancelled by CancelAllOrders and the ones that could not be cancelled
+type CancelAllResult struct {
+	Cancelled []string          `json:"cancelled_orders"`
+	Failed    []string          `json:"failed_orders,omitempty"`   // The cancel request itself failed
+	Rejected  []CancelRejection `json:"rejected_orders,omitempty"` // Coinbase answered but refused the cancel
+}
+
+// CancelRejection is an order Coinbase refused to cancel, e.g. because it already filled
+type CancelRejection struct {
+	OrderID string `json:"order_id"`
+	Reason  string `json:"reason"`
 }
 
 // Candle represents a single candle from the Coinbase API
@@ -57,6 +122,16 @@ type Candle struct {
 	Volume string `json:"volume"`
 }
 
+// OHLCCandle is a numeric candle with a Unix-second time, the shape charting libraries expect
+type OHLCCandle struct {
+	Time   int64   `json:"time"`
+	Open   float64 `json:"open"`
+	High   float64 `json:"high"`
+	Low    float64 `json:"low"`
+	Close  float64 `json:"close"`
+	Volume float64 `json:"volume"`
+}
+
 // CandlesResponse represents the response from the candles endpoint
 type CandlesResponse struct {
 	Candles []Candle `json:"candles"`
@@ -72,35 +147,99 @@ type OrderBookEntry struct {
 type OrderBook struct {
 	Bids []OrderBookEntry `json:"bids"`
 	Asks []OrderBookEntry `json:"asks"`
+	// Coinbase's own pricebook summary values
+	Last           string `json:"last,omitempty"`
+	MidMarket      string `json:"mid_market,omitempty"`
+	SpreadBps      string `json:"spread_bps,omitempty"`
+	SpreadAbsolute string `json:"spread_absolute,omitempty"`
 }
 
-// MarketState represents current market information
+// MarketState represents current market information.
+// MidMarket, Last, SpreadBps and SpreadAbsolute are Coinbase's authoritative values from the pricebook response.
 type MarketState struct {
-	ProductID     string    `json:"product_id"`
-	BestBid       string    `json:"best_bid"`
-	BestAsk       string    `json:"best_ask"`
-	Spread        string    `json:"spread"`
-	SpreadPercent string    `json:"spread_percent"`
-	LastPrice     string    `json:"last_price"`
-	Volume24h     string    `json:"volume_24h"`
-	OrderBook     OrderBook `json:"order_book"`
-	Timestamp     int64     `json:"timestamp"`
+	ProductID      string    `json:"product_id"`
+	BestBid        string    `json:"best_bid"`
+	BestAsk        string    `json:"best_ask"`
+	Spread         string    `json:"spread"`
+	SpreadPercent  string    `json:"spread_percent"`
+	LastPrice      string    `json:"last_price"`
+	MidMarket      string    `json:"mid_market"`
+	Last           string    `json:"last"`
+	SpreadBps      string    `json:"spread_bps"`
+	SpreadAbsolute string    `json:"spread_absolute"`
+	Volume24h      string    `json:"volume_24h"`
+	OrderBook      OrderBook `json:"order_book"`
+	Timestamp      int64     `json:"timestamp"`
+}
+
+// ProductStats represents price and 24h statistics for a product
+type ProductStats struct {
+	ProductID                string `json:"product_id"`
+	Price                    string `json:"price"`
+	Open24h                  string `json:"open_24h"`
+	High24h                  string `json:"high_24h"`
+	Low24h                   string `json:"low_24h"`
+	Volume24h                string `json:"volume_24h"`
+	PricePercentageChange24h string `json:"price_percentage_change_24h"`
+	Timestamp                int64  `json:"timestamp"`
+}
+
+// Summary represents a compact status snapshot for chat messages
+type Summary struct {
+	ProductID      string  `json:"product_id"`
+	Price          Money   `json:"price"`
+	Trend          string  `json:"trend"` // "bullish", "bearish", or "neutral"
+	RSI            float64 `json:"rsi"`
+	MACD           float64 `json:"macd"`
+	MACDSignal     float64 `json:"macd_signal"`
+	Change12hPct   float64 `json:"change_12h_pct"`
+	PortfolioQuote Money   `json:"portfolio_quote"`        // Portfolio value in QuoteCurrency
+	QuoteCurrency  string  `json:"quote_currency"`         // The pair's quote currency, e.g. USDC or EUR
+	PortfolioUSD   Money   `json:"portfolio_usd,omitzero"` // Portfolio value in USD, left out when no USD rate is available
+	LastSignal     string  `json:"last_signal,omitempty"`
+	LastSignalTime int64   `json:"last_signal_time,omitempty"`
+	Text           string  `json:"text"`
+	Timestamp      int64   `json:"timestamp"`
+}
+
+// SpreadSample represents the bid/ask spread at a point in time
+type SpreadSample struct {
+	Timestamp     int64   `json:"timestamp"`
+	Spread        float64 `json:"spread"`
+	SpreadPercent float64 `json:"spread_percent"`
+}
+
+// SpreadHistory represents the rolling spread series with summary statistics
+type SpreadHistory struct {
+	ProductID        string         `json:"product_id"`
+	Samples          []SpreadSample `json:"samples"`
+	Count            int            `json:"count"`
+	MinSpread        Money          `json:"min_spread"`
+	MaxSpread        Money          `json:"max_spread"`
+	AvgSpread        Money          `json:"avg_spread"`
+	MinSpreadPercent float64        `json:"min_spread_percent"`
+	MaxSpreadPercent float64        `json:"max_spread_percent"`
+	AvgSpreadPercent float64        `json:"avg_spread_percent"`
 }
 
 // TechnicalIndicators represents calculated technical analysis indicators
 type TechnicalIndicators struct {
-	MACD            float64 `json:"macd"`
-	SignalLine      float64 `json:"signal_line"`
-	EMA12           float64 `json:"ema_12"`
-	EMA26           float64 `json:"ema_26"`
-	EMA200          float64 `json:"ema_200"`
-	RSI             float64 `json:"rsi"`
-	ADX             float64 `json:"adx"`
-	PriceDropPct12h float64 `json:"price_drop_pct_12h"`
-	VolumeSpike     bool    `json:"volume_spike"`
-	CurrentPrice    float64 `json:"current_price"`
-	AverageVolume   float64 `json:"average_volume"`
-	LastVolume      float64 `json:"last_volume"`
+	MACD               float64 `json:"macd"`
+	SignalLine         float64 `json:"signal_line"`
+	EMA12              float64 `json:"ema_12"`
+	EMA26              float64 `json:"ema_26"`
+	EMA200             float64 `json:"ema_200"`
+	RSI                float64 `json:"rsi"`
+	ADX                float64 `json:"adx"`
+	PriceDropPct12h    float64 `json:"price_drop_pct_12h"`
+	PriceZScore        float64 `json:"price_zscore"`        // Distance of price from the long EMA in residual standard deviations
+	Volatility         float64 `json:"volatility"`          // EWMA of squared log returns, as a per-candle standard deviation in percent
+	BaselineVolatility float64 `json:"baseline_volatility"` // Equally weighted root mean square of log returns over all candles, in percent
+	Partial            bool    `json:"partial,omitempty"`   // Computation stopped early on a bearish signal (EARLY_SIGNAL_EXIT), some fields may be zero
+	VolumeSpike        bool    `json:"volume_spike"`
+	CurrentPrice       float64 `json:"current_price"`
+	AverageVolume      float64 `json:"average_volume"`
+	LastVolume         float64 `json:"last_volume"`
 	// Triangle analysis
 	TrianglePattern  string    `json:"triangle_pattern"`  // "ascending", "descending", "symmetrical", "none"
 	TriangleBreakout string    `json:"triangle_breakout"` // "bullish", "bearish", "none"
@@ -111,10 +250,39 @@ type TechnicalIndicators struct {
 
 // SignalResponse represents the response from the signal endpoint
 type SignalResponse struct {
-	BearishSignal bool                `json:"bearish_signal"`
-	Indicators    TechnicalIndicators `json:"indicators"`
-	Triggers      []string            `json:"triggers,omitempty"`
-	Timestamp     int64               `json:"timestamp"`
+	BearishSignal      bool                `json:"bearish_signal"`
+	Recommendation     string              `json:"recommendation"`      // BUY (bullish), SELL (bearish) or HOLD (neutral)
+	EffectiveThreshold float64             `json:"effective_threshold"` // Weighted score needed to call a trend (scaled by volatility with ADAPTIVE_THRESHOLDS)
+	Indicators         TechnicalIndicators `json:"indicators"`
+	Triggers           []string            `json:"triggers,omitempty"`
+	Timestamp          int64               `json:"timestamp"`
+}
+
+// SignalSimulation is how the trend and dip detectors classify a set of hypothetical indicators
+type SignalSimulation struct {
+	BearishScore   float64  `json:"bearish_score"`
+	BullishScore   float64  `json:"bullish_score"`
+	Threshold      float64  `json:"threshold"` // Score needed to call a trend (scaled by volatility with ADAPTIVE_THRESHOLDS)
+	Trend          string   `json:"trend"`     // "bullish", "bearish", or "neutral"
+	Recommendation string   `json:"recommendation"`
+	Triggers       []string `json:"triggers"`
+	DipScore       float64  `json:"dip_score"`
+	DipThreshold   float64  `json:"dip_threshold"`
+	DipDetected    bool     `json:"dip_detected"` // A live dip would also signal bearish, outside its cooldown
+	DipTriggers    []string `json:"dip_triggers"`
+}
+
+// DipStatus is the immediate-dip detector's reading of the current signal indicators
+type DipStatus struct {
+	ProductID                string              `json:"product_id"`
+	Detected                 bool                `json:"detected"`                   // Score reached the threshold
+	Score                    float64             `json:"score"`                      // Weighted dip score
+	Threshold                float64             `json:"threshold"`                  // Score needed to detect a dip
+	Triggers                 []string            `json:"triggers"`                   // Conditions that added to the score (minor ones add without a trigger)
+	CooldownRemainingSeconds int64               `json:"cooldown_remaining_seconds"` // A detected dip is held back until the cooldown ends
+	LastTrend                string              `json:"last_trend"`                 // Dips only signal when the trend isn't bearish already
+	Indicators               TechnicalIndicators `json:"indicators"`
+	Timestamp                int64               `json:"timestamp"`
 }
 
 // Trade represents a completed trade
@@ -131,41 +299,128 @@ type Trade struct {
 	ExecutedAt  int64  `json:"executed_at"`
 }
 
+// RoundTrip is a buy matched (first-in-first-out) with a later sell of the same size
+type RoundTrip struct {
+	BuyTradeID     string  `json:"buy_trade_id"`
+	SellTradeID    string  `json:"sell_trade_id"`
+	Size           string  `json:"size"`
+	BuyPrice       Money   `json:"buy_price"`
+	SellPrice      Money   `json:"sell_price"`
+	Fees           Money   `json:"fees"`
+	RealizedPnL    Money   `json:"realized_pnl"`
+	RealizedPnLPct float64 `json:"realized_pnl_pct"`
+	BuyTime        int64   `json:"buy_time"`
+	SellTime       int64   `json:"sell_time"`
+	HoldingSeconds int64   `json:"holding_seconds"`
+}
+
+// TradeStats aggregates realized round trips over a period; open (unsold) buys are excluded from realized stats
+type TradeStats struct {
+	ProductID             string      `json:"product_id"`
+	Period                string      `json:"period"`
+	TotalTrades           int         `json:"total_trades"`
+	RoundTripCount        int         `json:"round_trip_count"`
+	Wins                  int         `json:"wins"`
+	Losses                int         `json:"losses"`
+	WinRate               float64     `json:"win_rate"` // Percentage of round trips with positive P&L
+	RealizedPnL           Money       `json:"realized_pnl"`
+	AverageWin            Money       `json:"average_win"`
+	AverageLoss           Money       `json:"average_loss"`
+	TotalFees             Money       `json:"total_fees"`
+	AverageHoldingSeconds int64       `json:"average_holding_seconds"`
+	OpenBuys              int         `json:"open_buys"`
+	OpenSize              string      `json:"open_size"`
+	UnmatchedSellSize     string      `json:"unmatched_sell_size"` // Sold size with no buy in the period
+	RoundTrips            []RoundTrip `json:"round_trips"`
+	Timestamp             int64       `json:"timestamp"`
+}
+
 // AccountValue represents account balance at a point in time
 type AccountValue struct {
-	Timestamp int64   `json:"timestamp"`
-	BTC       float64 `json:"btc"`
-	USDC      float64 `json:"usdc"`
-	TotalUSD  float64 `json:"total_usd"` // Total value in USD
+	Timestamp  int64   `json:"timestamp"`
+	BTC        float64 `json:"btc"`         // Base currency balance
+	USDC       float64 `json:"usdc"`        // Quote currency balance
+	TotalQuote float64 `json:"total_quote"` // Total value in the pair's quote currency
+	// Deprecated: same as TotalQuote, the field's name before other quote currencies were supported.
+	// Kept for existing consumers for one release, read total_quote instead.
+	TotalUSD   float64 `json:"total_usd"`
+	TotalValue float64 `json:"total_value"` // Total value in Currency (VALUATION_CURRENCY, or the quote currency if no cross rate was found)
+	Currency   string  `json:"currency"`
+}
+
+// IndicatorSeries holds per-candle indicator values, aligned index-for-index with the candles.
+// Entries are nil (null in JSON) until enough candles exist to compute the indicator.
+type IndicatorSeries struct {
+	EMA12  []*float64 `json:"ema_12"`
+	EMA26  []*float64 `json:"ema_26"`
+	RSI    []*float64 `json:"rsi"`
+	MACD   []*float64 `json:"macd"`
+	Signal []*float64 `json:"signal"`
+}
+
+// IndicatorSeriesResponse represents indicator series with the candle timestamps they belong to
+type IndicatorSeriesResponse struct {
+	ProductID   string          `json:"product_id"`
+	Period      string          `json:"period"`
+	Granularity string          `json:"granularity"`
+	Count       int             `json:"count"`
+	Timestamps  []int64         `json:"timestamps"` // Candle start times (Unix seconds)
+	Close       []float64       `json:"close"`
+	Indicators  IndicatorSeries `json:"indicators"`
+}
+
+// Account value sources for graph data
+const (
+	ValueSourceAuto     = "auto"     // In-memory history, falling back to values computed from trades
+	ValueSourceMemory   = "memory"   // In-memory history only
+	ValueSourceComputed = "computed" // Always reconstruct values from trades
+)
+
+// GraphOptions holds optional overrides for GetGraphData
+type GraphOptions struct {
+	Granularity string // Candle granularity (defaults depend on the period)
+	ValueSource string // Account value source: auto (default), memory or computed
+}
+
+// GraphSummary holds summary statistics for a graph period
+type GraphSummary struct {
+	TotalTrades    int     `json:"total_trades"`
+	BuyTrades      int     `json:"buy_trades"`
+	SellTrades     int     `json:"sell_trades"`
+	TotalVolume    Money   `json:"total_volume"`
+	TotalFees      Money   `json:"total_fees"`
+	StartingValue  Money   `json:"starting_value"`
+	EndingValue    Money   `json:"ending_value"`
+	ValueChange    Money   `json:"value_change"`
+	ValueChangePct float64 `json:"value_change_pct"`
+	BestPrice      Money   `json:"best_price"`
+	WorstPrice     Money   `json:"worst_price"`
+	AveragePrice   Money   `json:"average_price"`
+	RealizedPnL    Money   `json:"realized_pnl"`   // Net P&L of sells matched first-in-first-out with buys in the period
+	UnrealizedPnL  Money   `json:"unrealized_pnl"` // Buys in the period not sold yet, marked to the last candle close, net of their fees
+	FeesPaid       Money   `json:"fees_paid"`      // Fees included in the realized and unrealized P&L (sells of older coins excluded)
+	HasTrades      bool    `json:"has_trades"`     // False when there were no trades in the period
+	HasPriceData   bool    `json:"has_price_data"` // False when no candle had a usable close price
+	HasValueData   bool    `json:"has_value_data"` // False when no account values were available
+}
+
+// ChartOptions holds optional settings for GenerateChartPNG
+type ChartOptions struct {
+	MaxPoints int            // Asset value points to plot before LTTB downsampling kicks in (0 uses the default of 200)
+	Location  *time.Location // Timezone for axis labels and the title date range (nil uses CHART_TIMEZONE)
+	Volume    bool           // Add a volume panel under the price chart
 }
 
 // GraphData represents the complete data for charting
 type GraphData struct {
-	Period        string         `json:"period"` // "week" or "month"
-	StartTime     int64          `json:"start_time"`
-	EndTime       int64          `json:"end_time"`
-	Candles       []Candle       `json:"candles"`
-	Trades        []Trade        `json:"trades"`
-	AccountValues []AccountValue `json:"account_values"`
-	Indicators    struct {
-		EMA12  []float64 `json:"ema_12"`
-		EMA26  []float64 `json:"ema_26"`
-		RSI    []float64 `json:"rsi"`
-		MACD   []float64 `json:"macd"`
-		Signal []float64 `json:"signal"`
-	} `json:"indicators"`
-	Summary struct {
-		TotalTrades    int     `json:"total_trades"`
-		BuyTrades      int     `json:"buy_trades"`
-		SellTrades     int     `json:"sell_trades"`
-		TotalVolume    float64 `json:"total_volume"`
-		TotalFees      float64 `json:"total_fees"`
-		StartingValue  float64 `json:"starting_value"`
-		EndingValue    float64 `json:"ending_value"`
-		ValueChange    float64 `json:"value_change"`
-		ValueChangePct float64 `json:"value_change_pct"`
-		BestPrice      float64 `json:"best_price"`
-		WorstPrice     float64 `json:"worst_price"`
-		AveragePrice   float64 `json:"average_price"`
-	} `json:"summary"`
+	Period        string          `json:"period"` // "week" or "month"
+	StartTime     int64           `json:"start_time"`
+	EndTime       int64           `json:"end_time"`
+	Candles       []Candle        `json:"candles"`
+	Trades        []Trade         `json:"trades"`
+	AccountValues []AccountValue  `json:"account_values"`
+	ValueSource   string          `json:"value_source"` // Source actually used for AccountValues
+	Indicators    IndicatorSeries `json:"indicators"`
+	Summary       GraphSummary    `json:"summary"`
+	Warnings      []string        `json:"warnings,omitempty"` // Parts that couldn't be computed (e.g. "trade history unavailable")
 }
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultValuationCurrency is the currency asset values are reported in when VALUATION_CURRENCY is unset
+const defaultValuationCurrency = "USD"
+
+// usdEquivalent reports whether a currency is treated as 1:1 with USD (Coinbase converts USDC at par)
+func usdEquivalent(currency string) bool {
+	return currency == "USD" || currency == "USDC"
+}
+
+// pairCurrencies splits the configured trading pair into its base and quote currencies
+func (c *CoinbaseClient) pairCurrencies() (string, string, error) {
+	parts := strings.Split(c.tradingPair, "-")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("invalid trading pair format: %s", c.tradingPair)
+	}
+	return parts[0], parts[1], nil
+}
+
+// pairBalances returns the available base and quote balances of the configured trading pair
+func (c *CoinbaseClient) pairBalances() (float64, float64, error) {
+	base, quote, err := c.pairCurrencies()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	accounts, err := c.GetAccounts()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get current accounts: %w", err)
+	}
+
+	var baseAccount, quoteAccount *Account
+	for i := range accounts {
+		if accounts[i].Currency == base {
+			baseAccount = &accounts[i]
+		} else if accounts[i].Currency == quote {
+			quoteAccount = &accounts[i]
+		}
+	}
+	if baseAccount == nil || quoteAccount == nil {
+		return 0, 0, fmt.Errorf("missing %s or %s accounts", base, quote)
+	}
+
+	baseBalance, _ := strconv.ParseFloat(baseAccount.AvailableBalance, 64)
+	quoteBalance, _ := strconv.ParseFloat(quoteAccount.AvailableBalance, 64)
+	return baseBalance, quoteBalance, nil
+}
+
+// conversionRate returns how many units of to one unit of from is worth, looking up the FROM-TO
+// product and falling back to the inverse of TO-FROM. USD and USDC convert at par.
+func (c *CoinbaseClient) conversionRate(from, to string) (float64, error) {
+	if from == to || (usdEquivalent(from) && usdEquivalent(to)) {
+		return 1, nil
+	}
+
+	if product, err := c.getProductByID(from + "-" + to); err == nil {
+		if rate, err := strconv.ParseFloat(product.Price, 64); err == nil && rate > 0 {
+			return rate, nil
+		}
+	}
+	if product, err := c.getProductByID(to + "-" + from); err == nil {
+		if rate, err := strconv.ParseFloat(product.Price, 64); err == nil && rate > 0 {
+			return 1 / rate, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no %s-%s or %s-%s price available", from, to, to, from)
+}
+
+// valuationRate returns the rate converting quote currency amounts into VALUATION_CURRENCY, and the
+// currency actually used. When no cross rate can be found values stay in the quote currency.
+func (c *CoinbaseClient) valuationRate(quote string) (float64, string) {
+	rate, err := c.conversionRate(quote, c.valuationCurrency)
+	if err != nil {
+		c.logger.Printf("[WARN] Cannot value %s in %s (%v), reporting asset values in %s", quote, c.valuationCurrency, err, quote)
+		return 1, quote
+	}
+	return rate, c.valuationCurrency
+}
+
+// currencySymbols maps currency codes to the symbol shown in chart titles; others are shown by code
+var currencySymbols = map[string]string{
+	"USD":  "$",
+	"USDC": "$",
+	"USDT": "$",
+	"EUR":  "€",
+	"GBP":  "£",
+	"JPY":  "¥",
+	"CAD":  "CA$",
+	"AUD":  "A$",
+}
+
+// formatAmount formats an amount with its currency symbol, e.g. "€1234.50", or "1234.50 SGD" when the
+// currency has no known symbol
+func formatAmount(amount float64, currency string) string {
+	if symbol, ok := currencySymbols[currency]; ok {
+		return fmt.Sprintf("%s%.2f", symbol, amount)
+	}
+	return fmt.Sprintf("%.2f %s", amount, currency)
+}
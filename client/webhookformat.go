@@ -0,0 +1,172 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signal webhook formats (WEBHOOK_FORMAT)
+const (
+	WebhookFormatRaw     = "raw"     // GET with query parameters, for n8n and similar (default)
+	WebhookFormatSlack   = "slack"   // POST a Slack incoming webhook message (text + blocks)
+	WebhookFormatDiscord = "discord" // POST a Discord webhook message (content + embeds)
+)
+
+// Discord embed colors for each trend
+const (
+	discordColorBearish = 0xE01E5A
+	discordColorBullish = 0x2EB67D
+	discordColorNeutral = 0x9E9E9E
+)
+
+// RawWebhook reports whether signal webhooks are sent as GET query parameters (WEBHOOK_FORMAT=raw)
+func (c *CoinbaseClient) RawWebhook() bool {
+	return c.webhookFormat == WebhookFormatRaw
+}
+
+// newSignalWebhookRequest builds the signal webhook request in the configured format
+func (c *CoinbaseClient) newSignalWebhookRequest(signal *SignalResponse, sequence uint64) (*http.Request, error) {
+	var payload map[string]interface{}
+	switch c.webhookFormat {
+	case WebhookFormatSlack:
+		payload = slackWebhookPayload(signal, c.tradingPair, sequence)
+	case WebhookFormatDiscord:
+		payload = discordWebhookPayload(signal, c.tradingPair, sequence)
+	default:
+		req, err := http.NewRequest("GET", c.webhookURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		q := req.URL.Query()
+		q.Add("signal", "true")
+		q.Add("bearish", strconv.FormatBool(signal.BearishSignal))
+		q.Add("recommendation", signal.Recommendation)
+		q.Add("triggers", strings.Join(signal.Triggers, ","))
+		q.Add("timestamp", fmt.Sprintf("%d", signal.Timestamp))
+		q.Add("pair", c.tradingPair)
+		q.Add("sequence", fmt.Sprintf("%d", sequence))
+		req.URL.RawQuery = q.Encode()
+		return req, nil
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s webhook message: %w", c.webhookFormat, err)
+	}
+	req, err := http.NewRequest("POST", c.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// signalTrend names the trend behind a signal's recommendation
+func signalTrend(signal *SignalResponse) string {
+	switch signal.Recommendation {
+	case "SELL":
+		return "bearish"
+	case "BUY":
+		return "bullish"
+	default:
+		return "neutral"
+	}
+}
+
+// signalHeadline is the one-line summary shared by the chat formats, e.g. "📉 BTC-USDC: SELL (bearish)"
+func signalHeadline(signal *SignalResponse, pair string) string {
+	icon := "➖"
+	switch signalTrend(signal) {
+	case "bearish":
+		icon = "📉"
+	case "bullish":
+		icon = "📈"
+	}
+	return fmt.Sprintf("%s %s: %s (%s)", icon, pair, signal.Recommendation, signalTrend(signal))
+}
+
+// signalTriggers lists the triggers of a signal for display
+func signalTriggers(signal *SignalResponse) string {
+	if len(signal.Triggers) == 0 {
+		return "none"
+	}
+	return strings.Join(signal.Triggers, ", ")
+}
+
+// slackWebhookPayload formats a signal as a Slack incoming webhook message. The text is the notification
+// fallback; the blocks show the price and main indicators, with triggers and sequence as context.
+func slackWebhookPayload(signal *SignalResponse, pair string, sequence uint64) map[string]interface{} {
+	headline := signalHeadline(signal, pair)
+	indicators := signal.Indicators
+
+	field := func(name, value string) map[string]interface{} {
+		return map[string]interface{}{"type": "mrkdwn", "text": fmt.Sprintf("*%s*\n%s", name, value)}
+	}
+
+	return map[string]interface{}{
+		"text": headline,
+		"blocks": []map[string]interface{}{
+			{
+				"type": "header",
+				"text": map[string]interface{}{"type": "plain_text", "text": headline},
+			},
+			{
+				"type": "section",
+				"fields": []map[string]interface{}{
+					field("Price", fmt.Sprintf("%.2f", indicators.CurrentPrice)),
+					field("RSI", fmt.Sprintf("%.1f", indicators.RSI)),
+					field("MACD / Signal", fmt.Sprintf("%.4f / %.4f", indicators.MACD, indicators.SignalLine)),
+					field("12h change", fmt.Sprintf("%.2f%%", indicators.PriceDropPct12h)),
+				},
+			},
+			{
+				"type": "context",
+				"elements": []map[string]interface{}{
+					{"type": "mrkdwn", "text": fmt.Sprintf("Triggers: %s · sequence %d", signalTriggers(signal), sequence)},
+				},
+			},
+		},
+	}
+}
+
+// discordWebhookPayload formats a signal as a Discord webhook message: the headline as content and one
+// embed, colored by trend, with the price and main indicators as inline fields
+func discordWebhookPayload(signal *SignalResponse, pair string, sequence uint64) map[string]interface{} {
+	indicators := signal.Indicators
+
+	color := discordColorNeutral
+	switch signalTrend(signal) {
+	case "bearish":
+		color = discordColorBearish
+	case "bullish":
+		color = discordColorBullish
+	}
+
+	field := func(name, value string) map[string]interface{} {
+		return map[string]interface{}{"name": name, "value": value, "inline": true}
+	}
+
+	return map[string]interface{}{
+		"content": signalHeadline(signal, pair),
+		"embeds": []map[string]interface{}{
+			{
+				"title":       fmt.Sprintf("%s signal", pair),
+				"description": fmt.Sprintf("Triggers: %s", signalTriggers(signal)),
+				"color":       color,
+				"fields": []map[string]interface{}{
+					field("Price", fmt.Sprintf("%.2f", indicators.CurrentPrice)),
+					field("RSI", fmt.Sprintf("%.1f", indicators.RSI)),
+					field("MACD / Signal", fmt.Sprintf("%.4f / %.4f", indicators.MACD, indicators.SignalLine)),
+					field("12h change", fmt.Sprintf("%.2f%%", indicators.PriceDropPct12h)),
+				},
+				"footer":    map[string]interface{}{"text": fmt.Sprintf("sequence %d", sequence)},
+				"timestamp": time.Unix(signal.Timestamp, 0).UTC().Format(time.RFC3339),
+			},
+		},
+	}
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// testSignal is a bearish signal as GetSignal would return it
+func testSignal() *SignalResponse {
+	return &SignalResponse{
+		BearishSignal:  true,
+		Recommendation: "SELL",
+		Indicators: TechnicalIndicators{
+			CurrentPrice: 43210.5, RSI: 28.44, MACD: -12.34567, SignalLine: -8.9, PriceDropPct12h: -5.678,
+		},
+		Triggers:  []string{"MACD_BEARISH_CROSSOVER", "RSI_MOMENTUM_BREAKDOWN"},
+		Timestamp: 1718000000,
+	}
+}
+
+// assertJSON compares the JSON encoding of got with the expected JSON document, ignoring formatting
+func assertJSON(t *testing.T, got interface{}, want string) {
+	t.Helper()
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal(data, &gotValue); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("invalid expected JSON: %v", err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("payload = %s\nwant %s", data, want)
+	}
+}
+
+func TestSlackWebhookPayload(t *testing.T) {
+	assertJSON(t, slackWebhookPayload(testSignal(), "BTC-USDC", 42), `{
+		"text": "📉 BTC-USDC: SELL (bearish)",
+		"blocks": [
+			{"type": "header", "text": {"type": "plain_text", "text": "📉 BTC-USDC: SELL (bearish)"}},
+			{"type": "section", "fields": [
+				{"type": "mrkdwn", "text": "*Price*\n43210.50"},
+				{"type": "mrkdwn", "text": "*RSI*\n28.4"},
+				{"type": "mrkdwn", "text": "*MACD / Signal*\n-12.3457 / -8.9000"},
+				{"type": "mrkdwn", "text": "*12h change*\n-5.68%"}
+			]},
+			{"type": "context", "elements": [
+				{"type": "mrkdwn", "text": "Triggers: MACD_BEARISH_CROSSOVER, RSI_MOMENTUM_BREAKDOWN · sequence 42"}
+			]}
+		]
+	}`)
+}
+
+func TestDiscordWebhookPayload(t *testing.T) {
+	assertJSON(t, discordWebhookPayload(testSignal(), "BTC-USDC", 42), `{
+		"content": "📉 BTC-USDC: SELL (bearish)",
+		"embeds": [{
+			"title": "BTC-USDC signal",
+			"description": "Triggers: MACD_BEARISH_CROSSOVER, RSI_MOMENTUM_BREAKDOWN",
+			"color": 14687834,
+			"fields": [
+				{"name": "Price", "value": "43210.50", "inline": true},
+				{"name": "RSI", "value": "28.4", "inline": true},
+				{"name": "MACD / Signal", "value": "-12.3457 / -8.9000", "inline": true},
+				{"name": "12h change", "value": "-5.68%", "inline": true}
+			],
+			"footer": {"text": "sequence 42"},
+			"timestamp": "2024-06-10T06:13:20Z"
+		}]
+	}`)
+
+	neutral := &SignalResponse{Recommendation: "HOLD", Timestamp: 1718000000}
+	payload := discordWebhookPayload(neutral, "ETH-EUR", 1)
+	embed := payload["embeds"].([]map[string]interface{})[0]
+	if payload["content"] != "➖ ETH-EUR: HOLD (neutral)" || embed["color"] != discordColorNeutral || embed["description"] != "Triggers: none" {
+		t.Errorf("neutral payload = %v", payload)
+	}
+}
+
+func TestSignalWebhookRequestFormats(t *testing.T) {
+	tests := []struct {
+		format      string
+		method      string
+		contentType string
+	}{
+		{WebhookFormatRaw, http.MethodGet, ""},
+		{WebhookFormatSlack, http.MethodPost, "application/json"},
+		{WebhookFormatDiscord, http.MethodPost, "application/json"},
+	}
+	for _, tt := range tests {
+		c := &CoinbaseClient{tradingPair: "BTC-USDC", webhookURL: "https://hooks.example/signal", webhookFormat: tt.format}
+		req, err := c.newSignalWebhookRequest(testSignal(), 42)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		if req.Method != tt.method || req.Header.Get("Content-Type") != tt.contentType {
+			t.Errorf("%s: %s with content type %q, want %s with %q", tt.format, req.Method, req.Header.Get("Content-Type"), tt.method, tt.contentType)
+		}
+		if tt.format == WebhookFormatRaw {
+			if q := req.URL.Query(); q.Get("bearish") != "true" || q.Get("recommendation") != "SELL" || q.Get("sequence") != "42" || q.Get("pair") != "BTC-USDC" {
+				t.Errorf("raw query = %s", req.URL.RawQuery)
+			}
+			continue
+		}
+		body, _ := io.ReadAll(req.Body)
+		if !json.Valid(body) {
+			t.Errorf("%s: body is not JSON: %s", tt.format, body)
+		}
+	}
+}
+
+func TestRawWebhookSendsTheBearishFlag(t *testing.T) {
+	c := &CoinbaseClient{tradingPair: "BTC-USDC", webhookURL: "https://hooks.example/signal", webhookFormat: WebhookFormatRaw}
+	bullish := &SignalResponse{Recommendation: "BUY", Timestamp: 1718000000}
+	req, err := c.newSignalWebhookRequest(bullish, 7)
+	if err != nil {
+		t.Fatalf("newSignalWebhookRequest: %v", err)
+	}
+	if q := req.URL.Query(); q.Get("bearish") != "false" || q.Get("recommendation") != "BUY" {
+		t.Errorf("BUY signal query = %s, want bearish=false", req.URL.RawQuery)
+	}
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// webhookSequence numbers every outgoing webhook event. It is shared by all pair clients, so receivers see
+// one increasing series per process (and across restarts), whichever pair or webhook URL the event is for.
+var webhookSequence struct {
+	sync.Mutex
+	value  uint64
+	loaded bool
+}
+
+// NextWebhookSequence returns the sequence number for a new webhook event. Retries of the same event must
+// reuse it, so receivers can drop duplicates and spot out-of-order deliveries (a lower number than the last).
+// With WEBHOOK_SEQUENCE_FILE the counter is persisted after every increment; without it, it starts from the
+// process start time in milliseconds, which keeps it increasing across restarts as long as the clock does.
+func (c *CoinbaseClient) NextWebhookSequence() uint64 {
+	webhookSequence.Lock()
+	defer webhookSequence.Unlock()
+
+	if !webhookSequence.loaded {
+		webhookSequence.value = c.loadWebhookSequence()
+		webhookSequence.loaded = true
+	}
+	webhookSequence.value++
+
+	if c.webhookSequenceFile != "" {
+		if err := writeFileAtomic(c.webhookSequenceFile, []byte(strconv.FormatUint(webhookSequence.value, 10)+"\n")); err != nil {
+			c.errorLog.Printf("webhook-sequence", "[WARN] Could not save webhook sequence: %v", err)
+		} else {
+			c.errorLog.Clear("webhook-sequence")
+		}
+	}
+	return webhookSequence.value
+}
+
+// loadWebhookSequence reads the last persisted sequence number, falling back to the current time in milliseconds
+func (c *CoinbaseClient) loadWebhookSequence() uint64 {
+	seed := uint64(time.Now().UnixMilli())
+	if c.webhookSequenceFile == "" {
+		return seed
+	}
+
+	value, err := readWebhookSequence(c.webhookSequenceFile)
+	if err != nil {
+		c.logger.Printf("[WARN] Could not read webhook sequence, starting from %d: %v", seed, err)
+		return seed
+	}
+	return value
+}
+
+// readWebhookSequence parses the sequence file; a missing file starts the sequence at zero
+func readWebhookSequence(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook sequence file %s: %w", path, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+)
+
+// ScoringWeights holds the points the trend and dip detectors add for each condition they find.
+// The conditions themselves (e.g. RSI below 30) are fixed; only what they weigh is configurable.
+type ScoringWeights struct {
+	Bearish TrendScoreWeights `json:"bearish"`
+	Bullish TrendScoreWeights `json:"bullish"`
+	Dip     DipScoreWeights   `json:"dip"`
+}
+
+// TrendScoreWeights weighs the conditions of the bearish or bullish trend score. "Bonus" weights multiply
+// the strength of a condition: the MACD/signal distance, or the EMA gap in percent.
+type TrendScoreWeights struct {
+	MACDCrossover          float64 `json:"macd_crossover"`            // MACD crossed the signal line
+	MACDStrong             float64 `json:"macd_strong"`               // ... and MACD is beyond ±0.1
+	MACDStrengthBonus      float64 `json:"macd_strength_bonus"`       // Per unit of MACD/signal distance, when strong
+	EMACrossover           float64 `json:"ema_crossover"`             // Short EMA crossed the long EMA
+	EMAStrengthBonus       float64 `json:"ema_strength_bonus"`        // Per percent of EMA gap
+	RSIStrong              float64 `json:"rsi_strong"`                // RSI below 30 (bearish) or above 70 (bullish)
+	RSIModerate            float64 `json:"rsi_moderate"`              // RSI below 40 or above 60
+	RSISlight              float64 `json:"rsi_slight"`                // RSI below 45 or above 55
+	PriceChangeStrong      float64 `json:"price_change_strong"`       // 12h change beyond 5%
+	PriceChangeModerate    float64 `json:"price_change_moderate"`     // 12h change beyond 3%
+	PriceChangeSlight      float64 `json:"price_change_slight"`       // 12h change beyond 1%
+	TrendEMAConfirmed      float64 `json:"trend_ema_confirmed"`       // Price on the trend side of EMA200, confirmed by RSI
+	TrendEMAConfirmedBonus float64 `json:"trend_ema_confirmed_bonus"` // Per percent of distance from EMA200
+	TrendEMA               float64 `json:"trend_ema"`                 // Price on the trend side of EMA200 without RSI confirmation
+	TrendEMABonus          float64 `json:"trend_ema_bonus"`           // Per percent of distance from EMA200
+	ADXWithMomentum        float64 `json:"adx_with_momentum"`         // ADX above 25 with MACD on the trend side
+	ADXWithoutMomentum     float64 `json:"adx_without_momentum"`      // ADX above 25 alone
+	VolumeSpike            float64 `json:"volume_spike"`              // Volume spike with a 12h change beyond 2%
+	TriangleBreakout       float64 `json:"triangle_breakout"`         // Confident triangle broken out in the trend direction
+	TrianglePattern        float64 `json:"triangle_pattern"`          // Confident descending (bearish) or ascending (bullish) triangle
+	TriangleConsolidation  float64 `json:"triangle_consolidation"`    // Confident symmetrical triangle not broken out yet
+}
+
+// DipScoreWeights weighs the conditions of the immediate-dip detector, which fires at Threshold points
+type DipScoreWeights struct {
+	Threshold          float64 `json:"threshold"`             // Score at which a dip is detected
+	PriceDropStrong    float64 `json:"price_drop_strong"`     // 12h drop beyond 7%
+	PriceDropModerate  float64 `json:"price_drop_moderate"`   // 12h drop beyond 5%
+	PriceDropSlight    float64 `json:"price_drop_slight"`     // 12h drop beyond 3%
+	RSIExtreme         float64 `json:"rsi_extreme"`           // RSI below 25
+	RSIOversold        float64 `json:"rsi_oversold"`          // RSI below 35
+	MACDStrong         float64 `json:"macd_strong"`           // MACD below the signal line and -0.15
+	MACDStrengthBonus  float64 `json:"macd_strength_bonus"`   // Per unit of MACD/signal distance, when strong
+	MACDModerate       float64 `json:"macd_moderate"`         // MACD below the signal line and -0.05
+	MACDSlight         float64 `json:"macd_slight"`           // MACD below the signal line
+	EMACrossover       float64 `json:"ema_crossover"`         // Short EMA below the long EMA
+	EMAStrengthBonus   float64 `json:"ema_strength_bonus"`    // Per percent of EMA gap
+	VolumeSpike        float64 `json:"volume_spike"`          // Volume spike with a 12h drop beyond 2%
+	StrongMomentum     float64 `json:"strong_momentum"`       // ADX above 25 with MACD below the signal line
+	BelowTrendEMA      float64 `json:"below_trend_ema"`       // Price below EMA200 with RSI below 40
+	BelowTrendEMABonus float64 `json:"below_trend_ema_bonus"` // Per percent below EMA200
+}
+
+// defaultTrendScoreWeights returns the built-in trend weights, the same for both directions
+func defaultTrendScoreWeights() TrendScoreWeights {
+	return TrendScoreWeights{
+		MACDCrossover:          1.5,
+		MACDStrong:             2.0,
+		MACDStrengthBonus:      10,
+		EMACrossover:           2.0,
+		EMAStrengthBonus:       0.1,
+		RSIStrong:              2.0,
+		RSIModerate:            1.5,
+		RSISlight:              0.5,
+		PriceChangeStrong:      2.0,
+		PriceChangeModerate:    1.5,
+		PriceChangeSlight:      0.5,
+		TrendEMAConfirmed:      1.5,
+		TrendEMAConfirmedBonus: 0.1,
+		TrendEMA:               1.0,
+		TrendEMABonus:          0.05,
+		ADXWithMomentum:        1.5,
+		ADXWithoutMomentum:     0.5,
+		VolumeSpike:            0.5,
+		TriangleBreakout:       2.0,
+		TrianglePattern:        1.5,
+		TriangleConsolidation:  0.5,
+	}
+}
+
+// defaultScoringWeights returns the built-in weights of every detector
+func defaultScoringWeights() ScoringWeights {
+	return ScoringWeights{
+		Bearish: defaultTrendScoreWeights(),
+		Bullish: defaultTrendScoreWeights(),
+		Dip: DipScoreWeights{
+			Threshold:          6.0,
+			PriceDropStrong:    3.0,
+			PriceDropModerate:  2.0,
+			PriceDropSlight:    1.0,
+			RSIExtreme:         2.5,
+			RSIOversold:        1.5,
+			MACDStrong:         2.5,
+			MACDStrengthBonus:  10,
+			MACDModerate:       2.0,
+			MACDSlight:         1.0,
+			EMACrossover:       2.0,
+			EMAStrengthBonus:   0.1,
+			VolumeSpike:        1.0,
+			StrongMomentum:     1.5,
+			BelowTrendEMA:      1.0,
+			BelowTrendEMABonus: 0.05,
+		},
+	}
+}
+
+// loadScoringWeights reads SCORING_WEIGHTS_FILE over the built-in weights: the file only needs the weights
+// it changes. Unknown keys, negative weights and a non-positive dip threshold are rejected.
+func loadScoringWeights() (ScoringWeights, error) {
+	weights := defaultScoringWeights()
+
+	path := os.Getenv("SCORING_WEIGHTS_FILE")
+	if path == "" {
+		return weights, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return weights, fmt.Errorf("failed to read SCORING_WEIGHTS_FILE %s: %w", path, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&weights); err != nil {
+		return weights, fmt.Errorf("invalid SCORING_WEIGHTS_FILE %s: %w", path, err)
+	}
+	if err := weights.validate(); err != nil {
+		return weights, fmt.Errorf("invalid SCORING_WEIGHTS_FILE %s: %w", path, err)
+	}
+	return weights, nil
+}
+
+// validate checks no weight is negative and the dip threshold is positive
+func (w ScoringWeights) validate() error {
+	sections := map[string]interface{}{"bearish": w.Bearish, "bullish": w.Bullish, "dip": w.Dip}
+	for _, section := range []string{"bearish", "bullish", "dip"} {
+		value := reflect.ValueOf(sections[section])
+		for i := 0; i < value.NumField(); i++ {
+			weight := value.Field(i).Float()
+			if weight < 0 {
+				return fmt.Errorf("%s.%s must not be negative, got %v",
+					section, value.Type().Field(i).Tag.Get("json"), weight)
+			}
+		}
+	}
+	if w.Dip.Threshold <= 0 {
+		return fmt.Errorf("dip.threshold must be positive, got %v", w.Dip.Threshold)
+	}
+	return nil
+}
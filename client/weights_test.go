@@ -0,0 +1,71 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeWeightsFile points SCORING_WEIGHTS_FILE at a file holding content
+func writeWeightsFile(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "weights.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write weights file: %v", err)
+	}
+	t.Setenv("SCORING_WEIGHTS_FILE", path)
+}
+
+func TestLoadScoringWeightsDefaults(t *testing.T) {
+	t.Setenv("SCORING_WEIGHTS_FILE", "")
+	weights, err := loadScoringWeights()
+	if err != nil {
+		t.Fatalf("loadScoringWeights: %v", err)
+	}
+	if weights != defaultScoringWeights() {
+		t.Errorf("weights without a file = %+v, want the defaults", weights)
+	}
+}
+
+func TestLoadScoringWeightsPartialOverride(t *testing.T) {
+	writeWeightsFile(t, `{"bearish": {"macd_strong": 3.5}, "dip": {"threshold": 8}}`)
+	weights, err := loadScoringWeights()
+	if err != nil {
+		t.Fatalf("loadScoringWeights: %v", err)
+	}
+
+	want := defaultScoringWeights()
+	want.Bearish.MACDStrong = 3.5
+	want.Dip.Threshold = 8
+	if weights != want {
+		t.Errorf("weights = %+v, want the defaults with bearish.macd_strong 3.5 and dip.threshold 8", weights)
+	}
+}
+
+func TestLoadScoringWeightsRejectsInvalidFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"unknown key", `{"bearish": {"macd_strnog": 3}}`, "unknown field"},
+		{"unknown section", `{"sideways": {}}`, "unknown field"},
+		{"negative weight", `{"bullish": {"rsi_strong": -1}}`, "bullish.rsi_strong must not be negative"},
+		{"zero dip threshold", `{"dip": {"threshold": 0}}`, "dip.threshold must be positive"},
+		{"malformed", `{"dip": `, "invalid SCORING_WEIGHTS_FILE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeWeightsFile(t, tt.content)
+			if _, err := loadScoringWeights(); err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("loadScoringWeights = %v, want an error containing %q", err, tt.message)
+			}
+		})
+	}
+
+	t.Setenv("SCORING_WEIGHTS_FILE", filepath.Join(t.TempDir(), "missing.json"))
+	if _, err := loadScoringWeights(); err == nil {
+		t.Error("missing SCORING_WEIGHTS_FILE did not fail")
+	}
+}
@@ -5,16 +5,26 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // TradingConfig holds trading configuration
 type TradingConfig struct {
-	BaseCurrency      string
-	QuoteCurrency     string
-	TradingPair       string
-	WebhookURL        string
-	WebhookMaxRetries int
-	WebhookTimeout    int
+	BaseCurrency       string
+	QuoteCurrency      string
+	TradingPair        string
+	TradingPairs       []string // All pairs tracked by this instance, the first is the default
+	WebhookURL         string
+	WebhookMaxRetries  int
+	WebhookTimeout     int
+	MarketDefaultLimit int      // Default order book depth for /market when no limit is given
+	ReadOnly           bool     // Disable every API endpoint that can trade (buy, sell, cancel, replace)
+	EnabledEndpoints   []string // Allow-list of API routes ("/signal" or "GET /orders"), empty allows all
+	PrefetchOnStartup  bool     // Warm the signal candle cache in the background at startup
+	// Health transition alerts
+	HealthWebhookURL      string        // Called when the service flips between healthy and unhealthy
+	HealthCheckInterval   time.Duration // How often the background prober runs the health check
+	HealthWebhookDebounce int           // Consecutive results needed before a new state counts
 }
 
 // LoadTradingConfig loads trading configuration from environment variables
@@ -42,6 +52,26 @@ func LoadTradingConfig() *TradingConfig {
 	}
 	config.TradingPair = strings.ToUpper(config.TradingPair)
 
+	// Load additional trading pairs (comma-separated, first is the default)
+	tradingPairs := os.Getenv("TRADING_PAIRS")
+	if tradingPairs != "" {
+		for _, pair := range strings.Split(tradingPairs, ",") {
+			pair = strings.ToUpper(strings.TrimSpace(pair))
+			if pair != "" {
+				config.TradingPairs = append(config.TradingPairs, pair)
+			}
+		}
+	}
+	if len(config.TradingPairs) == 0 {
+		config.TradingPairs = []string{config.TradingPair}
+	} else {
+		config.TradingPair = config.TradingPairs[0]
+		if parts := strings.Split(config.TradingPair, "-"); len(parts) == 2 {
+			config.BaseCurrency = parts[0]
+			config.QuoteCurrency = parts[1]
+		}
+	}
+
 	// Load webhook URL for n8n notifications
 	config.WebhookURL = os.Getenv("WEBHOOK_URL")
 
@@ -69,14 +99,100 @@ func LoadTradingConfig() *TradingConfig {
 		}
 	}
 
+	// Load default order book depth for /market
+	config.MarketDefaultLimit = 10
+	if marketLimit := os.Getenv("MARKET_DEFAULT_LIMIT"); marketLimit != "" {
+		if limit, err := strconv.Atoi(marketLimit); err == nil && limit >= 1 && limit <= 100 {
+			config.MarketDefaultLimit = limit
+		}
+	}
+
+	// Load endpoint restrictions for read-only deployments
+	config.ReadOnly = strings.ToLower(os.Getenv("READ_ONLY")) == "true"
+	if enabledEndpoints := os.Getenv("ENABLED_ENDPOINTS"); enabledEndpoints != "" {
+		for _, endpoint := range strings.Split(enabledEndpoints, ",") {
+			if endpoint = normalizeEndpoint(endpoint); endpoint != "" {
+				config.EnabledEndpoints = append(config.EnabledEndpoints, endpoint)
+			}
+		}
+	}
+
+	// Load startup warm-up
+	config.PrefetchOnStartup = strings.ToLower(os.Getenv("PREFETCH_ON_STARTUP")) == "true"
+
+	// Load health transition alerts (probe every minute, two results in a row to flip)
+	config.HealthWebhookURL = os.Getenv("HEALTH_WEBHOOK_URL")
+	config.HealthCheckInterval = time.Minute
+	if interval, err := time.ParseDuration(os.Getenv("HEALTH_CHECK_INTERVAL")); err == nil && interval >= 5*time.Second {
+		config.HealthCheckInterval = interval
+	}
+	config.HealthWebhookDebounce = 2
+	if debounce, err := strconv.Atoi(os.Getenv("HEALTH_WEBHOOK_DEBOUNCE")); err == nil && debounce >= 1 {
+		config.HealthWebhookDebounce = debounce
+	}
+
 	return config
 }
 
+// normalizeEndpoint upper-cases an optional method and makes sure the path starts with a slash
+func normalizeEndpoint(endpoint string) string {
+	fields := strings.Fields(endpoint)
+	switch len(fields) {
+	case 1:
+		return "/" + strings.TrimPrefix(fields[0], "/")
+	case 2:
+		return strings.ToUpper(fields[0]) + " /" + strings.TrimPrefix(fields[1], "/")
+	default:
+		return ""
+	}
+}
+
+// readOnlyRoutes are the non-GET routes READ_ONLY keeps enabled because they never change any state
+var readOnlyRoutes = map[string]bool{
+	"POST /signal/simulate": true,
+}
+
+// dryRunRoutes are the non-GET routes READ_ONLY keeps enabled for dry runs only; their handlers reject the
+// request unless it asks for "dry_run": true (see DryRunOnly)
+var dryRunRoutes = map[string]bool{
+	"POST /rebalance":      true,
+	"POST /close-position": true,
+}
+
+// IsEndpointEnabled reports whether an API route (path relative to /api/v1, e.g. "/orders/:order_id") may be served.
+// READ_ONLY blocks every non-GET route but the read-only and dry-run ones; ENABLED_ENDPOINTS, when set, must
+// list the path or "METHOD path".
+func (config *TradingConfig) IsEndpointEnabled(method, path string) bool {
+	route := method + " " + path
+	if config.ReadOnly && method != "GET" && !readOnlyRoutes[route] && !dryRunRoutes[route] {
+		return false
+	}
+	if len(config.EnabledEndpoints) == 0 {
+		return true
+	}
+	for _, endpoint := range config.EnabledEndpoints {
+		if endpoint == path || endpoint == route {
+			return true
+		}
+	}
+	return false
+}
+
+// DryRunOnly reports whether READ_ONLY limits an enabled route to dry runs
+func (config *TradingConfig) DryRunOnly(method, path string) bool {
+	return config.ReadOnly && dryRunRoutes[method+" "+path]
+}
+
 // GetTradingPair returns the configured trading pair
 func (config *TradingConfig) GetTradingPair() string {
 	return config.TradingPair
 }
 
+// GetTradingPairs returns all configured trading pairs
+func (config *TradingConfig) GetTradingPairs() []string {
+	return config.TradingPairs
+}
+
 // GetBaseCurrency returns the base currency
 func (config *TradingConfig) GetBaseCurrency() string {
 	return config.BaseCurrency
@@ -101,5 +217,31 @@ func (config *TradingConfig) Validate() error {
 	if config.BaseCurrency == config.QuoteCurrency {
 		return fmt.Errorf("base and quote currencies cannot be the same")
 	}
+	for _, pair := range config.TradingPairs {
+		if err := validateTradingPair(pair); err != nil {
+			return err
+		}
+	}
+	if err := validateTradingPair(config.TradingPair); err != nil {
+		return err
+	}
+	if config.WebhookMaxRetries < 0 || config.WebhookMaxRetries > 10 {
+		return fmt.Errorf("webhook max retries must be between 0 and 10, got %d", config.WebhookMaxRetries)
+	}
+	if config.WebhookTimeout < 1 || config.WebhookTimeout > 30 {
+		return fmt.Errorf("webhook timeout must be between 1 and 30 seconds, got %d", config.WebhookTimeout)
+	}
+	return nil
+}
+
+// validateTradingPair checks a pair has the BASE-QUOTE format (exactly one dash, both parts non-empty)
+func validateTradingPair(pair string) error {
+	parts := strings.Split(pair, "-")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("invalid trading pair %q: expected BASE-QUOTE format (e.g. BTC-USDC)", pair)
+	}
+	if parts[0] == parts[1] {
+		return fmt.Errorf("invalid trading pair %q: base and quote currencies cannot be the same", pair)
+	}
 	return nil
 }
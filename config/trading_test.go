@@ -0,0 +1,100 @@
+package config
+
+import "testing"
+
+func TestIsEndpointEnabled(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     TradingConfig
+		method     string
+		path       string
+		enabled    bool
+		dryRunOnly bool
+	}{
+		{"no restrictions", TradingConfig{}, "POST", "/buy", true, false},
+		{"read-only GET", TradingConfig{ReadOnly: true}, "GET", "/orders", true, false},
+		{"read-only buy", TradingConfig{ReadOnly: true}, "POST", "/buy", false, false},
+		{"read-only cancel", TradingConfig{ReadOnly: true}, "DELETE", "/orders", false, false},
+		{"read-only replace", TradingConfig{ReadOnly: true}, "PUT", "/orders/:order_id", false, false},
+		{"read-only simulate", TradingConfig{ReadOnly: true}, "POST", "/signal/simulate", true, false},
+		{"read-only rebalance", TradingConfig{ReadOnly: true}, "POST", "/rebalance", true, true},
+		{"read-only close position", TradingConfig{ReadOnly: true}, "POST", "/close-position", true, true},
+		{"rebalance without read-only", TradingConfig{}, "POST", "/rebalance", true, false},
+		{"allow-listed path", TradingConfig{EnabledEndpoints: []string{"/signal"}}, "GET", "/signal", true, false},
+		{"allow-listed method", TradingConfig{EnabledEndpoints: []string{"GET /orders"}}, "DELETE", "/orders", false, false},
+		{"not allow-listed", TradingConfig{EnabledEndpoints: []string{"/signal"}}, "GET", "/market", false, false},
+		{"read-only wins over allow-list", TradingConfig{ReadOnly: true, EnabledEndpoints: []string{"/buy"}}, "POST", "/buy", false, false},
+		{"allow-list applies to read-only routes", TradingConfig{ReadOnly: true, EnabledEndpoints: []string{"/signal"}}, "POST", "/signal/simulate", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if enabled := tt.config.IsEndpointEnabled(tt.method, tt.path); enabled != tt.enabled {
+				t.Errorf("IsEndpointEnabled(%s %s) = %v, want %v", tt.method, tt.path, enabled, tt.enabled)
+			}
+			if dryRunOnly := tt.config.DryRunOnly(tt.method, tt.path); dryRunOnly != tt.dryRunOnly {
+				t.Errorf("DryRunOnly(%s %s) = %v, want %v", tt.method, tt.path, dryRunOnly, tt.dryRunOnly)
+			}
+		})
+	}
+}
+
+func TestNormalizeEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"signal":     "/signal",
+		" /signal ":  "/signal",
+		"get orders": "GET /orders",
+		"POST /buy":  "POST /buy",
+		"":           "",
+		"GET /a /b":  "",
+	}
+	for endpoint, want := range tests {
+		if got := normalizeEndpoint(endpoint); got != want {
+			t.Errorf("normalizeEndpoint(%q) = %q, want %q", endpoint, got, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() TradingConfig {
+		return TradingConfig{
+			BaseCurrency:      "BTC",
+			QuoteCurrency:     "USDC",
+			TradingPair:       "BTC-USDC",
+			TradingPairs:      []string{"BTC-USDC", "ETH-EUR"},
+			WebhookMaxRetries: 3,
+			WebhookTimeout:    10,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(config *TradingConfig)
+		valid  bool
+	}{
+		{"valid", func(config *TradingConfig) {}, true},
+		{"no retries", func(config *TradingConfig) { config.WebhookMaxRetries = 0 }, true},
+		{"most retries", func(config *TradingConfig) { config.WebhookMaxRetries = 10 }, true},
+		{"negative retries", func(config *TradingConfig) { config.WebhookMaxRetries = -1 }, false},
+		{"too many retries", func(config *TradingConfig) { config.WebhookMaxRetries = 11 }, false},
+		{"shortest timeout", func(config *TradingConfig) { config.WebhookTimeout = 1 }, true},
+		{"longest timeout", func(config *TradingConfig) { config.WebhookTimeout = 30 }, true},
+		{"zero timeout", func(config *TradingConfig) { config.WebhookTimeout = 0 }, false},
+		{"timeout too long", func(config *TradingConfig) { config.WebhookTimeout = 31 }, false},
+		{"no dash", func(config *TradingConfig) { config.TradingPair = "BTC" }, false},
+		{"no quote", func(config *TradingConfig) { config.TradingPair = "BTC-" }, false},
+		{"no base", func(config *TradingConfig) { config.TradingPair = "-USDC" }, false},
+		{"two dashes", func(config *TradingConfig) { config.TradingPair = "A-B-C" }, false},
+		{"same currencies", func(config *TradingConfig) { config.TradingPair = "BTC-BTC" }, false},
+		{"bad extra pair", func(config *TradingConfig) { config.TradingPairs = []string{"BTC-USDC", "ETH"} }, false},
+		{"base equals quote", func(config *TradingConfig) { config.QuoteCurrency = "BTC" }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid()
+			tt.modify(&config)
+			if err := config.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate() = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
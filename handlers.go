@@ -1,31 +1,78 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"coinbase-base/client"
+	"coinbase-base/config"
+	"coinbase-base/middleware"
 
 	"os"
 
 	"github.com/gin-gonic/gin"
 )
 
+// validGranularities lists the candle granularities accepted by Coinbase
+var validGranularities = map[string]bool{
+	"UNKNOWN_GRANULARITY": true,
+	"ONE_MINUTE":          true,
+	"FIVE_MINUTE":         true,
+	"FIFTEEN_MINUTE":      true,
+	"THIRTY_MINUTE":       true,
+	"ONE_HOUR":            true,
+	"TWO_HOUR":            true,
+	"SIX_HOUR":            true,
+	"ONE_DAY":             true,
+}
+
 type Handlers struct {
-	client *client.CoinbaseClient
+	manager        *client.ClientManager
+	tradingConfig  *config.TradingConfig
+	securityConfig *middleware.SecurityConfig
 }
 
-func NewHandlers(client *client.CoinbaseClient) *Handlers {
+func NewHandlers(manager *client.ClientManager, tradingConfig *config.TradingConfig, securityConfig *middleware.SecurityConfig) *Handlers {
 	return &Handlers{
-		client: client,
+		manager:        manager,
+		tradingConfig:  tradingConfig,
+		securityConfig: securityConfig,
+	}
+}
+
+// clientFor resolves the client for the optional "pair" query parameter (defaults to the first configured pair)
+func (h *Handlers) clientFor(c *gin.Context) (*client.CoinbaseClient, bool) {
+	coinbaseClient, err := h.manager.Get(strings.TrimSpace(c.Query("pair")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid pair",
+			"message": err.Error(),
+		})
+		return nil, false
 	}
+	return coinbaseClient, true
 }
 
 // GetAccounts returns all accounts
 func (h *Handlers) GetAccounts(c *gin.Context) {
-	accounts, err := h.client.GetAccounts()
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
+	accounts, err := coinbaseClient.GetAccounts()
+	if errors.Is(err, client.ErrUpstreamNonJSON) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Coinbase is temporarily unavailable",
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to fetch accounts",
@@ -42,17 +89,25 @@ func (h *Handlers) GetAccounts(c *gin.Context) {
 
 // BuyBTC places a buy order for BTC with USDC, optionally with stop loss protection
 func (h *Handlers) BuyBTC(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+	if rejectShuttingDown(c, coinbaseClient.AcceptingOrders()) {
+		return
+	}
+
 	var req client.TradingRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request body",
-			"message": err.Error(),
-		})
+	if !bindJSON(c, &req) {
+		return
+	}
+	clientOrderID, ok := idempotencyKey(c)
+	if !ok {
 		return
 	}
 
 	// Validate price first (required for all orders)
-	if req.Price <= 0 {
+	if !req.Price.IsPositive() {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Missing price",
 			"message": "Price is required for market orders",
@@ -62,7 +117,7 @@ func (h *Handlers) BuyBTC(c *gin.Context) {
 
 	// Handle percentage-based order size calculation
 	if req.Percentage > 0 {
-		calculatedSize, err := h.client.CalculateOrderSizeByPercentage("BUY", req.Percentage, fmt.Sprintf("%.8f", req.Price))
+		calculatedSize, err := coinbaseClient.CalculateOrderSizeByPercentage("BUY", req.Percentage, req.Price.StringFixed(8))
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error":   "Failed to calculate order size by percentage",
@@ -83,7 +138,37 @@ func (h *Handlers) BuyBTC(c *gin.Context) {
 		return
 	}
 
-	order, err := h.client.BuyBTC(req.Size, req.Price)
+	// Idempotency-Key is passed through as the Coinbase client_order_id so retries are safe
+	order, err := coinbaseClient.BuyBTC(req.Size, req.Price.InexactFloat64(), client.OrderOptions{
+		PostOnly:      req.PostOnly,
+		ClientOrderID: clientOrderID,
+	})
+	if rejectShuttingDown(c, err) {
+		return
+	}
+	if errors.Is(err, client.ErrOrderNotionalExceeded) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Order exceeds maximum notional",
+			"message": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, client.ErrSpreadTooWide) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Spread too wide to trade",
+			"code":    "SPREAD_TOO_WIDE",
+			"message": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, client.ErrPostOnlyWouldCross) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Post-only order would cross the book",
+			"code":    "POST_ONLY_WOULD_CROSS",
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to place buy order",
@@ -93,8 +178,12 @@ func (h *Handlers) BuyBTC(c *gin.Context) {
 	}
 
 	response := gin.H{
-		"message": "Buy order placed successfully",
-		"order":   order,
+		"message":        "Buy order placed successfully",
+		"order":          order,
+		"requested_size": req.Size,
+		"filled_size":    order.FilledSize,
+		"filled_value":   order.FilledValue,
+		"partial_fill":   coinbaseClient.IsPartialFill(order),
 	}
 
 	c.JSON(http.StatusCreated, response)
@@ -102,17 +191,25 @@ func (h *Handlers) BuyBTC(c *gin.Context) {
 
 // SellBTC places a sell order for BTC to USDC
 func (h *Handlers) SellBTC(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+	if rejectShuttingDown(c, coinbaseClient.AcceptingOrders()) {
+		return
+	}
+
 	var req client.TradingRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request body",
-			"message": err.Error(),
-		})
+	if !bindJSON(c, &req) {
+		return
+	}
+	clientOrderID, ok := idempotencyKey(c)
+	if !ok {
 		return
 	}
 
 	// Validate price first (required for all orders)
-	if req.Price <= 0 {
+	if !req.Price.IsPositive() {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Missing price",
 			"message": "Price is required for market orders",
@@ -123,7 +220,7 @@ func (h *Handlers) SellBTC(c *gin.Context) {
 	// Handle percentage-based order size calculation
 	if req.Percentage > 0 {
 		// For SELL orders, we need the price to calculate fees correctly
-		calculatedSize, err := h.client.CalculateOrderSizeByPercentage("SELL", req.Percentage, fmt.Sprintf("%.8f", req.Price))
+		calculatedSize, err := coinbaseClient.CalculateOrderSizeByPercentage("SELL", req.Percentage, req.Price.StringFixed(8))
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error":   "Failed to calculate order size by percentage",
@@ -144,7 +241,46 @@ func (h *Handlers) SellBTC(c *gin.Context) {
 		return
 	}
 
-	order, err := h.client.SellBTC(req.Size, req.Price)
+	// Idempotency-Key is passed through as the Coinbase client_order_id so retries are safe
+	order, err := coinbaseClient.SellBTC(req.Size, req.Price.InexactFloat64(), client.OrderOptions{
+		PostOnly:      req.PostOnly,
+		ReduceOnly:    req.ReduceOnly,
+		ClientOrderID: clientOrderID,
+	})
+	if rejectShuttingDown(c, err) {
+		return
+	}
+	if errors.Is(err, client.ErrReduceOnlyExceedsBalance) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Reduce-only sell exceeds available balance",
+			"code":    "REDUCE_ONLY_EXCEEDS_BALANCE",
+			"message": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, client.ErrOrderNotionalExceeded) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Order exceeds maximum notional",
+			"message": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, client.ErrSpreadTooWide) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Spread too wide to trade",
+			"code":    "SPREAD_TOO_WIDE",
+			"message": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, client.ErrPostOnlyWouldCross) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Post-only order would cross the book",
+			"code":    "POST_ONLY_WOULD_CROSS",
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to place sell order",
@@ -154,16 +290,71 @@ func (h *Handlers) SellBTC(c *gin.Context) {
 	}
 
 	response := gin.H{
-		"message": "Sell order placed successfully",
-		"order":   order,
+		"message":        "Sell order placed successfully",
+		"order":          order,
+		"requested_size": req.Size,
+		"filled_size":    order.FilledSize,
+		"filled_value":   order.FilledValue,
+		"partial_fill":   coinbaseClient.IsPartialFill(order),
 	}
 
 	c.JSON(http.StatusCreated, response)
 }
 
-// GetOrders returns all orders (including stop limit orders)
+// GetOrders returns orders (including stop limit orders), filtered by status (default OPEN), side,
+// product (the trading pair unless product=all) and limit
 func (h *Handlers) GetOrders(c *gin.Context) {
-	orders, err := h.client.GetOrders()
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
+	status := strings.ToUpper(c.DefaultQuery("status", "OPEN"))
+	switch status {
+	case "OPEN", "FILLED", "CANCELLED", "ALL":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid status",
+			"message": "status must be one of: OPEN, FILLED, CANCELLED, ALL",
+		})
+		return
+	}
+
+	side := strings.ToUpper(c.Query("side"))
+	if side != "" && side != "BUY" && side != "SELL" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid side",
+			"message": "side must be BUY or SELL",
+		})
+		return
+	}
+
+	opts := client.OrderListOptions{Status: status, Side: side}
+
+	if product := c.Query("product"); product != "" {
+		if strings.ToLower(product) != "all" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid product",
+				"message": "product must be 'all' (omit it to list the configured trading pair)",
+			})
+			return
+		}
+		opts.AllProducts = true
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid limit",
+				"message": "limit must be between 1 and 1000",
+			})
+			return
+		}
+		opts.Limit = limit
+	}
+
+	orders, err := coinbaseClient.ListOrders(opts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to fetch orders",
@@ -180,6 +371,11 @@ func (h *Handlers) GetOrders(c *gin.Context) {
 
 // CancelOrder cancels a specific order (including stop limit orders)
 func (h *Handlers) CancelOrder(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
 	orderID := c.Param("order_id")
 	if orderID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -189,7 +385,14 @@ func (h *Handlers) CancelOrder(c *gin.Context) {
 		return
 	}
 
-	err := h.client.CancelOrder(orderID)
+	err := coinbaseClient.CancelOrder(orderID)
+	if errors.Is(err, client.ErrCancelRejected) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Order not cancelled",
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to cancel order",
@@ -204,56 +407,251 @@ func (h *Handlers) CancelOrder(c *gin.Context) {
 	})
 }
 
-// CancelAllOrders cancels all open orders
-func (h *Handlers) CancelAllOrders(c *gin.Context) {
-	// Get all orders first
-	orders, err := h.client.GetOrders()
+// ReplaceOrder replaces an open order with a new size and/or price
+func (h *Handlers) ReplaceOrder(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+	if rejectShuttingDown(c, coinbaseClient.AcceptingOrders()) {
+		return
+	}
+
+	orderID := c.Param("order_id")
+	if orderID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Missing order ID",
+			"message": "Order ID is required",
+		})
+		return
+	}
+
+	var req client.ReplaceOrderRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if !req.Price.IsPositive() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Missing price",
+			"message": "Price is required to replace an order",
+		})
+		return
+	}
+
+	if req.Size != "" {
+		if _, err := strconv.ParseFloat(req.Size, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid size format",
+				"message": "Size must be a valid number",
+			})
+			return
+		}
+	}
+
+	result, err := coinbaseClient.ReplaceOrder(orderID, req.Size, req.Price.InexactFloat64())
+	if rejectShuttingDown(c, err) {
+		return
+	}
+	var partialFill *client.PartialFillError
+	if errors.As(err, &partialFill) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":       "Order partially filled",
+			"message":     err.Error(),
+			"filled_size": partialFill.FilledSize,
+		})
+		return
+	}
+	if errors.Is(err, client.ErrOrderAlreadyFilled) || errors.Is(err, client.ErrOrderNotOpen) || errors.Is(err, client.ErrCancelRejected) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Order cannot be replaced",
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to fetch orders",
+			"error":   "Failed to replace order",
 			"message": err.Error(),
 		})
 		return
 	}
 
-	// Filter for open orders
-	var openOrders []client.Order
-	for _, order := range orders {
-		if order.Status == "OPEN" || order.Status == "PENDING" {
-			openOrders = append(openOrders, order)
-		}
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Order replaced successfully",
+		"old_order_id": result.OldOrderID,
+		"new_order_id": result.NewOrderID,
+		"order":        result.Order,
+	})
+}
+
+// Rebalance moves the base currency allocation toward target_base_pct with a single IOC order
+func (h *Handlers) Rebalance(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+	if rejectShuttingDown(c, coinbaseClient.AcceptingOrders()) {
+		return
 	}
 
-	if len(openOrders) == 0 {
-		c.JSON(http.StatusOK, gin.H{
-			"message":         "No open orders to cancel",
-			"cancelled_count": 0,
+	var req client.RebalanceRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.TargetBasePct == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Missing target_base_pct",
+			"message": "target_base_pct is required (0 to sell all base, 100 to buy with all quote)",
+		})
+		return
+	}
+	if *req.TargetBasePct < 0 || *req.TargetBasePct > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid target_base_pct",
+			"message": "target_base_pct must be between 0 and 100",
 		})
 		return
 	}
 
-	// Cancel each open order
-	var cancelledOrders []string
-	var failedOrders []string
+	if h.rejectReadOnly(c, req.DryRun) {
+		return
+	}
 
-	for _, order := range openOrders {
-		err := h.client.CancelOrder(order.ID)
-		if err != nil {
-			failedOrders = append(failedOrders, order.ID)
-		} else {
-			cancelledOrders = append(cancelledOrders, order.ID)
+	result, err := coinbaseClient.Rebalance(*req.TargetBasePct, req.TolerancePct, req.DryRun)
+	if rejectShuttingDown(c, err) {
+		return
+	}
+	if errors.Is(err, client.ErrSpreadTooWide) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Spread too wide to trade",
+			"code":    "SPREAD_TOO_WIDE",
+			"message": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, client.ErrOrderNotionalExceeded) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Order exceeds maximum notional",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to rebalance",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	status := http.StatusOK
+	if result.Executed != nil {
+		status = http.StatusCreated
+	}
+	c.JSON(status, result)
+}
+
+// ClosePosition cancels all open orders and sells the whole base balance with an IOC order
+func (h *Handlers) ClosePosition(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+	if rejectShuttingDown(c, coinbaseClient.AcceptingOrders()) {
+		return
+	}
+
+	// The body is optional: defaults are a 0.5% slippage and a real (non dry-run) close
+	var req client.ClosePositionRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+	if req.SlippagePct < 0 || req.SlippagePct >= 100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid slippage_pct",
+			"message": "slippage_pct must be between 0 and 100",
+		})
+		return
+	}
+
+	if h.rejectReadOnly(c, req.DryRun) {
+		return
+	}
+
+	result, err := coinbaseClient.ClosePosition(req.SlippagePct, req.DryRun)
+	if rejectShuttingDown(c, err) {
+		return
+	}
+	if err != nil {
+		// Orders may already have been cancelled when the sell fails
+		cancelled := 0
+		if result != nil {
+			cancelled = result.CancelledCount
 		}
+		status, response := http.StatusInternalServerError, gin.H{
+			"error":           "Failed to close position",
+			"message":         err.Error(),
+			"cancelled_count": cancelled,
+		}
+		if errors.Is(err, client.ErrSpreadTooWide) {
+			status = http.StatusConflict
+			response["error"] = "Spread too wide to trade"
+			response["code"] = "SPREAD_TOO_WIDE"
+		} else if errors.Is(err, client.ErrOrderNotionalExceeded) {
+			status = http.StatusBadRequest
+			response["error"] = "Order exceeds maximum notional"
+		}
+		c.JSON(status, response)
+		return
+	}
+
+	status := http.StatusOK
+	if result.SellOrder != nil {
+		status = http.StatusCreated
+	}
+	c.JSON(status, result)
+}
+
+// CancelAllOrders cancels all open orders
+func (h *Handlers) CancelAllOrders(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
+	result, err := coinbaseClient.CancelAllOrders()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to cancel orders",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if len(result.Cancelled) == 0 && len(result.Failed) == 0 && len(result.Rejected) == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"message":         "No open orders to cancel",
+			"cancelled_count": 0,
+		})
+		return
 	}
 
 	response := gin.H{
 		"message":          "Cancel all orders completed",
-		"cancelled_count":  len(cancelledOrders),
-		"failed_count":     len(failedOrders),
-		"cancelled_orders": cancelledOrders,
+		"cancelled_count":  len(result.Cancelled),
+		"failed_count":     len(result.Failed),
+		"rejected_count":   len(result.Rejected),
+		"cancelled_orders": result.Cancelled,
 	}
 
-	if len(failedOrders) > 0 {
-		response["failed_orders"] = failedOrders
+	if len(result.Failed) > 0 || len(result.Rejected) > 0 {
+		if len(result.Failed) > 0 {
+			response["failed_orders"] = result.Failed
+		}
+		if len(result.Rejected) > 0 {
+			response["rejected_orders"] = result.Rejected
+		}
 		c.JSON(http.StatusPartialContent, response)
 	} else {
 		c.JSON(http.StatusOK, response)
@@ -262,16 +660,32 @@ func (h *Handlers) CancelAllOrders(c *gin.Context) {
 
 // GetCandles retrieves candle data for the configured trading pair
 func (h *Handlers) GetCandles(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
 	// Get query parameters
 	start := c.Query("start")
 	end := c.Query("end")
 	granularity := c.Query("granularity")
 	limitStr := c.Query("limit")
 	period := c.Query("period")
+	format := c.DefaultQuery("format", "raw")
+
+	if format != "raw" && format != "ohlc" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid format",
+			"message": "Format must be one of: raw, ohlc",
+		})
+		return
+	}
 
-	// Handle preset periods
+	// Handle preset periods; a requested granularity is kept unless the period would exceed 350 candles
+	requestedGranularity := granularity
+	granularityDowngraded := false
 	if period != "" {
-		start, end, granularity = h.getPresetPeriod(period)
+		start, end, granularity, granularityDowngraded = h.getPresetPeriod(period, requestedGranularity)
 		if start == "" {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error":   "Invalid period",
@@ -324,18 +738,6 @@ func (h *Handlers) GetCandles(c *gin.Context) {
 	}
 
 	// Validate granularity
-	validGranularities := map[string]bool{
-		"UNKNOWN_GRANULARITY": true,
-		"ONE_MINUTE":          true,
-		"FIVE_MINUTE":         true,
-		"FIFTEEN_MINUTE":      true,
-		"THIRTY_MINUTE":       true,
-		"ONE_HOUR":            true,
-		"TWO_HOUR":            true,
-		"SIX_HOUR":            true,
-		"ONE_DAY":             true,
-	}
-
 	if !validGranularities[granularity] {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid granularity",
@@ -344,7 +746,7 @@ func (h *Handlers) GetCandles(c *gin.Context) {
 		return
 	}
 
-	candles, err := h.client.GetCandles(start, end, granularity, limit)
+	candles, err := coinbaseClient.GetCandles(start, end, granularity, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to fetch candles",
@@ -354,15 +756,33 @@ func (h *Handlers) GetCandles(c *gin.Context) {
 	}
 
 	response := gin.H{
-		"product_id":  h.client.GetTradingPair(),
+		"product_id":  coinbaseClient.GetTradingPair(),
 		"start":       start,
 		"end":         end,
 		"granularity": granularity,
 		"candles":     candles,
 	}
 
+	// OHLC format: numeric {time, open, high, low, close, volume} objects for charting libraries
+	if format == "ohlc" {
+		ohlc, err := client.ToOHLC(candles)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to convert candles",
+				"message": err.Error(),
+			})
+			return
+		}
+		response["candles"] = ohlc
+		response["format"] = format
+	}
+
 	if period != "" {
 		response["period"] = period
+		response["granularity_downgraded"] = granularityDowngraded
+		if requestedGranularity != "" {
+			response["requested_granularity"] = requestedGranularity
+		}
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -370,8 +790,13 @@ func (h *Handlers) GetCandles(c *gin.Context) {
 
 // GetMarketState retrieves current market state with bid/ask and order book
 func (h *Handlers) GetMarketState(c *gin.Context) {
-	// Get limit parameter (default to 10)
-	limitStr := c.DefaultQuery("limit", "10")
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
+	// Get limit parameter (default from MARKET_DEFAULT_LIMIT, 10 if unset)
+	limitStr := c.DefaultQuery("limit", strconv.Itoa(h.tradingConfig.MarketDefaultLimit))
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit < 1 || limit > 100 {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -381,7 +806,17 @@ func (h *Handlers) GetMarketState(c *gin.Context) {
 		return
 	}
 
-	marketState, err := h.client.GetMarketState(limit)
+	// fresh=true skips the short-lived market cache for this request
+	fresh, ok := freshParam(c)
+	if !ok {
+		return
+	}
+
+	getMarketState := coinbaseClient.GetMarketState
+	if fresh {
+		getMarketState = coinbaseClient.GetFreshMarketState
+	}
+	marketState, err := getMarketState(limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to fetch market state",
@@ -391,55 +826,256 @@ func (h *Handlers) GetMarketState(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"product_id":     marketState.ProductID,
-		"best_bid":       marketState.BestBid,
-		"best_ask":       marketState.BestAsk,
-		"spread":         marketState.Spread,
-		"spread_percent": marketState.SpreadPercent,
-		"last_price":     marketState.LastPrice,
-		"volume_24h":     marketState.Volume24h,
-		"order_book":     marketState.OrderBook,
-		"timestamp":      marketState.Timestamp,
-		"limit":          limit,
+		"product_id":      marketState.ProductID,
+		"best_bid":        marketState.BestBid,
+		"best_ask":        marketState.BestAsk,
+		"spread":          marketState.Spread,
+		"spread_percent":  marketState.SpreadPercent,
+		"last_price":      marketState.LastPrice,
+		"mid_market":      marketState.MidMarket,
+		"last":            marketState.Last,
+		"spread_bps":      marketState.SpreadBps,
+		"spread_absolute": marketState.SpreadAbsolute,
+		"volume_24h":      marketState.Volume24h,
+		"order_book":      marketState.OrderBook,
+		"timestamp":       marketState.Timestamp,
+		"limit":           limit,
+	})
+}
+
+// GetDepthChart returns a PNG of the cumulative order book depth
+func (h *Handlers) GetDepthChart(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
+	// Get limit parameter (more levels than /market by default, for a useful picture)
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid limit parameter",
+			"message": "Limit must be between 1 and 100 (number of bid/ask entries)",
+		})
+		return
+	}
+
+	orderBook, err := coinbaseClient.GetOrderBook(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch order book",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	pngData, err := coinbaseClient.GenerateDepthChartPNG(orderBook)
+	if errors.Is(err, client.ErrEmptyOrderBook) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Order book is empty",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to generate depth chart",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store") // The order book changes constantly
+	c.Data(http.StatusOK, "image/png", pngData)
+}
+
+// GetProductStats returns price and 24h statistics for the trading pair
+func (h *Handlers) GetProductStats(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
+	// fresh=true skips the short-lived market cache for this request
+	fresh, ok := freshParam(c)
+	if !ok {
+		return
+	}
+
+	getProductStats := coinbaseClient.GetProductStats
+	if fresh {
+		getProductStats = coinbaseClient.GetFreshProductStats
+	}
+	stats, err := getProductStats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch product stats",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"product": stats,
+	})
+}
+
+// GetSpreadHistory returns the rolling bid/ask spread series with min/max/avg statistics
+func (h *Handlers) GetSpreadHistory(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"spread_history": coinbaseClient.GetSpreadHistory(),
+		"timestamp":      time.Now().Format(time.RFC3339),
+	})
+}
+
+// EstimateFill estimates the average and worst fill price of a market-style order of the given size from the order book
+func (h *Handlers) EstimateFill(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
+	side := strings.ToUpper(c.Query("side"))
+	if side != "BUY" && side != "SELL" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid side",
+			"message": "Side must be BUY or SELL",
+		})
+		return
+	}
+
+	size, err := strconv.ParseFloat(c.Query("size"), 64)
+	if err != nil || size <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid size",
+			"message": "Size must be a positive number (base currency)",
+		})
+		return
+	}
+
+	avgPrice, worstPrice, filled, err := coinbaseClient.EstimateFillPrice(side, size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to estimate fill price",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"product_id":   coinbaseClient.GetTradingPair(),
+		"side":         side,
+		"size":         size,
+		"avg_price":    avgPrice,
+		"worst_price":  worstPrice,
+		"filled_size":  filled,
+		"fully_filled": filled >= size,
+		"timestamp":    time.Now().Format(time.RFC3339),
 	})
 }
 
-// getPresetPeriod returns start, end, and granularity for preset periods
-// Ensures we stay within the 350 candle limit
-func (h *Handlers) getPresetPeriod(period string) (string, string, string) {
+// GetSummary returns a compact text and JSON status (price, trend, indicators, portfolio value, last signal)
+func (h *Handlers) GetSummary(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
+	summary, err := coinbaseClient.GetSummary()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to build summary",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Plain text is convenient for forwarding straight to a chat message
+	if c.Query("format") == "text" {
+		c.String(http.StatusOK, summary.Text)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// getPresetPeriod resolves a preset period to a start/end range and granularity. Each preset has a default
+// granularity; a requested one is used instead when valid, coarsened (downgraded=true) if it would exceed 350 candles.
+func (h *Handlers) getPresetPeriod(period, requestedGranularity string) (start, end, granularity string, downgraded bool) {
 	now := time.Now()
 
+	var startTime time.Time
 	switch period {
 	case "last_hour":
 		// 60 minutes = 60 candles (within limit)
-		start := now.Add(-1 * time.Hour).Unix()
-		return fmt.Sprintf("%d", start), fmt.Sprintf("%d", now.Unix()), "ONE_MINUTE"
+		startTime, granularity = now.Add(-1*time.Hour), "ONE_MINUTE"
 	case "last_day":
 		// 24 hours * 4 (15-min intervals) = 96 candles (within limit)
-		start := now.AddDate(0, 0, -1).Unix()
-		return fmt.Sprintf("%d", start), fmt.Sprintf("%d", now.Unix()), "FIFTEEN_MINUTE"
+		startTime, granularity = now.AddDate(0, 0, -1), "FIFTEEN_MINUTE"
 	case "last_week":
 		// 7 days * 4 (6-hour intervals) = 28 candles (within limit)
-		start := now.AddDate(0, 0, -7).Unix()
-		return fmt.Sprintf("%d", start), fmt.Sprintf("%d", now.Unix()), "SIX_HOUR"
+		startTime, granularity = now.AddDate(0, 0, -7), "SIX_HOUR"
 	case "last_month":
 		// 30 days * 24 (hourly intervals) = 720 candles (exceeds limit)
 		// Use 6-hour intervals: 30 days * 4 = 120 candles (well within limit)
-		start := now.AddDate(0, -1, 0).Unix()
-		return fmt.Sprintf("%d", start), fmt.Sprintf("%d", now.Unix()), "SIX_HOUR"
+		startTime, granularity = now.AddDate(0, -1, 0), "SIX_HOUR"
 	case "last_year":
 		// 365 days (daily intervals) = 365 candles (exceeds limit)
 		// Limit to 350 days to stay within API limit
-		start := now.AddDate(0, 0, -350).Unix()
-		return fmt.Sprintf("%d", start), fmt.Sprintf("%d", now.Unix()), "ONE_DAY"
+		startTime, granularity = now.AddDate(0, 0, -350), "ONE_DAY"
 	default:
-		return "", "", ""
+		return "", "", "", false
 	}
+
+	if requestedGranularity != "" && validGranularities[requestedGranularity] && requestedGranularity != "UNKNOWN_GRANULARITY" {
+		granularity = client.FitGranularity(now.Sub(startTime), requestedGranularity)
+		downgraded = granularity != requestedGranularity
+	}
+
+	return fmt.Sprintf("%d", startTime.Unix()), fmt.Sprintf("%d", now.Unix()), granularity, downgraded
+}
+
+// GetTradeStats returns FIFO round-trip trade statistics (win rate, realized P&L, fees, holding time) for a period
+func (h *Handlers) GetTradeStats(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
+	period := c.DefaultQuery("period", "month")
+	if period != "week" && period != "month" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid period",
+			"message": "Period must be 'week' or 'month'",
+		})
+		return
+	}
+
+	stats, err := coinbaseClient.GetTradeStats(period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to calculate trade statistics",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
 }
 
 // GetPerformance returns performance statistics
 func (h *Handlers) GetPerformance(c *gin.Context) {
-	stats := h.client.GetPerformanceStats()
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
+	stats := coinbaseClient.GetPerformanceStats()
 	c.JSON(http.StatusOK, gin.H{
 		"performance": stats,
 		"timestamp":   time.Now().Format(time.RFC3339),
@@ -448,7 +1084,12 @@ func (h *Handlers) GetPerformance(c *gin.Context) {
 
 // GetSignal calculates technical indicators and checks for bearish signals
 func (h *Handlers) GetSignal(c *gin.Context) {
-	signal, err := h.client.GetSignal()
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
+	signal, err := coinbaseClient.GetSignal()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to calculate signal",
@@ -467,8 +1108,63 @@ func (h *Handlers) GetSignal(c *gin.Context) {
 	}
 }
 
+// GetDipStatus reports the immediate-dip detector's score and triggers without touching the signal state
+func (h *Handlers) GetDipStatus(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
+	status, err := coinbaseClient.GetDipStatus()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to calculate dip status",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// SimulateSignal classifies the indicator values in the request body without touching the signal state
+func (h *Handlers) SimulateSignal(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
+	var indicators client.TechnicalIndicators
+	if !bindJSON(c, &indicators) {
+		return
+	}
+
+	simulation, err := coinbaseClient.SimulateSignal(indicators)
+	if errors.Is(err, client.ErrInvalidIndicators) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid indicators",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to simulate signal",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, simulation)
+}
+
 // GetGraph returns a PNG chart image for Telegram
 func (h *Handlers) GetGraph(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
 	// Get period from query parameter (default to week)
 	period := c.DefaultQuery("period", "week")
 	if period != "week" && period != "month" {
@@ -479,8 +1175,80 @@ func (h *Handlers) GetGraph(c *gin.Context) {
 		return
 	}
 
+	// Optional granularity override (defaults depend on the period)
+	granularity := strings.ToUpper(c.Query("granularity"))
+	if granularity != "" && (!validGranularities[granularity] || granularity == "UNKNOWN_GRANULARITY") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid granularity",
+			"message": "Granularity must be one of: ONE_MINUTE, FIVE_MINUTE, FIFTEEN_MINUTE, THIRTY_MINUTE, ONE_HOUR, TWO_HOUR, SIX_HOUR, ONE_DAY",
+		})
+		return
+	}
+
+	// Optional account value source (auto prefers in-memory history, falling back to trades)
+	valueSource := strings.ToLower(c.DefaultQuery("value_source", client.ValueSourceAuto))
+	if valueSource != client.ValueSourceAuto && valueSource != client.ValueSourceMemory && valueSource != client.ValueSourceComputed {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid value_source",
+			"message": "value_source must be 'auto', 'memory' or 'computed'",
+		})
+		return
+	}
+
+	// Optional asset value point budget for the chart (long series are downsampled)
+	var points int
+	if pointsStr := c.Query("points"); pointsStr != "" {
+		parsed, err := strconv.Atoi(pointsStr)
+		if err != nil || parsed < 3 || parsed > 5000 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid points parameter",
+				"message": "Points must be between 3 and 5000",
+			})
+			return
+		}
+		points = parsed
+	}
+
+	// Optional timezone for axis labels and the title (defaults to CHART_TIMEZONE, then UTC)
+	var location *time.Location
+	if tz := c.Query("tz"); tz != "" {
+		loaded, err := time.LoadLocation(tz)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid timezone",
+				"message": fmt.Sprintf("Unknown timezone %q (use an IANA name such as Europe/Brussels)", tz),
+			})
+			return
+		}
+		location = loaded
+	}
+
+	// Optional volume panel under the price chart
+	volume := false
+	if volumeStr := c.Query("volume"); volumeStr != "" {
+		parsed, err := strconv.ParseBool(volumeStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid volume parameter",
+				"message": "volume must be true or false",
+			})
+			return
+		}
+		volume = parsed
+	}
+
 	// Get graph data from client
-	graphData, err := h.client.GetGraphData(period)
+	graphData, err := coinbaseClient.GetGraphData(period, client.GraphOptions{
+		Granularity: granularity,
+		ValueSource: valueSource,
+	})
+	if errors.Is(err, client.ErrCandleBudgetExceeded) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Granularity too fine for period",
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to fetch graph data",
@@ -490,7 +1258,7 @@ func (h *Handlers) GetGraph(c *gin.Context) {
 	}
 
 	// Generate PNG chart with dual Y-axes
-	pngData, err := h.client.GenerateChartPNG(graphData)
+	pngData, err := coinbaseClient.GenerateChartPNG(graphData, client.ChartOptions{MaxPoints: points, Location: location, Volume: volume})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to generate chart",
@@ -501,17 +1269,66 @@ func (h *Handlers) GetGraph(c *gin.Context) {
 
 	// Set headers for PNG image
 	c.Header("Content-Type", "image/png")
-	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=btc-usdc-chart-%s.png", period))
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%s-chart-%s.png", strings.ToLower(coinbaseClient.GetTradingPair()), period))
 	c.Header("Cache-Control", "public, max-age=300") // Cache for 5 minutes
 
 	// Return PNG data
 	c.Data(http.StatusOK, "image/png", pngData)
 }
 
+// GetIndicatorSeries returns per-candle EMA12/26, RSI, MACD and signal series with the candle timestamps
+func (h *Handlers) GetIndicatorSeries(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
+	period := c.DefaultQuery("period", "week")
+	if period != "week" && period != "month" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid period",
+			"message": "Period must be 'week' or 'month'",
+		})
+		return
+	}
+
+	granularity := strings.ToUpper(c.Query("granularity"))
+	if granularity != "" && (!validGranularities[granularity] || granularity == "UNKNOWN_GRANULARITY") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid granularity",
+			"message": "Granularity must be one of: ONE_MINUTE, FIVE_MINUTE, FIFTEEN_MINUTE, THIRTY_MINUTE, ONE_HOUR, TWO_HOUR, SIX_HOUR, ONE_DAY",
+		})
+		return
+	}
+
+	series, err := coinbaseClient.GetIndicatorSeries(period, granularity)
+	if errors.Is(err, client.ErrCandleBudgetExceeded) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Granularity too fine for period",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to calculate indicator series",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
 // CheckSignal performs a manual signal check and returns detailed results
 func (h *Handlers) CheckSignal(c *gin.Context) {
-	// Track asset value before checking signals
-	if err := h.client.TrackAssetValue(); err != nil {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
+	// Track asset value before checking signals (skipped when the last sample is too recent)
+	if err := coinbaseClient.TrackAssetValue(); err != nil && !errors.Is(err, client.ErrAssetSampleSkipped) {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to track asset value",
 			"message": err.Error(),
@@ -520,7 +1337,7 @@ func (h *Handlers) CheckSignal(c *gin.Context) {
 	}
 
 	// Get signal using lightweight method
-	signal, err := h.client.GetSignalLightweight()
+	signal, err := coinbaseClient.GetSignalLightweight()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to calculate signal",
@@ -549,3 +1366,149 @@ func (h *Handlers) CheckSignal(c *gin.Context) {
 		"timestamp": time.Now().Format(time.RFC3339),
 	})
 }
+
+// GetConfig returns the effective configuration of this instance with secrets redacted
+func (h *Handlers) GetConfig(c *gin.Context) {
+	coinbaseClient, ok := h.clientFor(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"trading": gin.H{
+			"base_currency":        h.tradingConfig.GetBaseCurrency(),
+			"quote_currency":       h.tradingConfig.GetQuoteCurrency(),
+			"trading_pair":         h.tradingConfig.GetTradingPair(),
+			"trading_pairs":        h.tradingConfig.GetTradingPairs(),
+			"market_default_limit": h.tradingConfig.MarketDefaultLimit,
+		},
+		"webhook": gin.H{
+			"configured":      h.tradingConfig.WebhookURL != "",
+			"url":             redactURL(h.tradingConfig.WebhookURL),
+			"max_retries":     h.tradingConfig.WebhookMaxRetries,
+			"timeout_seconds": h.tradingConfig.WebhookTimeout,
+		},
+		"security": gin.H{
+			"access_key_auth":       h.securityConfig.EnableAccessKeyAuth,
+			"access_key_set":        h.securityConfig.AccessKey != "",
+			"rate_limiting":         h.securityConfig.EnableRateLimiting,
+			"rate_limit_per_minute": h.securityConfig.RateLimitPerMinute,
+			"ip_whitelist":          h.securityConfig.EnableIPWhitelist,
+			"allowed_ips":           h.securityConfig.AllowedIPs,
+			"cors_allowed_origins":  h.securityConfig.CORSAllowedOrigins,
+			"max_body_bytes":        h.securityConfig.MaxBodyBytes,
+		},
+		"client":    coinbaseClient.GetEffectiveConfig(),
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// rejectShuttingDown answers 503 when err is client.ErrShuttingDown, i.e. shutdown is draining trades, and
+// reports whether it did. It's checked before a trade starts and on the trade's error, which covers a drain
+// that starts in between.
+func rejectShuttingDown(c *gin.Context, err error) bool {
+	if !errors.Is(err, client.ErrShuttingDown) {
+		return false
+	}
+	c.JSON(http.StatusServiceUnavailable, gin.H{
+		"error":   "Shutting down",
+		"code":    "SHUTTING_DOWN",
+		"message": err.Error(),
+	})
+	return true
+}
+
+// rejectReadOnly responds 403 to a request that would trade on a route READ_ONLY limits to dry runs.
+// It reports whether the request was rejected.
+func (h *Handlers) rejectReadOnly(c *gin.Context, dryRun bool) bool {
+	if dryRun || !h.tradingConfig.DryRunOnly(c.Request.Method, strings.TrimPrefix(c.FullPath(), "/api/v1")) {
+		return false
+	}
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":   "Endpoint disabled",
+		"message": fmt.Sprintf("%s %s only accepts dry runs (\"dry_run\": true) with READ_ONLY", c.Request.Method, c.FullPath()),
+	})
+	return true
+}
+
+// maxIdempotencyKeyLength bounds Idempotency-Key, a UUID fits with room for the suffix a shrunk retry adds
+const maxIdempotencyKeyLength = 64
+
+// idempotencyKey returns the trimmed Idempotency-Key header, answering 400 when it is longer than
+// maxIdempotencyKeyLength or has characters other than letters, digits, '-' and '_'. It returns false when a
+// response was written; a missing header is valid and returns "".
+func idempotencyKey(c *gin.Context) (string, bool) {
+	key := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+	valid := len(key) <= maxIdempotencyKeyLength
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			valid = false
+			break
+		}
+	}
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid Idempotency-Key",
+			"message": fmt.Sprintf("Idempotency-Key must be at most %d letters, digits, '-' or '_'", maxIdempotencyKeyLength),
+		})
+		return "", false
+	}
+	return key, true
+}
+
+// freshParam parses the optional "fresh" query parameter (anything strconv.ParseBool accepts), answering 400
+// for other values. It returns false when a response was written; a missing parameter is false.
+func freshParam(c *gin.Context) (bool, bool) {
+	value := c.Query("fresh")
+	if value == "" {
+		return false, true
+	}
+	fresh, err := strconv.ParseBool(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid fresh parameter",
+			"message": "fresh must be true or false",
+		})
+		return false, false
+	}
+	return fresh, true
+}
+
+// bindJSON binds the JSON request body into req, answering 413 when the body went over MAX_REQUEST_BODY_BYTES
+// and 400 for any other error. It returns false when a response was written.
+func bindJSON(c *gin.Context, req interface{}) bool {
+	err := c.ShouldBindJSON(req)
+	if err == nil {
+		return true
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":   "Request body too large",
+			"message": fmt.Sprintf("Request body must not exceed %d bytes", maxBytesErr.Limit),
+		})
+		return false
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":   "Invalid request body",
+		"message": err.Error(),
+	})
+	return false
+}
+
+// redactURL keeps only the scheme and host of a URL, since paths and query strings often embed tokens
+func redactURL(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "[REDACTED]"
+	}
+	if parsed.Path == "" && parsed.RawQuery == "" {
+		return parsed.Scheme + "://" + parsed.Host
+	}
+	return parsed.Scheme + "://" + parsed.Host + "/[REDACTED]"
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"coinbase-base/client"
+	"coinbase-base/config"
+	"coinbase-base/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestHandlers returns handlers over a BTC-USDC client signed with a throwaway key. Nothing is fetched
+// until a handler reaches Coinbase, so tests must stop at validation.
+func newTestHandlers(t *testing.T, tradingConfig *config.TradingConfig) *Handlers {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	t.Setenv("COINBASE_API_KEY", "test-key")
+	t.Setenv("COINBASE_API_SECRET", string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})))
+	t.Setenv("LOG_LEVEL", "ERROR")
+
+	manager, err := client.NewClientManager([]string{"BTC-USDC"}, "", 0, 0)
+	if err != nil {
+		t.Fatalf("failed to create client manager: %v", err)
+	}
+	t.Cleanup(func() { manager.Close() })
+
+	return NewHandlers(manager, tradingConfig, &middleware.SecurityConfig{})
+}
+
+// serve runs one request through handler and returns the recorded response
+func serve(handler gin.HandlerFunc, method, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(method, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handler(c)
+	return recorder
+}
+
+func TestRebalanceRejectsMissingOrInvalidTarget(t *testing.T) {
+	handlers := newTestHandlers(t, &config.TradingConfig{})
+
+	tests := []struct {
+		name    string
+		body    string
+		message string
+	}{
+		{"empty body", `{}`, "Missing target_base_pct"},
+		{"only dry run", `{"dry_run": true}`, "Missing target_base_pct"},
+		{"explicit null", `{"target_base_pct": null}`, "Missing target_base_pct"},
+		{"negative", `{"target_base_pct": -1}`, "Invalid target_base_pct"},
+		{"above 100", `{"target_base_pct": 101}`, "Invalid target_base_pct"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := serve(handlers.Rebalance, http.MethodPost, tt.body)
+			if recorder.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d (%s)", recorder.Code, http.StatusBadRequest, recorder.Body)
+			}
+			if !strings.Contains(recorder.Body.String(), tt.message) {
+				t.Errorf("body = %s, want %q", recorder.Body, tt.message)
+			}
+		})
+	}
+}
+
+func TestReadOnlyDisablesTradingRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tradingConfig := &config.TradingConfig{ReadOnly: true}
+	handlers := newTestHandlers(t, tradingConfig)
+
+	// Stub handlers stand in for the ones that would reach Coinbase
+	reached := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	router := gin.New()
+	api := router.Group("/api/v1")
+	api.Use(middleware.EndpointGuard("/api/v1", tradingConfig.IsEndpointEnabled))
+	api.GET("/orders", reached)
+	api.POST("/buy", reached)
+	api.DELETE("/orders", reached)
+	api.PUT("/orders/:order_id", reached)
+	api.POST("/signal/simulate", reached)
+	api.POST("/rebalance", handlers.Rebalance)
+	api.POST("/close-position", handlers.ClosePosition)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{http.MethodGet, "/api/v1/orders", "", http.StatusNoContent},
+		{http.MethodPost, "/api/v1/buy", `{"size": "0.1", "price": 50000}`, http.StatusForbidden},
+		{http.MethodDelete, "/api/v1/orders", "", http.StatusForbidden},
+		{http.MethodPut, "/api/v1/orders/abc", `{"price": 50000}`, http.StatusForbidden},
+		{http.MethodPost, "/api/v1/signal/simulate", `{}`, http.StatusNoContent},
+		{http.MethodPost, "/api/v1/rebalance", `{"target_base_pct": 60}`, http.StatusForbidden},
+		{http.MethodPost, "/api/v1/close-position", "", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/close-position", `{"dry_run": false}`, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(recorder, req)
+			if recorder.Code != tt.status {
+				t.Errorf("status = %d, want %d (%s)", recorder.Code, tt.status, recorder.Body)
+			}
+		})
+	}
+}
+
+func TestIdempotencyKeyIsValidated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handlers := newTestHandlers(t, &config.TradingConfig{})
+
+	tests := []struct {
+		name  string
+		key   string
+		valid bool
+	}{
+		{"none", "", true},
+		{"uuid", " 7d0b6a52-1f0e-4c1a-9a57-3c2f4f1b9e10 ", true},
+		{"longest", strings.Repeat("k", 64), true},
+		{"too long", strings.Repeat("k", 65), false},
+		{"spaces inside", "retry 1", false},
+		{"punctuation", "order/1?x=y", false},
+		{"non-ASCII", "clé-1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+			c.Request.Header.Set("Idempotency-Key", tt.key)
+			key, ok := idempotencyKey(c)
+			if ok != tt.valid {
+				t.Fatalf("idempotencyKey(%q) valid = %v, want %v", tt.key, ok, tt.valid)
+			}
+			if ok && key != strings.TrimSpace(tt.key) {
+				t.Errorf("idempotencyKey(%q) = %q, want it trimmed", tt.key, key)
+			}
+			if !ok && recorder.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", recorder.Code)
+			}
+		})
+	}
+
+	// Both order routes reject a bad key before anything reaches Coinbase
+	for name, handler := range map[string]gin.HandlerFunc{"buy": handlers.BuyBTC, "sell": handlers.SellBTC} {
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"size": "0.1", "price": 50000}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Header.Set("Idempotency-Key", "not a valid key!")
+		handler(c)
+		if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "Invalid Idempotency-Key") {
+			t.Errorf("%s with a bad key: %d %s, want 400 Invalid Idempotency-Key", name, recorder.Code, recorder.Body)
+		}
+	}
+}
+
+func TestFreshParamIsValidated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handlers := newTestHandlers(t, &config.TradingConfig{MarketDefaultLimit: 10})
+
+	tests := []struct {
+		query string
+		fresh bool
+		valid bool
+	}{
+		{"", false, true},
+		{"fresh=true", true, true},
+		{"fresh=1", true, true},
+		{"fresh=TRUE", true, true},
+		{"fresh=false", false, true},
+		{"fresh=0", false, true},
+		{"fresh=yes", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			fresh, ok := freshParam(c)
+			if ok != tt.valid || fresh != tt.fresh {
+				t.Fatalf("freshParam(%q) = %v, %v, want %v, %v", tt.query, fresh, ok, tt.fresh, tt.valid)
+			}
+			if !ok && recorder.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", recorder.Code)
+			}
+		})
+	}
+
+	// Both cached routes reject a bad value before anything reaches Coinbase
+	for name, handler := range map[string]gin.HandlerFunc{"market": handlers.GetMarketState, "product": handlers.GetProductStats} {
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest(http.MethodGet, "/?fresh=yes", nil)
+		handler(c)
+		if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "Invalid fresh parameter") {
+			t.Errorf("%s with fresh=yes: %d %s, want 400 Invalid fresh parameter", name, recorder.Code, recorder.Body)
+		}
+	}
+}
@@ -2,14 +2,18 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"net/http/pprof"
 	"os"
 	"os/signal"
+	"runtime"
 	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Embed the timezone database; the alpine runtime image has no tzdata for CHART_TIMEZONE
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -19,6 +23,17 @@ import (
 	"coinbase-base/middleware"
 )
 
+// Build information, injected at build time with
+// -ldflags "-X main.version=... -X main.commit=... -X main.buildTime=..."
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildTime = "unknown"
+)
+
+// startTime is used to report uptime on /api/v1/version
+var startTime = time.Now()
+
 // Logger interface for consistent logging
 type Logger interface {
 	Info(format string, args ...interface{})
@@ -87,13 +102,21 @@ func main() {
 	} else {
 		logger.Info("Running in development mode with log level: %s", logLevel)
 	}
+	logger.Info("🏷️ Version %s (commit %s, built %s)", version, commit, buildTime)
 
 	// Load configurations
 	tradingConfig := config.LoadTradingConfig()
+	if err := tradingConfig.Validate(); err != nil {
+		logger.Error("Invalid trading configuration: %v", err)
+		os.Exit(1)
+	}
 	securityConfig := middleware.LoadSecurityConfig()
 
 	// Log startup information
 	logger.Info("📈 Trading pair: %s (%s/%s)", tradingConfig.GetTradingPair(), tradingConfig.GetBaseCurrency(), tradingConfig.GetQuoteCurrency())
+	if len(tradingConfig.GetTradingPairs()) > 1 {
+		logger.Info("📈 Tracked pairs: %s (default: %s)", strings.Join(tradingConfig.GetTradingPairs(), ", "), tradingConfig.GetTradingPair())
+	}
 
 	logger.Info("🔐 Security features:")
 	logger.Info("   - Rate limiting: %v (%d req/min)", securityConfig.EnableRateLimiting, securityConfig.RateLimitPerMinute)
@@ -108,9 +131,9 @@ func main() {
 		logger.Info("   - Usage: X-API-Key header or ?api_key query param")
 	}
 
-	// Create Coinbase client
-	coinbaseClient, err := client.NewCoinbaseClient(
-		tradingConfig.GetTradingPair(),
+	// Create one Coinbase client per trading pair
+	clientManager, err := client.NewClientManager(
+		tradingConfig.GetTradingPairs(),
 		tradingConfig.WebhookURL,
 		tradingConfig.WebhookMaxRetries,
 		tradingConfig.WebhookTimeout,
@@ -119,87 +142,143 @@ func main() {
 		logger.Error("Failed to create Coinbase client: %v", err)
 		os.Exit(1)
 	}
-	defer coinbaseClient.Close()
+	defer clientManager.Close()
+
+	// The default pair client backs the health check
+	coinbaseClient := clientManager.Default()
 
 	// Initialize handlers
-	handlers := NewHandlers(coinbaseClient)
+	handlers := NewHandlers(clientManager, tradingConfig, securityConfig)
 
 	// Start background signal polling if webhook URL is configured
 	if tradingConfig.WebhookURL != "" {
 		logger.Info("🔔 Starting background signal polling (every 10 minutes)")
 		logger.Debug("   - Webhook URL: %s", tradingConfig.WebhookURL)
-		go startSignalPolling(coinbaseClient, tradingConfig.WebhookURL)
+		go startSignalPolling(clientManager, tradingConfig.WebhookURL)
 	} else {
 		logger.Info("🔕 No webhook URL configured - signal polling disabled")
 		logger.Debug("   - Set WEBHOOK_URL to enable automatic signal notifications")
 	}
 
+	// Alert on healthy/unhealthy transitions, probing in the background so no /health traffic is needed
+	var healthMonitor *client.HealthMonitor
+	if tradingConfig.HealthWebhookURL != "" {
+		healthMonitor = client.NewHealthMonitor(coinbaseClient, tradingConfig.HealthWebhookURL, tradingConfig.HealthWebhookDebounce)
+		logger.Info("🩺 Health alerts enabled (probe every %v, %d results to flip)", tradingConfig.HealthCheckInterval, tradingConfig.HealthWebhookDebounce)
+		go startHealthProber(healthMonitor, coinbaseClient, tradingConfig.HealthCheckInterval)
+	}
+
+	// Orders left open after placement are polled so their later fills reach the execution webhook
+	if os.Getenv("EXECUTION_WEBHOOK_URL") != "" {
+		logger.Info("🧾 Watching open orders for fills (every %v)", client.ExecutionPollInterval())
+		go startFillWatcher(clientManager, client.ExecutionPollInterval())
+	}
+
+	// Warm the signal candle cache without delaying server readiness
+	if tradingConfig.PrefetchOnStartup {
+		go prefetchSignalCandles(clientManager)
+	}
+
 	// Create Gin router
 	router := gin.New()
 
 	// Add middleware
 	router.Use(gin.Recovery())
+	router.Use(middleware.CORSMiddleware(securityConfig)) // Before the access key check, which would reject preflights
+	router.Use(middleware.MaxBodySize(securityConfig))
 	router.Use(middleware.SecurityMiddleware(securityConfig))
 
-	// Health check endpoint (no logging for frequent health checks)
-	router.GET("/health", func(c *gin.Context) {
-		// Test Coinbase communication and authentication
-		accounts, err := coinbaseClient.GetAccountsWithLogging(false) // Suppress debug logs for health checks
-		if err != nil {
+	// Liveness endpoint: the process is up and the key parsed, independent of Coinbase availability
+	router.GET("/healthz", func(c *gin.Context) {
+		if !coinbaseClient.KeyLoaded() {
 			c.JSON(503, gin.H{
 				"status":    "unhealthy",
-				"error":     "Coinbase API communication failed",
-				"message":   err.Error(),
+				"error":     "API key not loaded",
 				"timestamp": time.Now().Format(time.RFC3339),
 			})
 			return
 		}
+		c.JSON(200, gin.H{
+			"status":    "alive",
+			"timestamp": time.Now().Format(time.RFC3339),
+		})
+	})
 
-		// Check if we have both BTC and USDC accounts
-		var hasBTC, hasUSDC bool
-		for _, account := range accounts {
-			if account.Currency == "BTC" && account.TradingEnabled {
-				hasBTC = true
-			}
-			if account.Currency == "USDC" && account.TradingEnabled {
-				hasUSDC = true
-			}
-		}
-
-		if !hasBTC || !hasUSDC {
-			c.JSON(503, gin.H{
-				"status":    "unhealthy",
-				"error":     "Missing required trading accounts",
-				"message":   "Both BTC and USDC accounts must be available and enabled for trading",
-				"timestamp": time.Now().Format(time.RFC3339),
-			})
-			return
+	// Health check endpoint (no logging for frequent health checks); results also feed the health alerts
+	router.GET("/health", func(c *gin.Context) {
+		status, response := checkHealth(coinbaseClient)
+		if healthMonitor != nil {
+			healthMonitor.Record(status == 200, healthReason(response))
 		}
+		c.JSON(status, response)
+	})
 
+	// Version endpoint (no auth, like health) to confirm which build is deployed
+	router.GET("/api/v1/version", func(c *gin.Context) {
 		c.JSON(200, gin.H{
-			"status":    "healthy",
-			"timestamp": time.Now().Format(time.RFC3339),
-			"accounts": gin.H{
-				"btc_available":  hasBTC,
-				"usdc_available": hasUSDC,
-			},
+			"version":        version,
+			"commit":         commit,
+			"build_time":     buildTime,
+			"go_version":     runtime.Version(),
+			"uptime_seconds": int64(time.Since(startTime).Seconds()),
 		})
 	})
 
 	// API routes
 	api := router.Group("/api/v1")
+	api.Use(middleware.EndpointGuard("/api/v1", tradingConfig.IsEndpointEnabled))
 	{
 		api.GET("/performance", handlers.GetPerformance)
 		api.GET("/signal", handlers.GetSignal)
+		api.GET("/dip", handlers.GetDipStatus)
 		api.GET("/signal/check", handlers.CheckSignal) // Manual signal check
+		api.POST("/signal/simulate", handlers.SimulateSignal)
 		api.GET("/accounts", handlers.GetAccounts)
 		api.GET("/orders", handlers.GetOrders)
 		api.POST("/buy", handlers.BuyBTC)
 		api.POST("/sell", handlers.SellBTC)
 		api.DELETE("/orders", handlers.CancelAllOrders)
+		api.PUT("/orders/:order_id", handlers.ReplaceOrder)
+		api.POST("/rebalance", handlers.Rebalance)
+		api.POST("/close-position", handlers.ClosePosition)
 		api.GET("/candles", handlers.GetCandles)
 		api.GET("/market", handlers.GetMarketState)
+		api.GET("/market/depth-chart", handlers.GetDepthChart)
+		api.GET("/estimate-fill", handlers.EstimateFill)
+		api.GET("/product", handlers.GetProductStats)
+		api.GET("/spread-history", handlers.GetSpreadHistory)
+		api.GET("/summary", handlers.GetSummary)
+		api.GET("/stats", handlers.GetTradeStats)
 		api.GET("/graph", handlers.GetGraph)
+		api.GET("/indicators/series", handlers.GetIndicatorSeries)
+		api.GET("/config", handlers.GetConfig)
+	}
+
+	// Runtime profiling (opt-in): exposes goroutine stacks, heap contents and command line to anyone with the access key
+	if securityConfig.EnablePprof {
+		if !securityConfig.EnableAccessKeyAuth {
+			logger.Warn("⚠️  ENABLE_PPROF is on without access key auth - profiles are reachable without credentials")
+		}
+		registerPprof(router.Group("/debug/pprof"))
+		logger.Info("🩺 pprof enabled under /debug/pprof")
+	}
+
+	// Log which API endpoints are enabled (READ_ONLY / ENABLED_ENDPOINTS)
+	if tradingConfig.ReadOnly || len(tradingConfig.EnabledEndpoints) > 0 {
+		logger.Info("🔒 Endpoint restrictions: read-only=%v", tradingConfig.ReadOnly)
+		for _, route := range router.Routes() {
+			path, isAPI := strings.CutPrefix(route.Path, "/api/v1")
+			if !isAPI {
+				continue
+			}
+			if tradingConfig.DryRunOnly(route.Method, path) {
+				logger.Info("   - dry run:  %s %s", route.Method, route.Path)
+			} else if tradingConfig.IsEndpointEnabled(route.Method, path) {
+				logger.Info("   - enabled:  %s %s", route.Method, route.Path)
+			} else {
+				logger.Info("   - disabled: %s %s", route.Method, route.Path)
+			}
+		}
 	}
 
 	// Get port from environment or use default
@@ -219,16 +298,31 @@ func main() {
 		logger.Info("🚀 Starting server on port %s", port)
 		logger.Debug("📖 API Documentation:")
 		logger.Debug("   - Health check: GET http://localhost:%s/health", port)
+		logger.Debug("   - Liveness: GET http://localhost:%s/healthz", port)
+		logger.Debug("   - Version: GET http://localhost:%s/api/v1/version", port)
 		logger.Debug("   - Performance: GET http://localhost:%s/api/v1/performance", port)
 		logger.Debug("   - Signal: GET http://localhost:%s/api/v1/signal", port)
+		logger.Debug("   - Dip status: GET http://localhost:%s/api/v1/dip", port)
+		logger.Debug("   - Simulate signal: POST http://localhost:%s/api/v1/signal/simulate", port)
 		logger.Debug("   - Accounts: GET http://localhost:%s/api/v1/accounts", port)
 		logger.Debug("   - Orders: GET http://localhost:%s/api/v1/orders", port)
 		logger.Debug("   - Buy: POST http://localhost:%s/api/v1/buy", port)
 		logger.Debug("   - Sell: POST http://localhost:%s/api/v1/sell", port)
 		logger.Debug("   - Cancel all: DELETE http://localhost:%s/api/v1/orders", port)
+		logger.Debug("   - Replace order: PUT http://localhost:%s/api/v1/orders/:order_id", port)
+		logger.Debug("   - Rebalance: POST http://localhost:%s/api/v1/rebalance", port)
+		logger.Debug("   - Close position: POST http://localhost:%s/api/v1/close-position", port)
 		logger.Debug("   - Candles: GET http://localhost:%s/api/v1/candles", port)
 		logger.Debug("   - Market: GET http://localhost:%s/api/v1/market", port)
+		logger.Debug("   - Depth chart: GET http://localhost:%s/api/v1/market/depth-chart", port)
+		logger.Debug("   - Estimate fill: GET http://localhost:%s/api/v1/estimate-fill?side=BUY&size=0.01", port)
+		logger.Debug("   - Product stats: GET http://localhost:%s/api/v1/product", port)
+		logger.Debug("   - Spread history: GET http://localhost:%s/api/v1/spread-history", port)
+		logger.Debug("   - Summary: GET http://localhost:%s/api/v1/summary", port)
+		logger.Debug("   - Trade stats: GET http://localhost:%s/api/v1/stats?period=month", port)
 		logger.Debug("   - Graph: GET http://localhost:%s/api/v1/graph?period=week", port)
+		logger.Debug("   - Indicator series: GET http://localhost:%s/api/v1/indicators/series?period=week", port)
+		logger.Debug("   - Config: GET http://localhost:%s/api/v1/config", port)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logger.Error("Failed to start server: %v", err)
 			os.Exit(1)
@@ -242,48 +336,165 @@ func main() {
 
 	logger.Info("Shutting down server...")
 
-	// Create a deadline for server shutdown
+	// Create a deadline for the whole shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Refuse new trades (503) and let the orders being placed or polled finish before connections are closed
+	if err := clientManager.Drain(ctx); err != nil {
+		logger.Error("Shutting down with trades in flight: %v", err)
+	}
+
 	// Attempt graceful shutdown
 	if err := server.Shutdown(ctx); err != nil {
 		logger.Error("Server forced to shutdown: %v", err)
 	}
 
 	// Close HTTP client connections
-	if err := coinbaseClient.Close(); err != nil {
+	if err := clientManager.Close(); err != nil {
 		logger.Error("Error closing HTTP client: %v", err)
 	}
 
 	logger.Info("Server stopped.")
 }
 
-// startSignalPolling runs background signal polling every 10 minutes
-func startSignalPolling(client *client.CoinbaseClient, webhookURL string) {
+// registerPprof mounts the net/http/pprof handlers on a Gin group (the group path must be /debug/pprof,
+// which pprof.Index uses to resolve named profiles)
+func registerPprof(group *gin.RouterGroup) {
+	group.GET("/", gin.WrapF(pprof.Index))
+	group.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	group.GET("/profile", gin.WrapF(pprof.Profile))
+	group.GET("/symbol", gin.WrapF(pprof.Symbol))
+	group.POST("/symbol", gin.WrapF(pprof.Symbol))
+	group.GET("/trace", gin.WrapF(pprof.Trace))
+	for _, name := range []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"} {
+		group.GET("/"+name, gin.WrapH(pprof.Handler(name)))
+	}
+}
+
+// prefetchSignalCandles fetches the signal candles of every pair so the first signal check only tops up the cache
+func prefetchSignalCandles(manager *client.ClientManager) {
+	start := time.Now()
+	for _, pairClient := range manager.Clients() {
+		count, err := pairClient.PrefetchSignalCandles()
+		if err != nil {
+			log.Printf("[COINBASE-INFO] ⚠️ Candle prefetch failed: %v", err)
+			continue
+		}
+		log.Printf("[COINBASE-INFO] 🔥 Prefetched %d candles for %s", count, pairClient.GetTradingPair())
+	}
+	log.Printf("[COINBASE-INFO] 🔥 Candle warm-up finished in %v", time.Since(start).Round(time.Millisecond))
+}
+
+// startSignalPolling runs background signal polling every 10 minutes for every configured pair
+func startSignalPolling(manager *client.ClientManager, webhookURL string) {
 	ticker := time.NewTicker(10 * time.Minute)
 	defer ticker.Stop()
 
-	log.Printf("[COINBASE-INFO] 🚀 Background signal polling started - checking every 10 minutes")
+	log.Printf("[COINBASE-INFO] 🚀 Background signal polling started - checking %s every 10 minutes", strings.Join(manager.Pairs(), ", "))
 
 	// Send startup webhook to establish baseline position
 	log.Printf("[COINBASE-INFO] 🔍 Sending startup webhook with current market position...")
-	sendStartupWebhook(client, webhookURL)
+	for _, pairClient := range manager.Clients() {
+		sendStartupWebhook(pairClient, webhookURL)
+	}
+
+	// Collapse failures repeated on every poll, e.g. during a Coinbase outage
+	errorLog := client.NewLogThrottle(log.Default(), client.LogRepeatWindow())
 
 	// Run initial check immediately
 	log.Printf("[COINBASE-INFO] 🔍 Running initial signal check...")
-	checkSignal(client)
+	for _, pairClient := range manager.Clients() {
+		checkSignal(pairClient, errorLog)
+	}
 
 	// Continue polling every 10 minutes
 	for range ticker.C {
-		checkSignal(client)
+		for _, pairClient := range manager.Clients() {
+			checkSignal(pairClient, errorLog)
+		}
+	}
+}
+
+// checkHealth tests Coinbase communication and authentication and the required trading accounts,
+// returning the HTTP status and body of the /health response
+func checkHealth(coinbaseClient *client.CoinbaseClient) (int, gin.H) {
+	accounts, err := coinbaseClient.GetAccountsWithLogging(false) // Suppress debug logs for health checks
+	if err != nil {
+		return 503, gin.H{
+			"status":    "unhealthy",
+			"error":     "Coinbase API communication failed",
+			"message":   err.Error(),
+			"timestamp": time.Now().Format(time.RFC3339),
+		}
+	}
+
+	// Check if we have both BTC and USDC accounts
+	var hasBTC, hasUSDC bool
+	for _, account := range accounts {
+		if account.Currency == "BTC" && account.TradingEnabled {
+			hasBTC = true
+		}
+		if account.Currency == "USDC" && account.TradingEnabled {
+			hasUSDC = true
+		}
+	}
+
+	if !hasBTC || !hasUSDC {
+		return 503, gin.H{
+			"status":    "unhealthy",
+			"error":     "Missing required trading accounts",
+			"message":   "Both BTC and USDC accounts must be available and enabled for trading",
+			"timestamp": time.Now().Format(time.RFC3339),
+		}
+	}
+
+	return 200, gin.H{
+		"status":    "healthy",
+		"timestamp": time.Now().Format(time.RFC3339),
+		"accounts": gin.H{
+			"btc_available":  hasBTC,
+			"usdc_available": hasUSDC,
+		},
+	}
+}
+
+// healthReason summarizes an unhealthy /health response for the health webhook
+func healthReason(response gin.H) string {
+	reason, _ := response["error"].(string)
+	if message, ok := response["message"].(string); ok && message != "" {
+		reason += ": " + message
+	}
+	return reason
+}
+
+// startHealthProber runs the health check at a fixed interval and feeds the results to the health monitor
+func startHealthProber(monitor *client.HealthMonitor, coinbaseClient *client.CoinbaseClient, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for ; ; <-ticker.C {
+		status, response := checkHealth(coinbaseClient)
+		monitor.Record(status == 200, healthReason(response))
+	}
+}
+
+// startFillWatcher checks the orders each pair left open after placement, at a fixed interval, for new fills
+func startFillWatcher(manager *client.ClientManager, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, pairClient := range manager.Clients() {
+			pairClient.PollWatchedOrders()
+		}
 	}
 }
 
 // sendStartupWebhook sends a webhook at startup to establish current market position
 func sendStartupWebhook(client *client.CoinbaseClient, webhookURL string) {
-	// Track current asset value
-	if err := client.TrackAssetValue(); err != nil {
+	// Track current asset value (always, as the baseline of the history)
+	if err := client.ForceTrackAssetValue(); err != nil {
 		log.Printf("[COINBASE-INFO] ⚠️ Failed to track asset value for startup webhook: %v", err)
 	}
 
@@ -294,6 +505,14 @@ func sendStartupWebhook(client *client.CoinbaseClient, webhookURL string) {
 		return
 	}
 
+	// Chat formats get the current signal as a regular message
+	if !client.RawWebhook() {
+		if err := client.SendWebhook(signal); err != nil {
+			log.Printf("[COINBASE-INFO] ❌ Startup webhook failed: %v", err)
+		}
+		return
+	}
+
 	// Create startup webhook request
 	req, err := http.NewRequest("GET", webhookURL, nil)
 	if err != nil {
@@ -307,6 +526,8 @@ func sendStartupWebhook(client *client.CoinbaseClient, webhookURL string) {
 	q.Add("baseline", "true")
 	q.Add("current_trend", getCurrentTrendState(signal))
 	q.Add("timestamp", fmt.Sprintf("%d", signal.Timestamp))
+	q.Add("pair", client.GetTradingPair())
+	q.Add("sequence", fmt.Sprintf("%d", client.NextWebhookSequence()))
 
 	// Add signal information if any triggers are present
 	if len(signal.Triggers) > 0 {
@@ -354,27 +575,46 @@ func getCurrentTrendState(signal *client.SignalResponse) string {
 	return "neutral"
 }
 
-var (
-	lastTrendState = "neutral" // Track the previous trend state
-)
+var lastTrendStates = make(map[string]string) // Track the previous trend state per trading pair
+
+// checkSignal performs a signal check and sends webhook if needed; failures go through errorLog so an
+// outage logs each error once with a repeat count instead of on every poll
+func checkSignal(pairClient *client.CoinbaseClient, errorLog *client.LogThrottle) {
+	pair := pairClient.GetTradingPair()
+	lastTrendState, exists := lastTrendStates[pair]
+	if !exists {
+		lastTrendState = "neutral"
+	}
 
-// checkSignal performs a signal check and sends webhook if needed
-func checkSignal(client *client.CoinbaseClient) {
 	// Only log in debug mode to reduce noise
-	if os.Getenv("LOG_LEVEL") == "DEBUG" {
-		log.Printf("[COINBASE-INFO] 🔍 Checking for trading signals (lightweight mode)...")
+	if pairClient.Debug() {
+		log.Printf("[COINBASE-INFO] 🔍 Checking %s for trading signals (lightweight mode)...", pair)
+	}
+
+	// Track asset value before checking signals (a recent manual check may already have sampled it)
+	if err := pairClient.TrackAssetValue(); errors.Is(err, client.ErrAssetSampleSkipped) {
+		if pairClient.Debug() {
+			log.Printf("[COINBASE-INFO] %s asset value sampled recently, skipping", pair)
+		}
+	} else if err != nil {
+		errorLog.Printf("asset-value:"+pair, "[COINBASE-INFO] ⚠️ Failed to track %s asset value: %v", pair, err)
+	} else {
+		errorLog.Clear("asset-value:" + pair)
 	}
 
-	// Track asset value before checking signals
-	if err := client.TrackAssetValue(); err != nil {
-		log.Printf("[COINBASE-INFO] ⚠️ Failed to track asset value: %v", err)
+	// Sample the spread so spread history has data without manual calls
+	if err := pairClient.SampleSpread(); err != nil {
+		errorLog.Printf("spread:"+pair, "[COINBASE-INFO] ⚠️ Failed to sample %s spread: %v", pair, err)
+	} else {
+		errorLog.Clear("spread:" + pair)
 	}
 
-	signal, err := client.GetSignalLightweight() // Uses lightweight signal
+	signal, err := pairClient.GetSignalLightweight() // Uses lightweight signal
 	if err != nil {
-		log.Printf("[COINBASE-INFO] ❌ Signal check failed: %v", err)
+		errorLog.Printf("signal-check:"+pair, "[COINBASE-INFO] ❌ Signal check failed for %s: %v", pair, err)
 		return
 	}
+	errorLog.Clear("signal-check:" + pair)
 
 	// Determine current trend state
 	currentTrend := "neutral"
@@ -387,19 +627,19 @@ func checkSignal(client *client.CoinbaseClient) {
 
 	// Log signal check result focusing on trend changes
 	if len(signal.Triggers) > 0 {
-		log.Printf("[COINBASE-INFO] 🔄 Signal check: TREND CHANGE detected - %s → %s with triggers: %v", lastTrendState, currentTrend, signal.Triggers)
+		log.Printf("[COINBASE-INFO] 🔄 Signal check %s: TREND CHANGE detected - %s → %s with triggers: %v", pair, lastTrendState, currentTrend, signal.Triggers)
 	} else {
-		log.Printf("[COINBASE-INFO] ✅ Signal check: No trend change - current trend: %s", currentTrend)
+		log.Printf("[COINBASE-INFO] ✅ Signal check %s: No trend change - current trend: %s", pair, currentTrend)
 	}
 
 	// Update the last trend state for next comparison
-	lastTrendState = currentTrend
+	lastTrendStates[pair] = currentTrend
 
 	if len(signal.Triggers) > 0 { // Check if any triggers are present
 		log.Printf("[COINBASE-INFO] 🔄 TREND CHANGE DETECTED: %v", signal.Triggers)
 	} else {
 		// Only log in debug mode to reduce noise
-		if os.Getenv("LOG_LEVEL") == "DEBUG" {
+		if pairClient.Debug() {
 			log.Printf("[COINBASE-INFO] ✅ No trend changes detected")
 		}
 	}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Default CORS methods and headers, used when only CORS_ALLOWED_ORIGINS is set
+const (
+	defaultCORSAllowedMethods = "GET,POST,PUT,DELETE,OPTIONS"
+	defaultCORSAllowedHeaders = "Content-Type,X-API-Key"
+)
+
+// splitList splits a comma-separated environment value, dropping blanks
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// CORSMiddleware adds CORS headers for the configured origins and answers preflight requests itself.
+// It must run before SecurityMiddleware: browsers send preflights without the X-API-Key header, so the
+// access key check would otherwise reject them. Without CORS_ALLOWED_ORIGINS it does nothing.
+func CORSMiddleware(config *SecurityConfig) gin.HandlerFunc {
+	allowAll := slices.Contains(config.CORSAllowedOrigins, "*")
+	methods := strings.Join(config.CORSAllowedMethods, ", ")
+	headers := strings.Join(config.CORSAllowedHeaders, ", ")
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" || len(config.CORSAllowedOrigins) == 0 {
+			c.Next()
+			return
+		}
+
+		if !allowAll && !slices.Contains(config.CORSAllowedOrigins, origin) {
+			config.logger.Debug("🌐 CORS ORIGIN NOT ALLOWED: %s (Path: %s)", origin, c.Request.URL.Path)
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", origin)
+		c.Header("Vary", "Origin")
+
+		// Preflight: answer directly, the actual request carries the access key
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			c.Header("Access-Control-Allow-Methods", methods)
+			c.Header("Access-Control-Allow-Headers", headers)
+			c.Header("Access-Control-Max-Age", "600")
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
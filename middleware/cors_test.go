@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testSecurityConfig returns an access-key protected configuration that logs nowhere
+func testSecurityConfig() *SecurityConfig {
+	return &SecurityConfig{
+		AccessKey:           "secret",
+		EnableAccessKeyAuth: true,
+		logger:              &SimpleLogger{Logger: log.New(io.Discard, "", 0), level: "DEBUG"},
+	}
+}
+
+// testRouter serves GET /api/v1/price behind the CORS and security middleware, in main's order
+func testRouter(config *SecurityConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORSMiddleware(config), SecurityMiddleware(config))
+	router.GET("/api/v1/price", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func TestCORSPreflight(t *testing.T) {
+	config := testSecurityConfig()
+	config.CORSAllowedOrigins = []string{"https://dashboard.example"}
+	config.CORSAllowedMethods = splitList(defaultCORSAllowedMethods)
+	config.CORSAllowedHeaders = splitList(defaultCORSAllowedHeaders)
+	router := testRouter(config)
+
+	request := func(method, origin string, preflight bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/price", nil)
+		req.Header.Set("Origin", origin)
+		if preflight {
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// Preflights carry no access key, they are answered before the key check
+	recorder := request(http.MethodOptions, "https://dashboard.example", true)
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("preflight status = %d, want %d", recorder.Code, http.StatusNoContent)
+	}
+	headers := map[string]string{
+		"Access-Control-Allow-Origin":  "https://dashboard.example",
+		"Access-Control-Allow-Methods": "GET, POST, PUT, DELETE, OPTIONS",
+		"Access-Control-Allow-Headers": "Content-Type, X-API-Key",
+		"Access-Control-Max-Age":       "600",
+		"Vary":                         "Origin",
+	}
+	for header, want := range headers {
+		if got := recorder.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+
+	// Other origins get no CORS headers, and their preflight reaches the key check
+	recorder = request(http.MethodOptions, "https://other.example", true)
+	if recorder.Code != http.StatusUnauthorized || recorder.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("preflight from another origin = %d with Allow-Origin %q, want 401 without it",
+			recorder.Code, recorder.Header().Get("Access-Control-Allow-Origin"))
+	}
+
+	// The actual request still needs the access key, with the CORS headers so the browser can read the error
+	recorder = request(http.MethodGet, "https://dashboard.example", false)
+	if recorder.Code != http.StatusUnauthorized || recorder.Header().Get("Access-Control-Allow-Origin") != "https://dashboard.example" {
+		t.Errorf("request without key = %d with Allow-Origin %q, want 401 with the origin",
+			recorder.Code, recorder.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
+
+func TestCORSDisabledWithoutOrigins(t *testing.T) {
+	router := testRouter(testSecurityConfig())
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/price", nil)
+	req.Header.Set("Origin", "https://dashboard.example")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if recorder.Code == http.StatusNoContent || recorder.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("preflight without CORS_ALLOWED_ORIGINS = %d with Allow-Origin %q, want no CORS answer",
+			recorder.Code, recorder.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
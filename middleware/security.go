@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"fmt"
 	"log"
 	"net"
 	"net/http"
@@ -13,6 +14,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"golang.org/x/time/rate"
+
+	"coinbase-base/redact"
 )
 
 // Logger interface for consistent logging
@@ -61,6 +64,11 @@ type SecurityConfig struct {
 	EnableRateLimiting  bool
 	EnableIPWhitelist   bool
 	EnableAccessKeyAuth bool
+	EnablePprof         bool     // Mount net/http/pprof under /debug/pprof (behind the access key)
+	MaxBodyBytes        int64    // Largest accepted request body, larger ones get 413 (zero disables the cap)
+	CORSAllowedOrigins  []string // Origins allowed to call the API from a browser ("*" for any), empty disables CORS
+	CORSAllowedMethods  []string
+	CORSAllowedHeaders  []string
 	logger              Logger
 }
 
@@ -91,6 +99,9 @@ func (rl *RateLimiter) GetLimiter(ip string, requestsPerMinute int) *rate.Limite
 	return limiter
 }
 
+// defaultMaxBodyBytes caps request bodies when MAX_REQUEST_BODY_BYTES is unset
+const defaultMaxBodyBytes = 16 << 10
+
 // LoadSecurityConfig loads security configuration from environment variables
 func LoadSecurityConfig() *SecurityConfig {
 	config := &SecurityConfig{}
@@ -132,6 +143,16 @@ func LoadSecurityConfig() *SecurityConfig {
 		config.RateLimitPerMinute = 60 // default
 	}
 
+	// Load request body cap (our JSON payloads are a few hundred bytes)
+	config.MaxBodyBytes = defaultMaxBodyBytes
+	if maxBodyStr := os.Getenv("MAX_REQUEST_BODY_BYTES"); maxBodyStr != "" {
+		if maxBody, err := strconv.ParseInt(maxBodyStr, 10, 64); err == nil && maxBody >= 0 {
+			config.MaxBodyBytes = maxBody
+		} else {
+			config.logger.Warn("⚠️  Invalid MAX_REQUEST_BODY_BYTES %q, using %d", maxBodyStr, defaultMaxBodyBytes)
+		}
+	}
+
 	// Load IP whitelist
 	allowedIPsStr := os.Getenv("ALLOWED_IPS")
 	if allowedIPsStr != "" {
@@ -145,6 +166,18 @@ func LoadSecurityConfig() *SecurityConfig {
 	config.EnableRateLimiting = getEnvBool("ENABLE_RATE_LIMITING", true)
 	config.EnableIPWhitelist = getEnvBool("ENABLE_IP_WHITELIST", false)
 	config.EnableAccessKeyAuth = getEnvBool("ENABLE_ACCESS_KEY_AUTH", true)
+	config.EnablePprof = getEnvBool("ENABLE_PPROF", false)
+
+	// Load CORS config (disabled unless origins are listed)
+	config.CORSAllowedOrigins = splitList(os.Getenv("CORS_ALLOWED_ORIGINS"))
+	config.CORSAllowedMethods = splitList(os.Getenv("CORS_ALLOWED_METHODS"))
+	if len(config.CORSAllowedMethods) == 0 {
+		config.CORSAllowedMethods = splitList(defaultCORSAllowedMethods)
+	}
+	config.CORSAllowedHeaders = splitList(os.Getenv("CORS_ALLOWED_HEADERS"))
+	if len(config.CORSAllowedHeaders) == 0 {
+		config.CORSAllowedHeaders = splitList(defaultCORSAllowedHeaders)
+	}
 
 	return config
 }
@@ -222,6 +255,31 @@ func SecurityMiddleware(config *SecurityConfig) gin.HandlerFunc {
 	}
 }
 
+// MaxBodySize rejects requests whose declared body exceeds the configured cap with 413 and limits the rest
+// with http.MaxBytesReader, so reading past the cap (e.g. a chunked body during JSON binding) fails
+func MaxBodySize(config *SecurityConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if config.MaxBodyBytes <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > config.MaxBodyBytes {
+			config.logger.Warn("📦 REQUEST BODY TOO LARGE: %s (%d bytes, Path: %s)",
+				c.ClientIP(), c.Request.ContentLength, c.Request.URL.Path)
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":   "Request body too large",
+				"message": fmt.Sprintf("Request body must not exceed %d bytes", config.MaxBodyBytes),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.MaxBodyBytes)
+		c.Next()
+	}
+}
+
 // isIPAllowed checks if an IP is in the allowed list (supports CIDR notation)
 func isIPAllowed(clientIP string, allowedIPs []string) bool {
 	// Parse the client IP
@@ -250,12 +308,29 @@ func isIPAllowed(clientIP string, allowedIPs []string) bool {
 	return false
 }
 
-// isHealthCheck checks if the request is for a health check endpoint
+// isHealthCheck checks if the request is for a health check or version endpoint
 func isHealthCheck(path string) bool {
-	return path == "/ping" || path == "/health"
+	return path == "/ping" || path == "/health" || path == "/healthz" || path == "/api/v1/version"
 }
 
-// GetAccessKey returns the current access key (for display purposes)
+// GetAccessKey returns the current access key masked for display, keeping the first and last four characters
 func (config *SecurityConfig) GetAccessKey() string {
-	return config.AccessKey
+	return redact.Secret(config.AccessKey)
+}
+
+// EndpointGuard rejects requests to routes the enabled func disallows with 403 Forbidden.
+// The path passed to enabled is the route template with prefix trimmed (e.g. "/orders/:order_id").
+func EndpointGuard(prefix string, enabled func(method, path string) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := strings.TrimPrefix(c.FullPath(), prefix)
+		if path != "" && !enabled(c.Request.Method, path) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Endpoint disabled",
+				"message": fmt.Sprintf("%s %s is disabled by READ_ONLY/ENABLED_ENDPOINTS configuration", c.Request.Method, c.FullPath()),
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
 }
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config := testSecurityConfig()
+	config.MaxBodyBytes = 16
+
+	router := gin.New()
+	router.Use(MaxBodySize(config))
+	router.POST("/api/v1/buy", func(c *gin.Context) {
+		// A body without a declared length is only cut off while being read
+		if _, err := io.ReadAll(c.Request.Body); err != nil {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name    string
+		body    string
+		chunked bool
+		status  int
+		error   string
+	}{
+		{"within the cap", `{"size":"0.1"}`, false, http.StatusOK, ""},
+		{"exactly the cap", strings.Repeat("x", 16), false, http.StatusOK, ""},
+		{"declared oversized body", strings.Repeat("x", 17), false, http.StatusRequestEntityTooLarge, "Request body too large"},
+		{"undeclared oversized body", strings.Repeat("x", 1024), true, http.StatusRequestEntityTooLarge, "http: request body too large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/buy", strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			if recorder.Code != tt.status {
+				t.Errorf("status = %d, want %d (%s)", recorder.Code, tt.status, recorder.Body)
+			}
+			if !strings.Contains(recorder.Body.String(), tt.error) {
+				t.Errorf("body = %s, want %q", recorder.Body, tt.error)
+			}
+		})
+	}
+
+	// Zero disables the cap
+	config.MaxBodyBytes = 0
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/buy", strings.NewReader(strings.Repeat("x", 1024)))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status without a cap = %d, want %d", recorder.Code, http.StatusOK)
+	}
+}
+
+func TestAccessKeysAreNotLogged(t *testing.T) {
+	var logged bytes.Buffer
+	config := testSecurityConfig()
+	config.AccessKey = "k3y-0123456789abcdef-secret"
+	config.logger = &SimpleLogger{Logger: log.New(&logged, "", 0), level: "DEBUG"}
+	router := testRouter(config)
+
+	for _, key := range []string{config.AccessKey, "wrong-0123456789abcdef-guess"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/price", nil)
+		req.Header.Set("X-API-Key", key)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		if strings.Contains(logged.String(), key) {
+			t.Errorf("log exposes access key %q:\n%s", key, logged.String())
+		}
+	}
+	if logged.Len() == 0 {
+		t.Error("nothing was logged, the check proves nothing")
+	}
+
+	// The startup banner shows the masked key
+	if masked := config.GetAccessKey(); masked != "k3y-****cret" {
+		t.Errorf("GetAccessKey() = %q, want k3y-****cret", masked)
+	}
+}
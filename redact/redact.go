@@ -0,0 +1,20 @@
+// Package redact masks secrets (API keys, access keys, JWTs) before they are logged or displayed
+package redact
+
+// Secret keeps the first and last four characters of a secret so log lines can still be
+// correlated, and hides everything in between. Short values are fully masked.
+func Secret(secret string) string {
+	if len(secret) <= 12 {
+		return "****"
+	}
+	return secret[:4] + "****" + secret[len(secret)-4:]
+}
+
+// JWT reduces a JWT to its last few signature characters, which differ per request and are
+// enough to match a logged request against a server-side trace without exposing a usable token
+func JWT(jwt string) string {
+	if len(jwt) <= 8 {
+		return "<redacted>"
+	}
+	return "<redacted>..." + jwt[len(jwt)-8:]
+}
@@ -0,0 +1,26 @@
+package redact
+
+import "testing"
+
+func TestSecret(t *testing.T) {
+	tests := []struct{ secret, want string }{
+		{"", "****"},
+		{"short", "****"},
+		{"exactly12chr", "****"},
+		{"organizations/abc/apiKeys/0123456789abcdef", "orga****cdef"},
+	}
+	for _, tt := range tests {
+		if got := Secret(tt.secret); got != tt.want {
+			t.Errorf("Secret(%q) = %q, want %q", tt.secret, got, tt.want)
+		}
+	}
+}
+
+func TestJWT(t *testing.T) {
+	if got := JWT("header.payload.signature-1234"); got != "<redacted>...ure-1234" {
+		t.Errorf("JWT = %q", got)
+	}
+	if got := JWT("short"); got != "<redacted>" {
+		t.Errorf("JWT of a short token = %q", got)
+	}
+}